@@ -0,0 +1,182 @@
+// main 函数
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/config"
+	"github.com/danta7/go_mall/internal/logger"
+)
+
+const usage = `Usage: migrate <command> [flags]
+
+Commands:
+  status                      列出迁移文件与执行记录的状态
+  up [-dry-run] [-resume]     执行全部未执行的迁移
+  down [-steps N] [-dry-run] [-resume]
+                              回滚最近执行的 N 个迁移（默认 1）
+  down -to VERSION [-dry-run] [-resume]
+                              回滚版本号大于 VERSION 的全部迁移（VERSION=0 回滚全部）
+  create NAME                 在迁移目录下创建新的迁移文件（MIGRATIONS_DIR 为空时为 ./migrations）
+
+-dry-run 只输出将要执行的 SQL，不执行、不修改迁移记录与 migrations 表结构，也不获取迁移锁
+-resume  从中断处继续执行 partial/failed 状态的迁移（先用 status 查看失败原因并处理）
+`
+
+// defaultCreateDir 未配置 MIGRATIONS_DIR 时新建迁移文件的目录（即内嵌迁移文件的源码目录）
+const defaultCreateDir = "migrations"
+
+// main 为数据库迁移命令入口，复用服务的配置加载与数据库连接
+// 迁移与服务启动解耦：生产环境可关闭 MIGRATIONS_ON_STARTUP，在发布流程中单独执行本命令
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd := os.Args[1]
+	opts := parseFlags(cmd, os.Args[2:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	// create 只生成文件，不需要连接数据库
+	if cmd == "create" {
+		dir := cfg.Migrations.Dir
+		if dir == "" {
+			dir = defaultCreateDir
+		}
+		path, err := database.CreateMigration(dir, opts.name, time.Now())
+		if err != nil {
+			log.Fatalf("create migration: %v", err)
+		}
+		fmt.Println(path)
+		return
+	}
+
+	lg, err := logger.New(cfg.App.Env, cfg.Log.Level, cfg.Log.Encoding, cfg.App.Name, cfg.App.Version)
+	if err != nil {
+		log.Fatalf("init logger: %v", err)
+	}
+
+	db, err := database.New(cfg, lg)
+	if err != nil {
+		lg.Sugar().Fatalw("failed to initialize database", "err", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	fsys := database.MigrationsFS(cfg.Migrations.Dir)
+	migrateOpts := database.MigrateOptions{
+		WarnOnDrift: cfg.Migrations.OnDrift == "warn",
+		LockTimeout: cfg.Migrations.LockTimeout,
+		Resume:      opts.resume,
+	}
+	if opts.dryRun {
+		migrateOpts.DryRun = os.Stdout
+	}
+
+	switch cmd {
+	case "status":
+		err = printStatus(db, fsys)
+	case "up":
+		err = db.RunMigrations(fsys, migrateOpts)
+	case "down":
+		if opts.to != "" {
+			err = db.RollbackMigrationsTo(fsys, opts.to, migrateOpts)
+		} else {
+			err = db.RollbackMigrations(fsys, opts.steps, migrateOpts)
+		}
+	}
+	if err != nil {
+		_ = db.Close()
+		lg.Sugar().Fatalw("migrate "+cmd+" failed", "err", err, "dir", cfg.Migrations.Dir)
+	}
+}
+
+// cmdOptions 子命令解析后的参数
+type cmdOptions struct {
+	name   string
+	dryRun bool
+	resume bool
+	steps  int
+	to     string
+}
+
+// parseFlags 为每个子命令单独定义 FlagSet，只接受该命令适用的参数；
+// 未知命令、不适用的参数或多余的位置参数输出用法后以状态码 2 退出
+func parseFlags(cmd string, args []string) cmdOptions {
+	flags := flag.NewFlagSet(cmd, flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+
+	var opts cmdOptions
+	nargs := 0
+	switch cmd {
+	case "status":
+	case "create":
+		nargs = 1
+	case "up":
+		flags.BoolVar(&opts.dryRun, "dry-run", false, "print the SQL that would run without executing it")
+		flags.BoolVar(&opts.resume, "resume", false, "continue a partial or failed migration from where it stopped")
+	case "down":
+		flags.BoolVar(&opts.dryRun, "dry-run", false, "print the SQL that would run without executing it")
+		flags.BoolVar(&opts.resume, "resume", false, "continue a partial or failed migration from where it stopped")
+		flags.IntVar(&opts.steps, "steps", 1, "number of migrations to roll back")
+		flags.StringVar(&opts.to, "to", "", "roll back every migration newer than this version")
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() != nargs {
+		fmt.Fprintf(os.Stderr, "migrate %s: expected %d argument(s), got %q\n\n", cmd, nargs, flags.Args())
+		flags.Usage()
+		os.Exit(2)
+	}
+	if nargs == 1 {
+		opts.name = flags.Arg(0)
+	}
+	if cmd == "down" {
+		set := map[string]bool{}
+		flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if set["steps"] && set["to"] {
+			fmt.Fprintf(os.Stderr, "migrate down: -steps and -to cannot be used together\n\n")
+			flags.Usage()
+			os.Exit(2)
+		}
+	}
+	return opts
+}
+
+// printStatus 以表格形式输出迁移状态，未完成的迁移附带进度与失败原因
+func printStatus(db *database.DB, fsys fs.FS) error {
+	statuses, err := db.MigrationStatuses(fsys)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "MIGRATION\tSTATE\tEXECUTED AT\tPROGRESS\tERROR")
+	for _, s := range statuses {
+		executedAt, progress, errMsg := "-", "-", "-"
+		if !s.ExecutedAt.IsZero() {
+			executedAt = s.ExecutedAt.Format(time.DateTime)
+		}
+		if s.Direction != "" {
+			progress = fmt.Sprintf("%s %d/%d", s.Direction, s.Applied, s.Total)
+		}
+		if s.Error != "" {
+			errMsg = s.Error
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Filename, s.State, executedAt, progress, errMsg)
+	}
+	return w.Flush()
+}
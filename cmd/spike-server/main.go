@@ -3,22 +3,32 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"github.com/danta7/go_mall/database"
 	"github.com/danta7/go_mall/internal/api"
 	"github.com/danta7/go_mall/internal/config"
+	"github.com/danta7/go_mall/internal/idempotency"
 	"github.com/danta7/go_mall/internal/logger"
 	mw "github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/mq"
+	"github.com/danta7/go_mall/internal/ratelimit"
 	"github.com/danta7/go_mall/internal/repo"
 	"github.com/danta7/go_mall/internal/resp"
 	"github.com/danta7/go_mall/internal/service"
+	"github.com/danta7/go_mall/internal/shipping"
+	"github.com/redis/go-redis/v9"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"time"
 )
 
+// spikeStreamPattern 秒杀结果 SSE 推送路由，长连接不受请求超时限制
+const spikeStreamPattern = "GET /api/v1/spike/orders/{ticket}/stream"
+
 // main 为应用入口：
 // 1) 加载并校验配置；
 // 2) 初始化结构化日志；
@@ -51,13 +61,23 @@ func main() {
 	// 执行数据库迁移
 	// 最佳实践：在应用启动时、HTTP服务器启动前执行数据库迁移
 	// 这样可以确保在处理请求前，数据库结构已经完全准备好
-	// 从环境变量获取迁移目录路径，如果未设置则使用默认值
-	// 从配置中获取迁移目录路径
-	migrationDir := cfg.Migrations.Dir
-	lg.Sugar().Infow("using migrations directory", "path", migrationDir)
+	// 关闭 MIGRATIONS_ON_STARTUP 后改由 cmd/migrate 在发布流程中单独执行
+	if cfg.Migrations.OnStartup {
+		// 迁移文件默认编译进二进制，配置了 MIGRATIONS_DIR 时改为读取该目录（便于开发时调试）
+		migrationDir := cfg.Migrations.Dir
+		if migrationDir != "" {
+			lg.Sugar().Infow("using migrations directory", "path", migrationDir)
+		}
 
-	if err := db.RunMigrations(migrationDir); err != nil {
-		lg.Sugar().Fatalw("failed to run database migrations", "err", err, "dir", migrationDir)
+		// 已执行的迁移文件被修改时默认拒绝启动，避免各环境数据库结构悄然不一致
+		// 多副本同时启动时只有持有迁移锁的实例执行迁移，其余实例等待
+		migrateOpts := database.MigrateOptions{
+			WarnOnDrift: cfg.Migrations.OnDrift == "warn",
+			LockTimeout: cfg.Migrations.LockTimeout,
+		}
+		if err := db.RunMigrations(database.MigrationsFS(migrationDir), migrateOpts); err != nil {
+			lg.Sugar().Fatalw("failed to run database migrations", "err", err, "dir", migrationDir)
+		}
 	}
 
 	// 初始化以来注入链：仓储 -> 服务 -> API处理器
@@ -65,6 +85,177 @@ func main() {
 	userService := service.NewUserService(userRepo, lg)
 	userHandler := api.NewUserHandler(userService, lg)
 
+	// Redis：秒杀库存/去重/结果存储、限流或幂等记录使用 redis 后端时初始化
+	var rdb *redis.Client
+	if cfg.Spike.StockBackend == "redis" || cfg.RateLimit.Backend == "redis" || cfg.Idempotency.Backend == "redis" {
+		rdb, err = database.NewRedis(cfg, lg)
+		if err != nil {
+			lg.Sugar().Fatalw("failed to initialize redis", "err", err)
+		}
+		defer func() {
+			if err := rdb.Close(); err != nil {
+				lg.Sugar().Errorw("failed to close redis connection", "err", err)
+			}
+		}()
+	}
+
+	// 秒杀库存计数器、去重标记与下单结果存储：多实例部署时需使用 Redis 共享
+	var (
+		stockCounter repo.StockCounter
+		spikeDedupe  repo.SpikeDedupe
+		resultStore  repo.SpikeResultStore
+		waitingRoom  repo.WaitingRoom
+		oneTimeKeys  repo.OneTimeKeyStore
+	)
+	switch cfg.Spike.StockBackend {
+	case "redis":
+		stockCounter = repo.NewRedisStockCounter(rdb)
+		spikeDedupe = repo.NewRedisSpikeDedupe(rdb)
+		resultStore = repo.NewRedisSpikeResultStore(rdb, cfg.Spike.ResultTTL)
+		waitingRoom = repo.NewRedisWaitingRoom(rdb)
+		oneTimeKeys = repo.NewRedisOneTimeKeyStore(rdb)
+	default:
+		stockCounter = repo.NewMemoryStockCounter()
+		spikeDedupe = repo.NewMemorySpikeDedupe()
+		resultStore = repo.NewMemorySpikeResultStore(cfg.Spike.ResultTTL)
+		waitingRoom = repo.NewMemoryWaitingRoom()
+		oneTimeKeys = repo.NewMemoryOneTimeKeyStore()
+	}
+
+	// 限流器：全局与按 IP 使用令牌桶（允许突发），秒杀下单按用户使用滑动窗口
+	var globalLimiter, ipLimiter, spikeUserLimiter ratelimit.Limiter
+	switch cfg.RateLimit.Backend {
+	case "redis":
+		globalLimiter = ratelimit.NewRedisTokenBucket(rdb, "global", float64(cfg.RateLimit.GlobalRPS), cfg.RateLimit.GlobalBurst)
+		ipLimiter = ratelimit.NewRedisTokenBucket(rdb, "ip", float64(cfg.RateLimit.IPRPS), cfg.RateLimit.IPBurst)
+		spikeUserLimiter = ratelimit.NewRedisSlidingWindow(rdb, "spike_user", cfg.RateLimit.SpikeUserLimit, cfg.RateLimit.SpikeUserWindow)
+	default:
+		globalLimiter = ratelimit.NewMemoryTokenBucket(float64(cfg.RateLimit.GlobalRPS), cfg.RateLimit.GlobalBurst)
+		ipLimiter = ratelimit.NewMemoryTokenBucket(float64(cfg.RateLimit.IPRPS), cfg.RateLimit.IPBurst)
+		spikeUserLimiter = ratelimit.NewMemorySlidingWindow(cfg.RateLimit.SpikeUserLimit, cfg.RateLimit.SpikeUserWindow)
+	}
+	rateLimit := func(limiter ratelimit.Limiter, key mw.KeyFunc) func(http.Handler) http.Handler {
+		if !cfg.RateLimit.Enabled {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return mw.RateLimit(limiter, key, lg)
+	}
+
+	// 幂等键：按路由 + 用户（未登录时按 IP）隔离，重试时重放首次响应
+	var idempotencyStore idempotency.Store
+	switch cfg.Idempotency.Backend {
+	case "redis":
+		idempotencyStore = idempotency.NewRedisStore(rdb)
+	default:
+		idempotencyStore = idempotency.NewMemoryStore()
+	}
+	idempotent := func(next http.Handler) http.Handler { return next }
+	if cfg.Idempotency.Enabled {
+		idempotent = mw.Idempotency(idempotencyStore, mw.IdempotencyConfig{
+			TTL:     cfg.Idempotency.TTL,
+			LockTTL: cfg.Idempotency.LockTTL,
+			Scope:   mw.KeyJoin(mw.KeyByRoute, mw.KeyByUser(api.UserKey, cfg.RateLimit.TrustProxy)),
+		}, lg)
+	}
+
+	// 消息队列：秒杀下单异步化
+	var broker mq.Broker
+	switch cfg.MQ.Backend {
+	case "amqp":
+		broker, err = mq.NewAMQPBroker(cfg.AMQPURL(), 16, lg)
+		if err != nil {
+			lg.Sugar().Fatalw("failed to initialize amqp broker", "err", err)
+		}
+	default:
+		broker = mq.NewMemoryBroker(0, lg)
+	}
+	defer func() {
+		if err := broker.Close(); err != nil {
+			lg.Sugar().Errorw("failed to close message broker", "err", err)
+		}
+	}()
+
+	couponRepo := repo.NewCouponRepository(db)
+	couponService := service.NewCouponService(couponRepo, lg)
+	couponHandler := api.NewCouponHandler(couponService, lg)
+	shippingCalc := shipping.NewFlat(cfg.Shipping.FlatFee)
+	if cfg.Shipping.FreeThreshold > 0 {
+		shippingCalc = shipping.NewFreeOver(cfg.Shipping.FreeThreshold, shippingCalc)
+	}
+	orderService := service.NewOrderService(repo.NewOrderRepository(db), couponRepo, shippingCalc, lg)
+	orderHandler := api.NewOrderHandler(orderService, lg)
+	addressService := service.NewAddressService(repo.NewAddressRepository(db), lg)
+	addressHandler := api.NewAddressHandler(addressService, lg)
+
+	spikeEventRepo := repo.NewSpikeEventRepository(db)
+	spikeOrderRepo := repo.NewSpikeOrderRepository(db)
+	spikeEventService := service.NewSpikeEventService(spikeEventRepo, spikeOrderRepo, stockCounter, lg)
+	if err := spikeEventService.WarmUpStock(context.Background()); err != nil {
+		lg.Sugar().Fatalw("failed to warm up spike stock", "err", err)
+	}
+	spikeEventHandler := api.NewSpikeEventHandler(spikeEventService, lg)
+	spikeService := service.NewSpikeService(spikeEventRepo, stockCounter, spikeDedupe, resultStore, orderService, addressService, broker, lg)
+	spikeHandler := api.NewSpikeHandler(spikeService, lg)
+	waitingRoomService := service.NewWaitingRoomService(spikeEventRepo, waitingRoom, cfg.WaitingRoom.AdmitRate, cfg.WaitingRoom.Secret, lg)
+	waitingRoomHandler := api.NewWaitingRoomHandler(waitingRoomService, lg)
+	spikeGuardService := service.NewSpikeGuardService(spikeEventRepo, oneTimeKeys, service.SpikeGuardConfig{
+		Difficulty:   cfg.AntiBot.Difficulty,
+		ChallengeTTL: cfg.AntiBot.ChallengeTTL,
+		TokenTTL:     cfg.AntiBot.TokenTTL,
+		Secret:       cfg.AntiBot.Secret,
+	}, lg)
+	spikeGuardHandler := api.NewSpikeGuardHandler(spikeGuardService, lg)
+
+	deadLetterService := service.NewDeadLetterService(repo.NewDeadLetterRepository(db), broker, lg)
+	deadLetterHandler := api.NewDeadLetterHandler(deadLetterService, lg)
+
+	// 启动后台任务：秒杀下单消费者
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	var bg sync.WaitGroup
+	spikeOrderWorker := service.NewSpikeOrderWorker(broker, spikeOrderRepo, orderService, stockCounter, spikeDedupe, resultStore, deadLetterService,
+		mq.RetryPolicy{MaxAttempts: cfg.MQ.RetryMaxAttempts, BaseDelay: cfg.MQ.RetryBaseDelay, MaxDelay: cfg.MQ.RetryMaxDelay},
+		cfg.Spike.OrderWorkers, lg)
+	bg.Add(1)
+	go func() {
+		defer bg.Done()
+		if err := spikeOrderWorker.Run(bgCtx); err != nil {
+			lg.Sugar().Errorw("spike order worker stopped", "err", err)
+		}
+	}()
+
+	// 启动后台任务：库存对账
+	stockReconciler := service.NewSpikeStockReconciler(spikeEventRepo, spikeOrderRepo, stockCounter, cfg.Spike.ReconcileInterval,
+		cfg.Spike.ReconcileRestoreAfter, lg)
+	bg.Add(1)
+	go func() {
+		defer bg.Done()
+		if err := stockReconciler.Run(bgCtx); err != nil {
+			lg.Sugar().Errorw("spike stock reconciler stopped", "err", err)
+		}
+	}()
+
+	// 启动后台任务：本地消息表投递
+	outboxRelay := service.NewOutboxRelay(repo.NewOutboxRepository(db), broker,
+		cfg.Outbox.PollInterval, cfg.Outbox.BatchSize, cfg.Outbox.MaxAttempts, lg)
+	bg.Add(1)
+	go func() {
+		defer bg.Done()
+		if err := outboxRelay.Run(bgCtx); err != nil {
+			lg.Sugar().Errorw("outbox relay stopped", "err", err)
+		}
+	}()
+
+	// 启动后台任务：领域事件消费（order.created、spike.event.status_changed）
+	domainEventConsumer := service.NewDomainEventConsumer(broker, deadLetterService,
+		mq.RetryPolicy{MaxAttempts: cfg.MQ.RetryMaxAttempts, BaseDelay: cfg.MQ.RetryBaseDelay, MaxDelay: cfg.MQ.RetryMaxDelay}, lg)
+	bg.Add(1)
+	go func() {
+		defer bg.Done()
+		if err := domainEventConsumer.Run(bgCtx); err != nil {
+			lg.Sugar().Errorw("domain event consumer stopped", "err", err)
+		}
+	}()
+
 	mux := http.NewServeMux()
 	// 健康检查端点
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
@@ -75,15 +266,76 @@ func main() {
 		resp.OK(w, &data, "", "")
 	})
 
+	// 运行时指标（含 outbox 积压量、命令行参数等进程信息），仅管理员可访问
+	adminOnly := api.AdminOnly(userService, cfg.App.AdminAPIEnabled, lg)
+	mux.HandleFunc("GET /debug/vars", adminOnly(expvar.Handler().ServeHTTP))
+
 	// 用户认证相关 API 路由
-	mux.HandleFunc("/api/v1/auth/register", userHandler.Register)
+	mux.Handle("/api/v1/auth/register", idempotent(http.HandlerFunc(userHandler.Register)))
 	mux.HandleFunc("/api/v1/auth/login", userHandler.Login)
 	mux.HandleFunc("/api/v1/profile", userHandler.GetProfile)
 
-	// Build middleware chain : request ID -> recovery -> timeout -> CORS -> access_log
-	handler := mw.RequestID(mux)
+	// 秒杀活动 API 路由：公开列表 + 管理端（仅管理员）
+	mux.HandleFunc("GET /api/v1/spike/events", spikeEventHandler.ListPublic)
+	mux.HandleFunc("POST /api/v1/spike/events/{id}/queue", waitingRoomHandler.Join)
+	mux.HandleFunc("GET /api/v1/spike/events/{id}/queue", waitingRoomHandler.Status)
+	mux.HandleFunc("GET /api/v1/spike/events/{id}/challenge", spikeGuardHandler.Challenge)
+	mux.HandleFunc("POST /api/v1/spike/events/{id}/challenge", spikeGuardHandler.Solve)
+	mux.HandleFunc("GET /api/v1/spike/events/{id}/purchase-path", spikeGuardHandler.Path)
+	purchase := spikeHandler.Purchase
+	// 开启防刷后只保留隐藏下单地址，固定地址不再注册
+	purchasePattern := "POST /api/v1/spike/events/{id}/purchase"
+	if cfg.AntiBot.Enabled {
+		purchase = spikeGuardHandler.Gate(purchase)
+		purchasePattern = "POST /api/v1/spike/events/{id}/purchase/{path}"
+	}
+	// 排队校验包在防刷校验之外、先于其执行：未被放行的请求不会消耗一次性下单凭证
+	if cfg.WaitingRoom.Enabled {
+		purchase = waitingRoomHandler.Gate(purchase)
+	}
+	// 幂等包裹在排队与防刷校验之外：重试直接重放首次响应，不会因一次性凭证已被消耗而失败；
+	// 校验拒绝（403/429）不保存，被放行后使用同一个键重试会重新执行
+	mux.Handle(purchasePattern,
+		rateLimit(spikeUserLimiter, mw.KeyJoin(mw.KeyByRoute, mw.KeyByUser(api.UserKey, cfg.RateLimit.TrustProxy)))(idempotent(http.HandlerFunc(purchase))))
+	mux.HandleFunc("GET /api/v1/spike/orders/{ticket}", spikeHandler.GetResult)
+	mux.HandleFunc(spikeStreamPattern, spikeHandler.StreamResult)
+	mux.HandleFunc("GET /api/v1/admin/spike/events", adminOnly(spikeEventHandler.List))
+	mux.HandleFunc("POST /api/v1/admin/spike/events", adminOnly(spikeEventHandler.Create))
+	mux.HandleFunc("GET /api/v1/admin/spike/events/{id}", adminOnly(spikeEventHandler.Get))
+	mux.HandleFunc("PUT /api/v1/admin/spike/events/{id}", adminOnly(spikeEventHandler.Update))
+	mux.HandleFunc("POST /api/v1/admin/spike/events/{id}/schedule", adminOnly(spikeEventHandler.Schedule))
+	mux.HandleFunc("POST /api/v1/admin/spike/events/{id}/close", adminOnly(spikeEventHandler.Close))
+
+	// 死信管理 API 路由（仅管理员）
+	mux.HandleFunc("GET /api/v1/admin/dead-letters", adminOnly(deadLetterHandler.List))
+	mux.HandleFunc("GET /api/v1/admin/dead-letters/{id}", adminOnly(deadLetterHandler.Get))
+	mux.HandleFunc("POST /api/v1/admin/dead-letters/{id}/replay", adminOnly(deadLetterHandler.Replay))
+	mux.HandleFunc("POST /api/v1/admin/dead-letters/{id}/discard", adminOnly(deadLetterHandler.Discard))
+
+	// 收货地址 API 路由（本人地址簿）
+	mux.HandleFunc("GET /api/v1/addresses", addressHandler.List)
+	mux.HandleFunc("POST /api/v1/addresses", addressHandler.Create)
+	mux.HandleFunc("GET /api/v1/addresses/{id}", addressHandler.Get)
+	mux.HandleFunc("PUT /api/v1/addresses/{id}", addressHandler.Update)
+	mux.HandleFunc("DELETE /api/v1/addresses/{id}", addressHandler.Delete)
+	mux.HandleFunc("POST /api/v1/addresses/{id}/default", addressHandler.SetDefault)
+
+	// 订单与优惠券 API 路由：用户查询本人订单 + 管理端优惠券管理（仅管理员）
+	mux.HandleFunc("GET /api/v1/orders/{id}", orderHandler.Get)
+	mux.HandleFunc("GET /api/v1/admin/coupons", adminOnly(couponHandler.List))
+	mux.HandleFunc("POST /api/v1/admin/coupons", adminOnly(couponHandler.Create))
+	mux.HandleFunc("GET /api/v1/admin/coupons/{id}", adminOnly(couponHandler.Get))
+	mux.HandleFunc("POST /api/v1/admin/coupons/{id}/enable", adminOnly(couponHandler.Enable))
+	mux.HandleFunc("POST /api/v1/admin/coupons/{id}/disable", adminOnly(couponHandler.Disable))
+
+	// Build middleware chain : rate limit (global, per-IP) -> request ID -> recovery -> timeout -> CORS -> access_log
+	// 执行顺序与包装顺序相反：先按 IP 再全局限流，避免被单个 IP 拒绝的请求消耗全局配额
+	handler := rateLimit(globalLimiter, mw.KeyGlobal)(mux)
+	handler = rateLimit(ipLimiter, mw.KeyByIP(cfg.RateLimit.TrustProxy))(handler)
+	handler = mw.RequestID(handler)
 	handler = mw.Recovery(lg)(handler)
-	handler = mw.Timeout(cfg.App.RequestTimeout)(handler)
+	// 只有 SSE 推送路由豁免请求超时（处理器自带最长推送时长）
+	handler = mw.Timeout(cfg.App.RequestTimeout, mw.ExemptPatterns(mux, spikeStreamPattern))(handler)
 	handler = mw.CORS(mw.CORSConfig{
 		AllowedOrigins: cfg.CORS.AllowedOrigins,
 		AllowedMethods: cfg.CORS.AllowedMethods,
@@ -119,5 +371,9 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		lg.Sugar().Errorw("server shutdown error", "err", err)
 	}
+
+	// 先停止 HTTP 服务不再受理新请求，再停止后台任务
+	bgCancel()
+	bg.Wait()
 	lg.Sugar().Infow("server exited")
 }
@@ -3,41 +3,36 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"os"
-	"path/filepath"
-	"sort"
-	"strings"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/danta7/go_mall/internal/config"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"go.uber.org/zap"
 )
 
-// DB 封装数据库连接
+// DB 封装数据库连接：内嵌的 *sql.DB 为主库，承担全部写入与默认读取；
+// 配置了从库时，仓储可通过 Reader 将只读查询分流到从库
 type DB struct {
 	*sql.DB
 	logger *zap.Logger
+
+	replicas  []*replica
+	next      atomic.Uint64
+	stop      chan struct{}
+	closeOnce sync.Once
 }
 
 // New 创建数据库连接
 func New(cfg *config.Config, logger *zap.Logger) (*DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=true&loc=Local",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.DBName,
-	)
-
-	sqlDB, err := sql.Open("mysql", dsn)
+	sqlDB, err := open(cfg, net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port)))
 	if err != nil {
-		return nil, fmt.Errorf("open database: %w", err)
+		return nil, err
 	}
 
-	// 配置连接池
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetMaxIdleConns(10)
-
 	// 测试链接
 	if err := sqlDB.Ping(); err != nil {
 		_ = sqlDB.Close()
@@ -48,135 +43,95 @@ func New(cfg *config.Config, logger *zap.Logger) (*DB, error) {
 		zap.String("host", cfg.Database.Host),
 		zap.Int("port", cfg.Database.Port),
 		zap.String("database", cfg.Database.DBName),
+		zap.Int("max_open_conns", cfg.Database.MaxOpenConns),
+		zap.Int("max_idle_conns", cfg.Database.MaxIdleConns),
+		zap.Duration("conn_max_lifetime", cfg.Database.ConnMaxLifetime),
+		zap.Duration("conn_max_idle_time", cfg.Database.ConnMaxIdleTime),
+		zap.Duration("dial_timeout", cfg.Database.DialTimeout),
+		zap.Duration("read_timeout", cfg.Database.ReadTimeout),
+		zap.Duration("write_timeout", cfg.Database.WriteTimeout),
+		zap.String("tls", cfg.Database.TLS),
+		zap.String("timezone", cfg.Database.Timezone),
 	)
 
-	return &DB{DB: sqlDB, logger: logger}, nil
-}
-
-// RunMigrations 执行数据库迁移
-// 数据库迁移是一种管理数据库结构变更的版本控制机制，通过SQL文件定义数据库模式变更
-// 主要作用是：
-// 1. 确保所有环境（开发、测试、生产）使用相同的数据库结构
-// 2. 跟踪数据库结构的变更历史
-// 3. 支持向前（应用新变更）和向后（回滚）操作
-// 4. 多人协作开发时避免数据库结构不一致
-func (db *DB) RunMigrations(migrationsDir string) error {
-	// 创建 migrations 表来记录已执行的迁移
-	if err := db.createMigrationsTable(); err != nil {
-		return fmt.Errorf("create migrations table: %w", err)
-	}
-
-	// 获取已经执行的迁移
-	executed, err := db.getExecuteMigrations()
-	if err != nil {
-		return fmt.Errorf("get executed migraions: %w", err)
-	}
-
-	// 读取迁移文件
-	files, err := filepath.Glob(filepath.Join(migrationsDir, "*.sql"))
-	if err != nil {
-		return fmt.Errorf("read migration files: %w", err)
-	}
-
-	sort.Strings(files)
+	db := &DB{DB: sqlDB, logger: logger, stop: make(chan struct{})}
 
-	// 执行未执行的迁移
-	// 迁移文件命名采用时间戳前缀（如 20241001_001_create_users_table.sql）确保按顺序执行
-	// 这种命名约定很重要，因为它保证了迁移按预期的顺序应用
-	for _, file := range files {
-		filename := filepath.Base(file)
-		if executed[filename] {
-			db.logger.Debug("migration already executed", zap.String("file", filename))
-			continue
+	// 从库连接失败不影响启动：标记为不可用，读流量回退到主库，由健康检查在恢复后重新启用
+	for _, addr := range cfg.Database.Replicas {
+		replicaDB, err := open(cfg, addr)
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("replica %s: %w", addr, err)
 		}
-
-		if err := db.executeMigration(file); err != nil {
-			return fmt.Errorf("execute migration %s: %w", filename, err)
+		rep := &replica{addr: addr, db: replicaDB}
+		if err := replicaDB.Ping(); err != nil {
+			logger.Warn("database replica unavailable", zap.String("addr", addr), zap.Error(err))
+		} else {
+			rep.healthy.Store(true)
+			logger.Info("database replica connected", zap.String("addr", addr))
 		}
-		db.logger.Info("migration executed", zap.String("file", filename))
+		db.replicas = append(db.replicas, rep)
+	}
+	if len(db.replicas) > 0 {
+		go db.checkReplicas(cfg.Database.ReplicaCheckInterval)
 	}
 
-	return nil
+	return db, nil
 }
 
-func (db *DB) createMigrationsTable() error {
-	query := `
-		CREATE TABLE IF NOT EXISTS migrations (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			filename VARCHAR(255) NOT NULL UNIQUE,
-			executed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
-	`
-	_, err := db.Exec(query)
+// Close 停止从库健康检查并关闭主库与全部从库连接
+func (db *DB) Close() error {
+	var err error
+	db.closeOnce.Do(func() {
+		close(db.stop)
+		for _, rep := range db.replicas {
+			_ = rep.db.Close()
+		}
+		err = db.DB.Close()
+	})
 	return err
 }
 
-// 查询已经执行过的迁移表文件
-func (db *DB) getExecuteMigrations() (map[string]bool, error) {
-	executed := make(map[string]bool)
-	rows, err := db.Query("SELECT filename FROM migrations")
+// open 按配置创建连接池，主库与从库使用相同的账号、连接参数与连接池配置
+func open(cfg *config.Config, addr string) (*sql.DB, error) {
+	dsn, err := dsn(cfg, addr)
 	if err != nil {
-		return executed, err
+		return nil, err
 	}
-	defer func() { _ = rows.Close() }()
-
-	// 遍历查询结果
-	for rows.Next() {
-		var filename string
-		if err := rows.Scan(&filename); err != nil {
-			return executed, err
-		}
-		executed[filename] = true
-	}
-	return executed, rows.Err()
-}
 
-func (db *DB) executeMigration(filepath string) error {
-	content, err := os.ReadFile(filepath)
+	sqlDB, err := sql.Open("mysql", dsn)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	filename := filepath[strings.LastIndex(filepath, "/")+1:]
-
-	// 分割 SQL 语句并逐条执行
-	// 使用分号(;)作为语句分隔符，但需要处理注释和字符串中的分号
-	sqlStatements := strings.Split(string(content), ";")
+	// 配置连接池
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+	return sqlDB, nil
+}
 
-	// 开始事务以确保所有SQL语句要么全部成功，要么全部失败
-	tx, err := db.Begin()
+// dsn 根据配置生成连接串；通过 mysql.Config 生成，密码等字段中的特殊字符会被正确转义
+func dsn(cfg *config.Config, addr string) (string, error) {
+	loc, err := time.LoadLocation(cfg.Database.Timezone)
 	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
-			return
-		}
-		err = tx.Commit()
-	}()
-
-	// 	执行每条 sql 语句
-	for _, stmt := range sqlStatements {
-		// 去除语句前后的空白字符
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" || strings.HasPrefix(strings.TrimLeft(stmt, " \t"), "--") {
-			continue
-		}
-
-		if _, execErr := tx.Exec(stmt); execErr != nil {
-			err = fmt.Errorf("exec SQL: %w", execErr)
-			return err
-		}
-	}
-
-	// 记录迁移过程
-	// 通过在migrations表中记录已执行的迁移文件名，确保相同的迁移不会被重复执行
-	if _, err = tx.Exec("INSERT INTO migrations (filename) VALUES (?)", filename); err != nil {
-		err = fmt.Errorf("record migration: %w", err)
-		return err
+		return "", fmt.Errorf("load database timezone: %w", err)
 	}
 
-	return nil
+	c := mysql.NewConfig()
+	c.User = cfg.Database.User
+	c.Passwd = cfg.Database.Password
+	c.Net = "tcp"
+	c.Addr = addr
+	c.DBName = cfg.Database.DBName
+	c.Params = map[string]string{"charset": "utf8mb4"}
+	c.Collation = cfg.Database.Collation
+	c.ParseTime = true
+	c.Loc = loc
+	c.Timeout = cfg.Database.DialTimeout
+	c.ReadTimeout = cfg.Database.ReadTimeout
+	c.WriteTimeout = cfg.Database.WriteTimeout
+	c.TLSConfig = cfg.Database.TLS
+	return c.FormatDSN(), nil
 }
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/internal/config"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestDSN(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Database.User = "spike"
+	cfg.Database.Password = "p@ss:/word?"
+	cfg.Database.DBName = "spike"
+	cfg.Database.DialTimeout = 3 * time.Second
+	cfg.Database.ReadTimeout = 2 * time.Minute
+	cfg.Database.TLS = "skip-verify"
+	cfg.Database.Collation = "utf8mb4_general_ci"
+	cfg.Database.Timezone = "UTC"
+
+	d, err := dsn(cfg, "db.internal:3307")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := mysql.ParseDSN(d)
+	if err != nil {
+		t.Fatalf("generated DSN %q is invalid: %v", d, err)
+	}
+	if parsed.Passwd != cfg.Database.Password || parsed.Addr != "db.internal:3307" {
+		t.Fatalf("unexpected credentials or address: %+v", parsed)
+	}
+	if parsed.Timeout != 3*time.Second || parsed.ReadTimeout != 2*time.Minute || parsed.WriteTimeout != 0 {
+		t.Fatalf("unexpected timeouts: %+v", parsed)
+	}
+	if parsed.TLSConfig != "skip-verify" || parsed.Collation != "utf8mb4_general_ci" || parsed.Loc.String() != "UTC" || !parsed.ParseTime {
+		t.Fatalf("unexpected options: %+v", parsed)
+	}
+
+	cfg.Database.Timezone = "Mars/Olympus"
+	if _, err := dsn(cfg, "db.internal:3307"); err == nil {
+		t.Fatalf("expected error for invalid timezone")
+	}
+}
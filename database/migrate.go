@@ -0,0 +1,886 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danta7/go_mall/migrations"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrIrreversibleMigration 迁移文件没有 Down 段，无法回滚
+	ErrIrreversibleMigration = errors.New("migration is irreversible")
+	// ErrMigrationDrift 已执行的迁移与迁移文件不一致
+	ErrMigrationDrift = errors.New("migration drift detected")
+	// ErrMigrationLockTimeout 等待其他实例完成迁移超时
+	ErrMigrationLockTimeout = errors.New("timed out waiting for migration lock")
+	// ErrMigrationIncomplete 存在执行到一半（partial）或执行失败（failed）的迁移
+	ErrMigrationIncomplete = errors.New("migration incomplete")
+)
+
+// defaultMigrationLockTimeout 未配置时等待迁移锁的时长
+const defaultMigrationLockTimeout = time.Minute
+
+// MigrateOptions 迁移执行选项
+type MigrateOptions struct {
+	// WarnOnDrift 检测到迁移漂移时只记录警告并继续执行；默认拒绝执行
+	WarnOnDrift bool
+	// LockTimeout 等待迁移锁的最长时间，<= 0 时使用默认值 1 分钟
+	LockTimeout time.Duration
+	// DryRun 非空时只将将要执行的 SQL 写入 DryRun，不执行也不修改迁移记录（只读，不建表、不加锁）
+	DryRun io.Writer
+	// Resume 继续执行中断（partial）或失败（failed）的迁移：跳过已成功的语句，从中断处继续；
+	// 未开启时遇到未完成的迁移返回 ErrMigrationIncomplete
+	Resume bool
+}
+
+// MigrationDrift 描述已执行迁移记录与迁移文件之间的差异
+type MigrationDrift struct {
+	// Changed 已执行但文件内容（Up 段语句）被修改的迁移
+	Changed []string
+	// Unknown 已执行但找不到对应文件的迁移（文件被删除或改名）
+	Unknown []string
+	// Missing 未执行但版本早于最新已执行迁移的文件（漏执行或分支合并顺序问题）
+	Missing []string
+}
+
+// Empty 是否没有任何差异
+func (d *MigrationDrift) Empty() bool {
+	return len(d.Changed) == 0 && len(d.Unknown) == 0 && len(d.Missing) == 0
+}
+
+func (d *MigrationDrift) String() string {
+	var parts []string
+	if len(d.Changed) > 0 {
+		parts = append(parts, "changed: "+strings.Join(d.Changed, ", "))
+	}
+	if len(d.Unknown) > 0 {
+		parts = append(parts, "unknown: "+strings.Join(d.Unknown, ", "))
+	}
+	if len(d.Missing) > 0 {
+		parts = append(parts, "missing: "+strings.Join(d.Missing, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// 迁移文件的分段指令：指令须独占一行；第一个指令之前的内容属于 Up 段，
+// 因此没有任何指令的旧迁移文件整体视为 Up。
+// NoTransaction 可出现在文件任意位置，声明整个文件不使用事务、逐条执行
+const (
+	directiveUp            = "-- +migrate up"
+	directiveDown          = "-- +migrate down"
+	directiveNoTransaction = "-- +migrate notransaction"
+)
+
+// migrations 表中记录的执行状态
+const (
+	recordApplied = "applied" // 执行完成
+	recordPartial = "partial" // 执行中，或进程在执行过程中退出
+	recordFailed  = "failed"  // 某条语句执行失败，last_error 记录原因
+)
+
+// migrationRecord 表示 migrations 表中的一条执行记录
+type migrationRecord struct {
+	// checksum 引入校验和之前执行的记录为空
+	checksum   string
+	executedAt time.Time
+	status     string
+	// direction 未完成的记录是在执行 Up 段（up）还是 Down 段（down）时中断的
+	direction string
+	// applied 当前方向上已成功执行的语句数
+	applied   int
+	lastError string
+}
+
+// complete 迁移是否已完整执行
+func (r *migrationRecord) complete() bool {
+	return r.status == recordApplied
+}
+
+// incompleteError 描述未完成的迁移，提示排查后以 Resume 继续
+func incompleteError(filename string, r *migrationRecord) error {
+	err := fmt.Errorf("%w: %s is %s during %s after %d statement(s)", ErrMigrationIncomplete, filename, r.status, r.direction, r.applied)
+	if r.lastError != "" {
+		err = fmt.Errorf("%w: %s", err, r.lastError)
+	}
+	return err
+}
+
+// migration 表示一个迁移文件解析后的内容
+type migration struct {
+	filename string
+	up       []string
+	down     []string
+	// checksum Up 段语句的 SHA-256，修改注释或 Down 段不视为内容变更
+	checksum string
+	// reversible 文件是否包含 Down 段（Down 段可以为空，表示回滚时无需执行语句）
+	reversible bool
+	// noTransaction 文件声明了 NoTransaction 指令
+	noTransaction bool
+}
+
+// transactional 语句能否放在一个事务中执行
+// MySQL 的 DDL 等语句会隐式提交当前事务，包含这类语句时事务无法保证原子性
+func (m *migration) transactional(statements []string) bool {
+	if m.noTransaction {
+		return false
+	}
+	for _, stmt := range statements {
+		if causesImplicitCommit(stmt) {
+			return false
+		}
+	}
+	return true
+}
+
+// MigrationsFS 返回迁移文件来源：dir 为空时使用编译进二进制的迁移文件，否则读取本地目录（开发时覆盖）
+func MigrationsFS(dir string) fs.FS {
+	if dir == "" {
+		return migrations.FS
+	}
+	return os.DirFS(dir)
+}
+
+// RunMigrations 执行数据库迁移
+// 数据库迁移是一种管理数据库结构变更的版本控制机制，通过SQL文件定义数据库模式变更
+// 主要作用是：
+// 1. 确保所有环境（开发、测试、生产）使用相同的数据库结构
+// 2. 跟踪数据库结构的变更历史
+// 3. 支持向前（应用新变更）和向后（回滚）操作
+// 4. 多人协作开发时避免数据库结构不一致
+//
+// 执行前校验已执行迁移的校验和：文件被修改、已执行的文件缺失或存在漏执行的旧版本时，
+// 默认拒绝执行并返回 ErrMigrationDrift，opts.WarnOnDrift 时只记录警告
+//
+// 多个实例同时启动时通过 MySQL 命名锁串行执行：只有一个实例执行迁移，
+// 其他实例等待锁释放后发现已无待执行迁移，直接返回
+//
+// 包含 DDL 的迁移无法在事务中原子执行，改为逐条执行并记录进度；
+// 存在未完成的迁移时返回 ErrMigrationIncomplete，排查后以 opts.Resume 从中断处继续
+//
+// opts.DryRun 时只读：不创建或升级 migrations 表、不获取迁移锁
+func (db *DB) RunMigrations(fsys fs.FS, opts MigrateOptions) error {
+	if opts.DryRun != nil {
+		return db.runMigrations(fsys, opts)
+	}
+	return db.withMigrationLock(opts.LockTimeout, func() error {
+		return db.runMigrations(fsys, opts)
+	})
+}
+
+func (db *DB) runMigrations(fsys fs.FS, opts MigrateOptions) error {
+	// 获取已经执行的迁移（非 dry-run 时先创建 migrations 表来记录已执行的迁移）
+	executed, err := db.loadExecuted(opts.DryRun == nil)
+	if err != nil {
+		return err
+	}
+
+	// 读取迁移文件
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	// 校验已执行迁移是否与文件一致
+	if opts.DryRun == nil {
+		if err := db.backfillChecksums(executed, migrations); err != nil {
+			return fmt.Errorf("backfill migration checksums: %w", err)
+		}
+	}
+	if drift := detectDrift(executed, migrations); !drift.Empty() {
+		if !opts.WarnOnDrift {
+			return fmt.Errorf("%w: %s", ErrMigrationDrift, drift)
+		}
+		db.logger.Warn("migration drift detected",
+			zap.Strings("changed", drift.Changed),
+			zap.Strings("unknown", drift.Unknown),
+			zap.Strings("missing", drift.Missing),
+		)
+	}
+
+	// 未完成的迁移须先处理：回滚中断的只能继续回滚，执行中断的须显式 Resume
+	for filename, record := range executed {
+		if record.complete() {
+			continue
+		}
+		if record.direction == "down" || !opts.Resume {
+			return incompleteError(filename, record)
+		}
+	}
+
+	// 执行未执行的迁移
+	// 迁移文件命名采用时间戳前缀（如 20241001_001_create_users_table.sql）确保按顺序执行
+	// 这种命名约定很重要，因为它保证了迁移按预期的顺序应用
+	for _, m := range migrations {
+		record := executed[m.filename]
+		if record != nil && record.complete() {
+			db.logger.Debug("migration already executed", zap.String("file", m.filename))
+			continue
+		}
+
+		if opts.DryRun != nil {
+			statements := m.up
+			if record != nil {
+				statements = m.up[min(record.applied, len(m.up)):]
+			}
+			if err := writeDryRun(opts.DryRun, m.filename, "up", statements, record == nil && m.transactional(m.up)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := db.executeMigration(m, record); err != nil {
+			return fmt.Errorf("execute migration %s: %w", m.filename, err)
+		}
+		db.logger.Info("migration executed", zap.String("file", m.filename))
+	}
+
+	return nil
+}
+
+// MigrationState 迁移文件（或执行记录）的状态
+type MigrationState string
+
+const (
+	MigrationApplied MigrationState = "applied" // 已执行
+	MigrationPending MigrationState = "pending" // 待执行
+	MigrationChanged MigrationState = "changed" // 已执行但文件被修改
+	MigrationUnknown MigrationState = "unknown" // 已执行但找不到对应文件
+	MigrationMissing MigrationState = "missing" // 未执行且早于最新已执行迁移
+	MigrationPartial MigrationState = "partial" // 执行中，或执行过程中进程退出
+	MigrationFailed  MigrationState = "failed"  // 执行失败，排查后 Resume
+)
+
+// MigrationStatus 单个迁移的状态
+type MigrationStatus struct {
+	Filename   string
+	State      MigrationState
+	ExecutedAt time.Time
+	// 以下字段只对未完成（partial/failed）的迁移有意义
+	Direction string // 中断时的方向：up 或 down
+	Applied   int    // 该方向上已成功执行的语句数
+	Total     int    // 该方向上的语句总数（文件缺失时为 0）
+	Error     string // 失败原因
+}
+
+// MigrationStatuses 按文件名顺序列出迁移文件与执行记录的状态（不执行任何迁移）
+func (db *DB) MigrationStatuses(fsys fs.FS) ([]*MigrationStatus, error) {
+	executed, err := db.loadExecuted(false)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := detectDrift(executed, migrations)
+	states := make(map[string]MigrationState)
+	for _, filename := range drift.Changed {
+		states[filename] = MigrationChanged
+	}
+	for _, filename := range drift.Unknown {
+		states[filename] = MigrationUnknown
+	}
+	for _, filename := range drift.Missing {
+		states[filename] = MigrationMissing
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(migrations)+len(drift.Unknown))
+	for _, m := range migrations {
+		status := &MigrationStatus{Filename: m.filename, State: MigrationPending}
+		if record, ok := executed[m.filename]; ok {
+			status.State, status.ExecutedAt = MigrationApplied, record.executedAt
+		}
+		if state, ok := states[m.filename]; ok {
+			status.State = state
+		}
+		if record, ok := executed[m.filename]; ok && !record.complete() {
+			status.Total = len(m.up)
+			if record.direction == "down" {
+				status.Total = len(m.down)
+			}
+			fillIncomplete(status, record)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, filename := range drift.Unknown {
+		status := &MigrationStatus{Filename: filename, State: MigrationUnknown, ExecutedAt: executed[filename].executedAt}
+		if record := executed[filename]; !record.complete() {
+			fillIncomplete(status, record)
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Filename < statuses[j].Filename })
+
+	return statuses, nil
+}
+
+// fillIncomplete 将未完成记录的状态、进度与失败原因填入 status
+func fillIncomplete(status *MigrationStatus, record *migrationRecord) {
+	status.State = MigrationState(record.status)
+	status.Direction, status.Applied, status.Error = record.direction, record.applied, record.lastError
+}
+
+// RollbackMigrations 按版本从新到旧回滚最近执行的 steps 个迁移（不检查漂移，opts.WarnOnDrift 不生效）
+func (db *DB) RollbackMigrations(fsys fs.FS, steps int, opts MigrateOptions) error {
+	if steps < 1 {
+		return fmt.Errorf("rollback steps must be >= 1, got %d", steps)
+	}
+	return db.rollback(fsys, opts, func(applied []string) []string {
+		if len(applied) > steps {
+			applied = applied[:steps]
+		}
+		return applied
+	})
+}
+
+// RollbackMigrationsTo 回滚版本号大于 version 的全部已执行迁移，version 本身保留
+// version 可以是版本号（如 20251018_005）或完整文件名；传 0 表示回滚全部迁移
+func (db *DB) RollbackMigrationsTo(fsys fs.FS, version string, opts MigrateOptions) error {
+	target := migrationVersion(version)
+	return db.rollback(fsys, opts, func(applied []string) []string {
+		var picked []string
+		for _, filename := range applied {
+			if migrationVersion(filename) > target {
+				picked = append(picked, filename)
+			}
+		}
+		return picked
+	})
+}
+
+// rollback 回滚 pick 从已执行迁移（按版本从新到旧）中选出的迁移
+// 回滚前先确认每个迁移文件都存在且包含 Down 段，避免执行到一半才发现无法继续
+// opts.DryRun 时与 RunMigrations 一样只读，不获取迁移锁
+func (db *DB) rollback(fsys fs.FS, opts MigrateOptions, pick func(applied []string) []string) error {
+	if opts.DryRun != nil {
+		return db.rollbackLocked(fsys, opts, pick)
+	}
+	return db.withMigrationLock(opts.LockTimeout, func() error {
+		return db.rollbackLocked(fsys, opts, pick)
+	})
+}
+
+func (db *DB) rollbackLocked(fsys fs.FS, opts MigrateOptions, pick func(applied []string) []string) error {
+	executed, err := db.loadExecuted(opts.DryRun == nil)
+	if err != nil {
+		return err
+	}
+	applied := make([]string, 0, len(executed))
+	for filename := range executed {
+		applied = append(applied, filename)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(applied)))
+
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*migration, len(migrations))
+	for _, m := range migrations {
+		byName[m.filename] = m
+	}
+
+	var plan []*migration
+	for _, filename := range pick(applied) {
+		m, ok := byName[filename]
+		if !ok {
+			return fmt.Errorf("rollback %s: migration file not found", filename)
+		}
+		if !m.reversible {
+			return fmt.Errorf("rollback %s: %w", filename, ErrIrreversibleMigration)
+		}
+		// 只有回滚中断的迁移可以 Resume 继续回滚；执行中断的迁移须先以 up -resume 执行完
+		if record := executed[filename]; !record.complete() && (record.direction != "down" || !opts.Resume) {
+			return incompleteError(filename, record)
+		}
+		plan = append(plan, m)
+	}
+
+	for _, m := range plan {
+		record := executed[m.filename]
+		if opts.DryRun != nil {
+			statements := m.down
+			if !record.complete() {
+				statements = m.down[min(record.applied, len(m.down)):]
+			}
+			if err := writeDryRun(opts.DryRun, m.filename, "down", statements, record.complete() && m.transactional(m.down)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := db.revertMigration(m, record); err != nil {
+			return fmt.Errorf("rollback migration %s: %w", m.filename, err)
+		}
+		db.logger.Info("migration rolled back", zap.String("file", m.filename))
+	}
+
+	return nil
+}
+
+// withMigrationLock 持有迁移锁执行 fn
+// 使用 GET_LOCK 命名锁（按库名区分，同一 MySQL 实例上的不同库互不影响）：
+// 锁属于会话，因此占用一个独立连接直到 fn 返回；持锁进程崩溃时连接断开，锁自动释放
+func (db *DB) withMigrationLock(timeout time.Duration, fn func() error) error {
+	if timeout <= 0 {
+		timeout = defaultMigrationLockTimeout
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get migration lock connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// GET_LOCK 的超时以秒为单位，不足一秒按一秒计
+	seconds := int((timeout + time.Second - 1) / time.Second)
+	start := time.Now()
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(CONCAT(DATABASE(), '.migrations'), ?)", seconds).Scan(&acquired); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("%w after %s", ErrMigrationLockTimeout, timeout)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		db.logger.Info("migration lock acquired", zap.Duration("waited", waited))
+	}
+
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "DO RELEASE_LOCK(CONCAT(DATABASE(), '.migrations'))"); err != nil {
+			db.logger.Warn("failed to release migration lock", zap.Error(err))
+		}
+	}()
+
+	return fn()
+}
+
+// migrationsTableUpgrades 旧版本创建的 migrations 表缺少的列，按顺序补齐；
+// value 为缺少该列时读取执行记录所用的默认值，与 DDL 中的默认值一致
+var migrationsTableUpgrades = []struct {
+	column string
+	ddl    string
+	value  string
+}{
+	{"checksum", "ALTER TABLE migrations ADD COLUMN checksum CHAR(64) NULL AFTER filename", "NULL"},
+	{"status", "ALTER TABLE migrations ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'applied' AFTER checksum", "'applied'"},
+	{"direction", "ALTER TABLE migrations ADD COLUMN direction VARCHAR(8) NOT NULL DEFAULT 'up' AFTER status", "'up'"},
+	{"statements_applied", "ALTER TABLE migrations ADD COLUMN statements_applied INT NOT NULL DEFAULT 0 AFTER direction", "0"},
+	{"last_error", "ALTER TABLE migrations ADD COLUMN last_error TEXT NULL AFTER statements_applied", "NULL"},
+}
+
+// loadExecuted 读取执行记录；create 为 true 时先创建或升级 migrations 表，
+// 否则只读：表不存在视为没有已执行的迁移，旧表缺少的列按默认值读取
+func (db *DB) loadExecuted(create bool) (map[string]*migrationRecord, error) {
+	if create {
+		if err := db.createMigrationsTable(); err != nil {
+			return nil, fmt.Errorf("create migrations table: %w", err)
+		}
+	}
+
+	columns, err := db.migrationsTableColumns()
+	if err != nil {
+		return nil, fmt.Errorf("inspect migrations table: %w", err)
+	}
+	if len(columns) == 0 {
+		return make(map[string]*migrationRecord), nil
+	}
+
+	executed, err := db.getExecuteMigrations(columns)
+	if err != nil {
+		return nil, fmt.Errorf("get executed migraions: %w", err)
+	}
+	return executed, nil
+}
+
+// migrationsTableColumns 返回 migrations 表现有的列，表不存在时返回空集合
+func (db *DB) migrationsTableColumns() (map[string]bool, error) {
+	rows, err := db.Query(`
+		SELECT COLUMN_NAME FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'migrations'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns[strings.ToLower(column)] = true
+	}
+	return columns, rows.Err()
+}
+
+func (db *DB) createMigrationsTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS migrations (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			filename VARCHAR(255) NOT NULL UNIQUE,
+			checksum CHAR(64) NULL,
+			status VARCHAR(16) NOT NULL DEFAULT 'applied',
+			direction VARCHAR(8) NOT NULL DEFAULT 'up',
+			statements_applied INT NOT NULL DEFAULT 0,
+			last_error TEXT NULL,
+			executed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	columns, err := db.migrationsTableColumns()
+	if err != nil {
+		return err
+	}
+	for _, upgrade := range migrationsTableUpgrades {
+		if columns[upgrade.column] {
+			continue
+		}
+		if _, err := db.Exec(upgrade.ddl); err != nil {
+			return fmt.Errorf("add column %s: %w", upgrade.column, err)
+		}
+	}
+	return nil
+}
+
+// 查询已经执行过的迁移表文件及其校验和、执行状态；columns 为表现有的列，缺少的列按默认值读取
+func (db *DB) getExecuteMigrations(columns map[string]bool) (map[string]*migrationRecord, error) {
+	executed := make(map[string]*migrationRecord)
+	rows, err := db.Query("SELECT filename, " + migrationRecordColumns(columns) + ", executed_at FROM migrations")
+	if err != nil {
+		return executed, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	// 遍历查询结果
+	for rows.Next() {
+		var (
+			filename   string
+			checksum   sql.NullString
+			lastError  sql.NullString
+			executedAt sql.NullTime
+			record     migrationRecord
+		)
+		if err := rows.Scan(&filename, &checksum, &record.status, &record.direction, &record.applied, &lastError, &executedAt); err != nil {
+			return executed, err
+		}
+		record.checksum, record.lastError, record.executedAt = checksum.String, lastError.String, executedAt.Time
+		executed[filename] = &record
+	}
+	return executed, rows.Err()
+}
+
+// migrationRecordColumns 按 migrationsTableUpgrades 的顺序拼接执行记录的查询列
+func migrationRecordColumns(columns map[string]bool) string {
+	exprs := make([]string, 0, len(migrationsTableUpgrades))
+	for _, upgrade := range migrationsTableUpgrades {
+		if columns[upgrade.column] {
+			exprs = append(exprs, upgrade.column)
+		} else {
+			exprs = append(exprs, upgrade.value+" AS "+upgrade.column)
+		}
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// backfillChecksums 为引入校验和之前执行的迁移记录当前文件的校验和
+func (db *DB) backfillChecksums(executed map[string]*migrationRecord, migrations []*migration) error {
+	for _, m := range migrations {
+		if record, ok := executed[m.filename]; !ok || record.checksum != "" || !record.complete() {
+			continue
+		}
+		if _, err := db.Exec("UPDATE migrations SET checksum = ? WHERE filename = ? AND checksum IS NULL", m.checksum, m.filename); err != nil {
+			return err
+		}
+		executed[m.filename].checksum = m.checksum
+		db.logger.Info("migration checksum recorded", zap.String("file", m.filename))
+	}
+	return nil
+}
+
+// detectDrift 对比已执行迁移记录与迁移文件
+func detectDrift(executed map[string]*migrationRecord, migrations []*migration) *MigrationDrift {
+	drift := &MigrationDrift{}
+
+	var latest string
+	files := make(map[string]bool, len(migrations))
+	for _, m := range migrations {
+		files[m.filename] = true
+	}
+	for filename := range executed {
+		if !files[filename] {
+			drift.Unknown = append(drift.Unknown, filename)
+		}
+		if filename > latest {
+			latest = filename
+		}
+	}
+	sort.Strings(drift.Unknown)
+
+	for _, m := range migrations {
+		record, ok := executed[m.filename]
+		switch {
+		case !ok && m.filename < latest:
+			drift.Missing = append(drift.Missing, m.filename)
+		// 未完成的迁移允许修复文件后继续执行，完成时会更新校验和
+		case ok && record.complete() && record.checksum != "" && record.checksum != m.checksum:
+			drift.Changed = append(drift.Changed, m.filename)
+		}
+	}
+	return drift
+}
+
+// executeMigration 执行 Up 段，record 为上次未完成的执行记录（首次执行时为 nil）
+// 只包含 DML 的迁移与迁移记录在同一事务中提交，要么全部成功要么全部失败；
+// 包含 DDL（MySQL 执行 DDL 会隐式提交事务）或声明了 NoTransaction 时事务形同虚设，
+// 改为逐条执行并在每条语句成功后记录进度，失败时记录 failed 状态与原因，便于排查后从中断处继续
+func (db *DB) executeMigration(m *migration, record *migrationRecord) error {
+	if record == nil && m.transactional(m.up) {
+		// 记录迁移过程
+		// 通过在migrations表中记录已执行的迁移文件名，确保相同的迁移不会被重复执行
+		return db.execInTx(m.up, "INSERT INTO migrations (filename, checksum) VALUES (?, ?)", m.filename, m.checksum)
+	}
+
+	skip := 0
+	if record == nil {
+		_, err := db.Exec("INSERT INTO migrations (filename, checksum, status, direction) VALUES (?, ?, ?, 'up')",
+			m.filename, m.checksum, recordPartial)
+		if err != nil {
+			return fmt.Errorf("record migration: %w", err)
+		}
+	} else {
+		skip = record.applied
+		db.logger.Info("resuming migration", zap.String("file", m.filename), zap.Int("skip", skip))
+	}
+
+	if err := db.execTracked(m.filename, m.up, skip); err != nil {
+		return err
+	}
+
+	_, err := db.Exec("UPDATE migrations SET status = ?, checksum = ?, last_error = NULL, executed_at = CURRENT_TIMESTAMP WHERE filename = ?",
+		recordApplied, m.checksum, m.filename)
+	if err != nil {
+		return fmt.Errorf("record migration: %w", err)
+	}
+	return nil
+}
+
+// revertMigration 执行 Down 段并删除迁移记录，之后重新执行 RunMigrations 会再次应用该迁移
+// 与 executeMigration 相同，包含 DDL 时逐条执行并记录回滚进度
+func (db *DB) revertMigration(m *migration, record *migrationRecord) error {
+	if record.complete() && m.transactional(m.down) {
+		return db.execInTx(m.down, "DELETE FROM migrations WHERE filename = ?", m.filename)
+	}
+
+	skip := 0
+	if record.complete() {
+		_, err := db.Exec("UPDATE migrations SET status = ?, direction = 'down', statements_applied = 0 WHERE filename = ?",
+			recordPartial, m.filename)
+		if err != nil {
+			return fmt.Errorf("record migration: %w", err)
+		}
+	} else {
+		skip = record.applied
+		db.logger.Info("resuming migration rollback", zap.String("file", m.filename), zap.Int("skip", skip))
+	}
+
+	if err := db.execTracked(m.filename, m.down, skip); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec("DELETE FROM migrations WHERE filename = ?", m.filename); err != nil {
+		return fmt.Errorf("record migration: %w", err)
+	}
+	return nil
+}
+
+// execTracked 跳过前 skip 条已执行的语句，逐条执行其余语句，每条成功后更新进度
+// 所有语句在同一连接上执行，保留 SET 等会话状态；失败时将记录标记为 failed 并保存原因
+func (db *DB) execTracked(filename string, statements []string, skip int) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get migration connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.ExecContext(ctx, "UPDATE migrations SET status = ?, last_error = NULL WHERE filename = ?", recordPartial, filename); err != nil {
+		return fmt.Errorf("record migration: %w", err)
+	}
+
+	for i := skip; i < len(statements); i++ {
+		if _, execErr := conn.ExecContext(ctx, statements[i]); execErr != nil {
+			err := fmt.Errorf("exec SQL (statement %d of %d): %w", i+1, len(statements), execErr)
+			if _, recordErr := conn.ExecContext(ctx, "UPDATE migrations SET status = ?, last_error = ? WHERE filename = ?",
+				recordFailed, err.Error(), filename); recordErr != nil {
+				db.logger.Error("failed to record migration failure", zap.String("file", filename), zap.Error(recordErr))
+			}
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, "UPDATE migrations SET statements_applied = ? WHERE filename = ?", i+1, filename); err != nil {
+			return fmt.Errorf("record migration progress: %w", err)
+		}
+	}
+	return nil
+}
+
+// execInTx 在同一事务中执行迁移语句并更新迁移记录
+func (db *DB) execInTx(statements []string, record string, args ...any) (err error) {
+	// 开始事务以确保所有SQL语句要么全部成功，要么全部失败
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// 	执行每条 sql 语句
+	for _, stmt := range statements {
+		if _, execErr := tx.Exec(stmt); execErr != nil {
+			err = fmt.Errorf("exec SQL: %w", execErr)
+			return err
+		}
+	}
+
+	if _, err = tx.Exec(record, args...); err != nil {
+		err = fmt.Errorf("record migration: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// writeDryRun 输出迁移将要执行的 SQL，语句以分号结尾，可直接交给 mysql 客户端执行
+// 包含分号的语句（如触发器）用 DELIMITER 包裹；不在事务中执行的迁移在标题中注明
+func writeDryRun(w io.Writer, filename, direction string, statements []string, transactional bool) error {
+	var b strings.Builder
+	if transactional {
+		fmt.Fprintf(&b, "-- %s (%s)\n", filename, direction)
+	} else {
+		fmt.Fprintf(&b, "-- %s (%s, no transaction)\n", filename, direction)
+	}
+	for _, stmt := range statements {
+		if strings.Contains(stmt, defaultDelimiter) {
+			fmt.Fprintf(&b, "DELIMITER $$\n%s$$\nDELIMITER ;\n", stmt)
+			continue
+		}
+		b.WriteString(stmt + defaultDelimiter + "\n")
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// loadMigrations 读取并解析 fsys 根目录下的全部迁移文件，按文件名排序
+func loadMigrations(fsys fs.FS) ([]*migration, error) {
+	// fs.Glob 的结果已按文件名排序
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("read migration files: %w", err)
+	}
+
+	migrations := make([]*migration, 0, len(files))
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", file, err)
+		}
+		m, err := parseMigration(file, string(content))
+		if err != nil {
+			return nil, fmt.Errorf("parse migration %s: %w", file, err)
+		}
+		migrations = append(migrations, m)
+	}
+	return migrations, nil
+}
+
+// parseMigration 按分段指令拆出 Up/Down 段并切分语句
+func parseMigration(filename, content string) (*migration, error) {
+	m := &migration{filename: filename}
+
+	var up, down strings.Builder
+	section := &up
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case directiveUp:
+			section = &up
+			continue
+		case directiveDown:
+			section = &down
+			m.reversible = true
+			continue
+		case directiveNoTransaction:
+			m.noTransaction = true
+			continue
+		}
+		section.WriteString(line)
+	}
+
+	var err error
+	// 按词法切分，字符串、注释中的分号以及 DELIMITER 定义的语句块不会被错误切开
+	if m.up, err = splitStatements(up.String()); err != nil {
+		return nil, fmt.Errorf("split up SQL: %w", err)
+	}
+	if m.down, err = splitStatements(down.String()); err != nil {
+		return nil, fmt.Errorf("split down SQL: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(m.up, ";\n")))
+	m.checksum = hex.EncodeToString(sum[:])
+	return m, nil
+}
+
+// migrationVersion 取文件名的版本号部分（日期 + 序号），如 20251018_005_create_outbox_table.sql -> 20251018_005
+func migrationVersion(filename string) string {
+	parts := strings.SplitN(strings.TrimSuffix(filename, ".sql"), "_", 3)
+	if len(parts) < 2 {
+		return parts[0]
+	}
+	return parts[0] + "_" + parts[1]
+}
+
+// implicitCommitKeywords 会隐式提交事务或自行控制事务的语句首关键字
+var implicitCommitKeywords = map[string]bool{
+	"ALTER": true, "CREATE": true, "DROP": true, "RENAME": true, "TRUNCATE": true,
+	"GRANT": true, "REVOKE": true, "LOCK": true, "UNLOCK": true,
+	"ANALYZE": true, "OPTIMIZE": true, "REPAIR": true, "CHECK": true,
+	"CACHE": true, "LOAD": true, "FLUSH": true, "RESET": true, "INSTALL": true, "UNINSTALL": true,
+	"BEGIN": true, "START": true, "COMMIT": true, "ROLLBACK": true,
+}
+
+// causesImplicitCommit 判断语句是否会隐式提交事务（DDL、账号管理、表维护等）或自行控制事务
+// CREATE/DROP TEMPORARY TABLE 例外，不会提交事务
+func causesImplicitCommit(stmt string) bool {
+	fields := strings.Fields(strings.ToUpper(stmt))
+	if len(fields) == 0 || !implicitCommitKeywords[strings.TrimLeft(fields[0], "(")] {
+		return false
+	}
+	if (fields[0] == "CREATE" || fields[0] == "DROP") && len(fields) > 1 && fields[1] == "TEMPORARY" {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationTemplate 新建迁移文件的模板
+const migrationTemplate = `-- %s
+
+-- +migrate Up
+
+
+-- +migrate Down
+
+`
+
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CreateMigration 在 dir 下创建新的迁移文件并返回文件路径
+// 文件名沿用 20250924_001_create_users_table.sql 的约定：日期 + 当天三位序号 + 描述，
+// 序号取 dir 中同一天已有迁移的最大序号加一
+func CreateMigration(dir, name string, now time.Time) (string, error) {
+	slug := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return "", fmt.Errorf("migration name %q must contain letters or digits", name)
+	}
+
+	date := now.Format("20060102")
+	files, err := filepath.Glob(filepath.Join(dir, date+"_*.sql"))
+	if err != nil {
+		return "", fmt.Errorf("read migration files: %w", err)
+	}
+	seq := 0
+	for _, file := range files {
+		version := migrationVersion(filepath.Base(file))
+		if n, err := strconv.Atoi(strings.TrimPrefix(version, date+"_")); err == nil && n > seq {
+			seq = n
+		}
+	}
+	if seq >= 999 {
+		return "", fmt.Errorf("too many migrations on %s", date)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s_%03d_%s.sql", date, seq+1, slug))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("create migration file: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, migrationTemplate, name); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("write migration file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write migration file: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/migrations"
+)
+
+func TestParseMigration(t *testing.T) {
+	content := "-- 建表\nCREATE TABLE a (id int);\n\n-- +migrate Down\nDROP TABLE a;\n"
+	m, err := parseMigration("20251018_001_a.sql", content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !m.reversible {
+		t.Fatalf("expected migration to be reversible")
+	}
+	if !reflect.DeepEqual(m.up, []string{"CREATE TABLE a (id int)"}) || !reflect.DeepEqual(m.down, []string{"DROP TABLE a"}) {
+		t.Fatalf("unexpected sections: up=%q down=%q", m.up, m.down)
+	}
+
+	m, err = parseMigration("20251018_002_b.sql", "CREATE TABLE b (id int);")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.reversible || len(m.down) != 0 {
+		t.Fatalf("expected migration without down section to be irreversible")
+	}
+
+	m, err = parseMigration("20251018_003_c.sql", "-- +migrate Down\nDROP TABLE c;\n-- +migrate Up\nCREATE TABLE c (id int);\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(m.up, []string{"CREATE TABLE c (id int)"}) || !reflect.DeepEqual(m.down, []string{"DROP TABLE c"}) {
+		t.Fatalf("expected sections in any order: up=%q down=%q", m.up, m.down)
+	}
+}
+
+func TestMigrationVersion(t *testing.T) {
+	cases := map[string]string{
+		"20251018_005_create_outbox_table.sql": "20251018_005",
+		"20251018_005":                         "20251018_005",
+		"0":                                    "0",
+	}
+	for in, want := range cases {
+		if got := migrationVersion(in); got != want {
+			t.Fatalf("%s: expected %s, got %s", in, want, got)
+		}
+	}
+}
+
+func TestLoadMigrations_Reversible(t *testing.T) {
+	loaded, err := loadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded) == 0 {
+		t.Fatalf("expected embedded migrations")
+	}
+	for _, m := range loaded {
+		if !m.reversible {
+			t.Fatalf("%s: %v", m.filename, ErrIrreversibleMigration)
+		}
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	migrations := []*migration{
+		{filename: "20251018_001_a.sql", checksum: "aaa"},
+		{filename: "20251018_002_b.sql", checksum: "bbb"},
+		{filename: "20251018_003_c.sql", checksum: "ccc"},
+		{filename: "20251018_005_e.sql", checksum: "eee"},
+	}
+	executed := map[string]*migrationRecord{
+		"20251018_001_a.sql": {checksum: "aaa", status: recordApplied},
+		"20251018_002_b.sql": {checksum: "changed", status: recordApplied},
+		"20251018_004_d.sql": {checksum: "ddd", status: recordApplied},
+	}
+
+	drift := detectDrift(executed, migrations)
+	if !reflect.DeepEqual(drift.Changed, []string{"20251018_002_b.sql"}) {
+		t.Fatalf("unexpected changed: %v", drift.Changed)
+	}
+	if !reflect.DeepEqual(drift.Unknown, []string{"20251018_004_d.sql"}) {
+		t.Fatalf("unexpected unknown: %v", drift.Unknown)
+	}
+	if !reflect.DeepEqual(drift.Missing, []string{"20251018_003_c.sql"}) {
+		t.Fatalf("unexpected missing: %v", drift.Missing)
+	}
+
+	// 尚未记录校验和的旧记录不视为变更
+	executed["20251018_002_b.sql"].checksum = ""
+	delete(executed, "20251018_004_d.sql")
+	executed["20251018_003_c.sql"] = &migrationRecord{checksum: "ccc", status: recordApplied}
+	if drift := detectDrift(executed, migrations); !drift.Empty() {
+		t.Fatalf("expected no drift, got %s", drift)
+	}
+
+	// 失败的迁移允许修复文件后继续执行，不视为变更
+	executed["20251018_005_e.sql"] = &migrationRecord{checksum: "old", status: recordFailed, applied: 1}
+	if drift := detectDrift(executed, migrations); !drift.Empty() {
+		t.Fatalf("expected no drift for failed migration, got %s", drift)
+	}
+}
+
+func TestParseMigration_NoTransaction(t *testing.T) {
+	m, err := parseMigration("20251018_001_a.sql", "-- +migrate NoTransaction\n-- +migrate Up\nINSERT INTO a VALUES (1);\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !m.noTransaction || m.transactional(m.up) {
+		t.Fatalf("expected NoTransaction directive to disable transaction")
+	}
+	if !reflect.DeepEqual(m.up, []string{"INSERT INTO a VALUES (1)"}) {
+		t.Fatalf("directive leaked into statements: %q", m.up)
+	}
+
+	m, err = parseMigration("20251018_002_b.sql", "INSERT INTO b VALUES (1);\nUPDATE b SET id = 2;\n-- +migrate Down\nDROP TABLE b;\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !m.transactional(m.up) {
+		t.Fatalf("expected DML-only up section to be transactional")
+	}
+	if m.transactional(m.down) {
+		t.Fatalf("expected DDL down section not to be transactional")
+	}
+}
+
+func TestCausesImplicitCommit(t *testing.T) {
+	cases := map[string]bool{
+		"CREATE TABLE a (id int)":               true,
+		"alter table a add column b int":        true,
+		"DROP INDEX idx ON a":                   true,
+		"RENAME TABLE a TO b":                   true,
+		"TRUNCATE TABLE a":                      true,
+		"CREATE TEMPORARY TABLE tmp (id int)":   false,
+		"DROP TEMPORARY TABLE tmp":              false,
+		"INSERT INTO a VALUES (1)":              false,
+		"UPDATE a SET b = 1":                    false,
+		"DELETE FROM a":                         false,
+		"INSERT INTO a SELECT 'CREATE TABLE x'": false,
+		"/*!40101 SET NAMES utf8mb4 */":         false,
+		"START TRANSACTION":                     true,
+		"COMMIT":                                true,
+	}
+	for stmt, want := range cases {
+		if got := causesImplicitCommit(stmt); got != want {
+			t.Fatalf("%q: expected %v, got %v", stmt, want, got)
+		}
+	}
+}
+
+func TestMigrationRecordColumns(t *testing.T) {
+	all := map[string]bool{"checksum": true, "status": true, "direction": true, "statements_applied": true, "last_error": true}
+	if got, want := migrationRecordColumns(all), "checksum, status, direction, statements_applied, last_error"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	// 旧版本表只有 filename/checksum/executed_at 时，dry-run 不升级表结构，缺少的列按默认值读取
+	legacy := map[string]bool{"id": true, "filename": true, "checksum": true, "executed_at": true}
+	want := "checksum, 'applied' AS status, 'up' AS direction, 0 AS statements_applied, NULL AS last_error"
+	if got := migrationRecordColumns(legacy); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 10, 18, 12, 0, 0, 0, time.Local)
+
+	path, err := CreateMigration(dir, "Add Coupon Index", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := filepath.Base(path); got != "20251018_001_add_coupon_index.sql" {
+		t.Fatalf("unexpected filename %s", got)
+	}
+
+	path, err = CreateMigration(dir, "second", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := filepath.Base(path); got != "20251018_002_second.sql" {
+		t.Fatalf("expected sequence to increase, got %s", got)
+	}
+
+	m, err := loadMigrations(os.DirFS(dir))
+	if err != nil || len(m) != 2 || !m[0].reversible {
+		t.Fatalf("expected scaffolded files to parse as reversible migrations, err=%v", err)
+	}
+
+	if _, err := CreateMigration(dir, "--", now); err == nil {
+		t.Fatalf("expected error for empty name")
+	}
+}
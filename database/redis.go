@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danta7/go_mall/internal/config"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// NewRedis 创建 Redis 客户端并测试连接
+func NewRedis(cfg *config.Config, logger *zap.Logger) (*redis.Client, error) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		_ = rdb.Close()
+		return nil, fmt.Errorf("ping redis: %w", err)
+	}
+
+	logger.Info("redis connected",
+		zap.String("host", cfg.Redis.Host),
+		zap.Int("port", cfg.Redis.Port),
+		zap.Int("db", cfg.Redis.DB),
+	)
+
+	return rdb, nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+)
+
+// replicaPingTimeout 单次健康检查的超时
+const replicaPingTimeout = 2 * time.Second
+
+// replica 只读从库连接及其健康状态
+type replica struct {
+	addr    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// primaryKey 强制读主库的上下文键
+type primaryKey struct{}
+
+// WithPrimary 返回强制读主库的上下文
+// 用于写后立即读（read-your-writes）与读-改-写等不能容忍复制延迟的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func primaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+// Reader 返回只读查询使用的执行器：按轮询选择健康的从库，
+// 未配置从库、从库均不可用或上下文通过 WithPrimary 强制读主库时使用主库；
+// 从库连接失败时将其标记为不可用并改由主库重试，写操作始终发往主库
+func (db *DB) Reader() Executor {
+	return reader{db: db}
+}
+
+type reader struct {
+	db *DB
+}
+
+func (r reader) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.db.ExecContext(ctx, query, args...)
+}
+
+func (r reader) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rep := r.db.pickReplica(ctx)
+	if rep == nil {
+		return r.db.QueryContext(ctx, query, args...)
+	}
+	rows, err := rep.db.QueryContext(ctx, query, args...)
+	if r.db.replicaFailed(rep, err) {
+		return r.db.QueryContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+func (r reader) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	rep := r.db.pickReplica(ctx)
+	if rep == nil {
+		return r.db.QueryRowContext(ctx, query, args...)
+	}
+	row := rep.db.QueryRowContext(ctx, query, args...)
+	if r.db.replicaFailed(rep, row.Err()) {
+		return r.db.QueryRowContext(ctx, query, args...)
+	}
+	return row
+}
+
+// pickReplica 轮询选择健康的从库，没有可用从库时返回 nil
+func (db *DB) pickReplica(ctx context.Context) *replica {
+	if len(db.replicas) == 0 || primaryForced(ctx) {
+		return nil
+	}
+	start := db.next.Add(1)
+	for i := range uint64(len(db.replicas)) {
+		rep := db.replicas[(start+i)%uint64(len(db.replicas))]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// replicaFailed 判断从库查询是否因连接问题失败，是则标记从库不可用
+// MySQL 返回的错误（语法、约束等）在主库上同样会失败，上下文取消也与从库无关，二者均不回退
+func (db *DB) replicaFailed(rep *replica, err error) bool {
+	if err == nil || errors.Is(err, sql.ErrNoRows) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return false
+	}
+	if rep.healthy.CompareAndSwap(true, false) {
+		db.logger.Warn("database replica unavailable, falling back to primary", zap.String("addr", rep.addr), zap.Error(err))
+	}
+	return true
+}
+
+// checkReplicas 定期探测从库，不可用的从库恢复后重新参与读流量
+func (db *DB) checkReplicas(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.stop:
+			return
+		case <-ticker.C:
+			for _, rep := range db.replicas {
+				db.pingReplica(rep)
+			}
+		}
+	}
+}
+
+func (db *DB) pingReplica(rep *replica) {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+	defer cancel()
+
+	err := rep.db.PingContext(ctx)
+	switch {
+	case err != nil && rep.healthy.CompareAndSwap(true, false):
+		db.logger.Warn("database replica health check failed", zap.String("addr", rep.addr), zap.Error(err))
+	case err == nil && rep.healthy.CompareAndSwap(false, true):
+		db.logger.Info("database replica recovered", zap.String("addr", rep.addr))
+	}
+}
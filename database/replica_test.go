@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+)
+
+func TestPickReplica(t *testing.T) {
+	a, b := &replica{addr: "a:3306"}, &replica{addr: "b:3306"}
+	a.healthy.Store(true)
+	b.healthy.Store(true)
+	db := &DB{logger: zap.NewNop(), replicas: []*replica{a, b}}
+	ctx := context.Background()
+
+	seen := map[string]int{}
+	for range 4 {
+		seen[db.pickReplica(ctx).addr]++
+	}
+	if seen["a:3306"] != 2 || seen["b:3306"] != 2 {
+		t.Fatalf("expected round robin across replicas, got %v", seen)
+	}
+
+	if rep := db.pickReplica(WithPrimary(ctx)); rep != nil {
+		t.Fatalf("expected primary when forced, got %s", rep.addr)
+	}
+
+	a.healthy.Store(false)
+	for range 3 {
+		if rep := db.pickReplica(ctx); rep != b {
+			t.Fatalf("expected only healthy replica b, got %v", rep)
+		}
+	}
+
+	b.healthy.Store(false)
+	if rep := db.pickReplica(ctx); rep != nil {
+		t.Fatalf("expected fallback to primary when no replica is healthy, got %s", rep.addr)
+	}
+}
+
+func TestReplicaFailed(t *testing.T) {
+	db := &DB{logger: zap.NewNop()}
+	cases := []struct {
+		name     string
+		err      error
+		fallback bool
+	}{
+		{"no error", nil, false},
+		{"no rows", sql.ErrNoRows, false},
+		{"context canceled", context.Canceled, false},
+		{"server error", &mysql.MySQLError{Number: 1146, Message: "table doesn't exist"}, false},
+		{"bad connection", driver.ErrBadConn, true},
+	}
+
+	for _, tc := range cases {
+		rep := &replica{addr: "a:3306"}
+		rep.healthy.Store(true)
+		if got := db.replicaFailed(rep, tc.err); got != tc.fallback {
+			t.Fatalf("%s: expected fallback=%v, got %v", tc.name, tc.fallback, got)
+		}
+		if rep.healthy.Load() == tc.fallback {
+			t.Fatalf("%s: expected healthy=%v", tc.name, !tc.fallback)
+		}
+	}
+}
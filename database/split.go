@@ -0,0 +1,155 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultDelimiter 默认语句分隔符
+const defaultDelimiter = ";"
+
+// splitStatements 将迁移文件内容切分为可逐条执行的 SQL 语句
+// 按词法扫描而不是直接按分号切分：
+//  1. 单引号、双引号字符串（支持反斜杠转义与双写引号）和反引号标识符中的分隔符不切分
+//  2. 行注释（-- 与 #）和块注释被移除；/*! */ 与 /*+ */ 会被服务端解析，原样保留
+//  3. 支持 mysql 客户端的 DELIMITER 指令，用于定义触发器、存储过程等包含分号的语句；
+//     指令须独占一行且位于语句开头，不会发送给服务端
+//
+// 只包含空白或注释的片段会被丢弃；字符串或块注释未闭合时返回错误
+func splitStatements(content string) ([]string, error) {
+	var (
+		stmts     []string
+		buf       strings.Builder
+		delimiter = defaultDelimiter
+		line      = 1
+	)
+
+	flush := func() {
+		if stmt := strings.TrimSpace(buf.String()); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		buf.Reset()
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+
+		// DELIMITER 指令只在语句开头识别，避免误伤语句中名为 delimiter 的标识符
+		if hasPrefixFold(content[i:], "DELIMITER") && atLineStart(content, i) && strings.TrimSpace(buf.String()) == "" {
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			directive := strings.TrimSpace(content[i : i+end])
+			fields := strings.Fields(directive)
+			if len(fields) >= 1 && strings.EqualFold(fields[0], "DELIMITER") {
+				if len(fields) != 2 {
+					return nil, fmt.Errorf("line %d: DELIMITER requires exactly one argument", line)
+				}
+				delimiter = fields[1]
+				buf.Reset()
+				i += end
+				continue
+			}
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end, err := scanQuoted(content, i)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			buf.WriteString(content[i:end])
+			line += strings.Count(content[i:end], "\n")
+			i = end
+			continue
+
+		case c == '#' || isLineComment(content, i):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				i = len(content)
+				continue
+			}
+			i += end
+			continue
+
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated block comment", line)
+			}
+			end += i + 4
+			comment := content[i:end]
+			line += strings.Count(comment, "\n")
+			if strings.HasPrefix(comment, "/*!") || strings.HasPrefix(comment, "/*+") {
+				buf.WriteString(comment)
+			} else {
+				buf.WriteByte(' ')
+			}
+			i = end
+			continue
+
+		case strings.HasPrefix(content[i:], delimiter):
+			flush()
+			i += len(delimiter)
+			continue
+		}
+
+		if c == '\n' {
+			line++
+		}
+		buf.WriteByte(c)
+		i++
+	}
+	flush()
+
+	return stmts, nil
+}
+
+// scanQuoted 扫描从 start 开始的字符串或反引号标识符，返回闭合引号之后的位置
+func scanQuoted(content string, start int) (int, error) {
+	quote := content[start]
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			// 反引号标识符中反斜杠没有转义含义
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			// 双写引号表示引号本身
+			if i+1 < len(content) && content[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated %c quoted string", quote)
+}
+
+// isLineComment 判断 i 处是否为 "-- " 行注释；MySQL 要求 -- 之后紧跟空白或控制字符
+func isLineComment(content string, i int) bool {
+	if !strings.HasPrefix(content[i:], "--") {
+		return false
+	}
+	return i+2 == len(content) || content[i+2] <= ' '
+}
+
+// atLineStart 判断 i 之前同一行是否只有空白
+func atLineStart(content string, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		switch content[j] {
+		case '\n':
+			return true
+		case ' ', '\t', '\r':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
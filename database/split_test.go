@@ -0,0 +1,130 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	cases := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "plain statements",
+			sql:  "CREATE TABLE a (id int);\nCREATE TABLE b (id int);",
+			want: []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"},
+		},
+		{
+			name: "missing trailing delimiter",
+			sql:  "SELECT 1;\nSELECT 2\n",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name: "semicolons in string literals",
+			sql:  `INSERT INTO t VALUES ('a;b', "c;d", 'it''s;', 'esc\';');`,
+			want: []string{`INSERT INTO t VALUES ('a;b', "c;d", 'it''s;', 'esc\';')`},
+		},
+		{
+			name: "semicolons in backtick identifiers",
+			sql:  "CREATE TABLE `we;ird``name` (id int);",
+			want: []string{"CREATE TABLE `we;ird``name` (id int)"},
+		},
+		{
+			name: "line comments",
+			sql:  "-- 用户表；含分号; 的注释\n# hash comment;\nSELECT 1; -- trailing; comment\n--\nSELECT 2;",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name: "double dash without space is not a comment",
+			sql:  "SELECT 1--1;",
+			want: []string{"SELECT 1--1"},
+		},
+		{
+			name: "block comments",
+			sql:  "/* header; comment\n spanning lines */ SELECT /* inline; */ 1;",
+			want: []string{"SELECT   1"},
+		},
+		{
+			name: "executable comments are kept",
+			sql:  "/*!40101 SET NAMES utf8mb4 */;\nSELECT /*+ MAX_EXECUTION_TIME(1000) */ 1;",
+			want: []string{"/*!40101 SET NAMES utf8mb4 */", "SELECT /*+ MAX_EXECUTION_TIME(1000) */ 1"},
+		},
+		{
+			name: "comment only file",
+			sql:  "-- nothing here;\n/* still; nothing */\n",
+			want: nil,
+		},
+		{
+			name: "delimiter directive for triggers",
+			sql: "DELIMITER $$\n" +
+				"CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW\nBEGIN\n  SET NEW.a = 1;\n  SET NEW.b = ';';\nEND$$\n" +
+				"delimiter ;\n" +
+				"SELECT 1;",
+			want: []string{
+				"CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW\nBEGIN\n  SET NEW.a = 1;\n  SET NEW.b = ';';\nEND",
+				"SELECT 1",
+			},
+		},
+		{
+			name: "delimiter keyword inside a statement",
+			sql:  "SELECT 1 AS\ndelimiter;",
+			want: []string{"SELECT 1 AS\ndelimiter"},
+		},
+	}
+
+	for _, tc := range cases {
+		got, err := splitStatements(tc.sql)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestSplitStatements_Errors(t *testing.T) {
+	cases := map[string]string{
+		"unterminated string":        "SELECT 'abc;",
+		"unterminated identifier":    "SELECT `abc;",
+		"unterminated block comment": "SELECT 1; /* never closed",
+		"delimiter without argument": "DELIMITER\nSELECT 1;",
+	}
+
+	for name, sql := range cases {
+		if _, err := splitStatements(sql); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSplitStatements_Migrations(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("expected migration files, err=%v", err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		stmts, err := splitStatements(string(content))
+		if err != nil {
+			t.Fatalf("split %s: %v", file, err)
+		}
+		if len(stmts) == 0 {
+			t.Fatalf("%s: expected at least one statement", file)
+		}
+		for _, stmt := range stmts {
+			if strings.HasPrefix(stmt, "--") {
+				t.Fatalf("%s: comment leaked into statement %q", file, stmt)
+			}
+		}
+	}
+}
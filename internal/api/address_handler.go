@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+// AddressHandler 收货地址相关的HTTP处理器
+type AddressHandler struct {
+	addressService service.AddressService
+	logger         *zap.Logger
+}
+
+// NewAddressHandler 创建收货地址处理器实例
+func NewAddressHandler(addressService service.AddressService, logger *zap.Logger) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+		logger:         logger,
+	}
+}
+
+// Create 新增收货地址
+// POST /api/v1/addresses
+func (h *AddressHandler) Create(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, err := currentUserID(r)
+	if err != nil {
+		resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	var req domain.AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+		return
+	}
+
+	address, err := h.addressService.Create(r.Context(), userID, &req)
+	if err != nil {
+		h.writeError(w, r, "create address failed", err)
+		return
+	}
+
+	resp.OK(w, address, reqID, "")
+}
+
+// List 查询本人的收货地址，默认地址在前
+// GET /api/v1/addresses
+func (h *AddressHandler) List(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, err := currentUserID(r)
+	if err != nil {
+		resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	addresses, err := h.addressService.List(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, "list addresses failed", err)
+		return
+	}
+	if addresses == nil {
+		addresses = []*domain.Address{}
+	}
+
+	data := map[string]any{"items": addresses}
+	resp.OK(w, &data, reqID, "")
+}
+
+// Get 查询收货地址详情
+// GET /api/v1/addresses/{id}
+func (h *AddressHandler) Get(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, id, ok := h.params(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.GetByID(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, "get address failed", err)
+		return
+	}
+
+	resp.OK(w, address, reqID, "")
+}
+
+// Update 修改收货地址（整体覆盖）
+// PUT /api/v1/addresses/{id}
+func (h *AddressHandler) Update(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, id, ok := h.params(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	var req domain.AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+		return
+	}
+
+	address, err := h.addressService.Update(r.Context(), userID, id, &req)
+	if err != nil {
+		h.writeError(w, r, "update address failed", err)
+		return
+	}
+
+	resp.OK(w, address, reqID, "")
+}
+
+// Delete 删除收货地址
+// DELETE /api/v1/addresses/{id}
+func (h *AddressHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, id, ok := h.params(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	if err := h.addressService.Delete(r.Context(), userID, id); err != nil {
+		h.writeError(w, r, "delete address failed", err)
+		return
+	}
+
+	resp.OK[any](w, nil, reqID, "")
+}
+
+// SetDefault 设为默认收货地址
+// POST /api/v1/addresses/{id}/default
+func (h *AddressHandler) SetDefault(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, id, ok := h.params(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.SetDefault(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, "set default address failed", err)
+		return
+	}
+
+	resp.OK(w, address, reqID, "")
+}
+
+// params 解析当前用户与路径中的地址ID，失败时已写入响应
+func (h *AddressHandler) params(w http.ResponseWriter, r *http.Request, reqID string) (userID, id int64, ok bool) {
+	userID, err := currentUserID(r)
+	if err != nil {
+		resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return 0, 0, false
+	}
+
+	id, err = pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return 0, 0, false
+	}
+	return userID, id, true
+}
+
+// writeError 将服务层错误映射为统一响应；请求已超时或被取消时返回统一超时响应
+func (h *AddressHandler) writeError(w http.ResponseWriter, r *http.Request, failMsg string, err error) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+	switch {
+	case errors.Is(err, service.ErrAddressNotFound):
+		resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "address not found", reqID, "")
+	case errors.Is(err, service.ErrInvalidAddress), errors.Is(err, service.ErrAddressLimitExceeded):
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	default:
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error(failMsg, zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, failMsg, reqID, "")
+	}
+}
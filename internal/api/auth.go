@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+var errMissingUserID = errors.New("user_id is required")
+
+// currentUserID 获取当前请求的用户ID
+// TODO: 从 JWT 中获取用户 ID；现在先从查询参数 user_id 获取作为临时方案
+func currentUserID(r *http.Request) (int64, error) {
+	userIDStr := r.URL.Query().Get("user_id")
+	if userIDStr == "" {
+		return 0, errMissingUserID
+	}
+
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, errors.New("invalid user_id")
+	}
+	return userID, nil
+}
+
+// UserKey 返回当前请求的用户标识，供按用户限流使用；未识别用户时返回空字符串
+func UserKey(r *http.Request) string {
+	userID, err := currentUserID(r)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatInt(userID, 10)
+}
+
+// AdminOnly 返回一个包装器，仅允许管理员角色访问被包装的处理器；enabled 为 false 时管理端接口一律返回 403
+//
+// 注意：操作人身份取自客户端传入的 user_id（见 currentUserID），只要知道管理员的用户 ID 即可冒充，
+// 因此只能在可信网络内开启（ADMIN_API_ENABLED）。
+// TODO: 接入 JWT 后从令牌中获取操作人，再去掉 enabled 开关
+func AdminOnly(userService service.UserService, enabled bool, logger *zap.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			reqID := middleware.RequestIDFromContext(r.Context())
+			if !enabled {
+				resp.Error(w, http.StatusForbidden, resp.CodeInvalidParam, "admin api disabled", reqID, "")
+				return
+			}
+
+			userID, err := currentUserID(r)
+			if err != nil {
+				resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+				return
+			}
+
+			user, err := userService.GetUserByID(r.Context(), userID)
+			if err != nil {
+				if errors.Is(err, service.ErrUserNotFound) {
+					resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, "user not found", reqID, "")
+					return
+				}
+				if middleware.HandleTimeout(w, r) {
+					return
+				}
+				logger.Error("load operator failed", zap.String("request_id", reqID), zap.Error(err))
+				resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "load operator failed", reqID, "")
+				return
+			}
+
+			if !user.IsActive || !user.IsAdmin() {
+				resp.Error(w, http.StatusForbidden, resp.CodeInvalidParam, "admin only", reqID, "")
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// pathID 解析路径参数中的正整数ID
+func pathID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid " + name)
+	}
+	return id, nil
+}
+
+// userAndEvent 解析当前用户与路径中的活动ID，失败时已写入响应
+func userAndEvent(w http.ResponseWriter, r *http.Request, reqID string) (userID, eventID int64, ok bool) {
+	userID, err := currentUserID(r)
+	if err != nil {
+		resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return 0, 0, false
+	}
+
+	eventID, err = pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return 0, 0, false
+	}
+	return userID, eventID, true
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageParams 解析分页参数 page、page_size，缺省或非法时回退到默认值
+func pageParams(r *http.Request) (page, pageSize int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ = strconv.Atoi(r.URL.Query().Get("page_size"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+// CouponHandler 优惠券管理相关的HTTP处理器（管理端）
+type CouponHandler struct {
+	couponService service.CouponService
+	logger        *zap.Logger
+}
+
+// NewCouponHandler 创建优惠券处理器实例
+func NewCouponHandler(couponService service.CouponService, logger *zap.Logger) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+		logger:        logger,
+	}
+}
+
+// Create 创建优惠券
+// POST /api/v1/admin/coupons
+func (h *CouponHandler) Create(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	var req domain.CreateCouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+		return
+	}
+
+	coupon, err := h.couponService.Create(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, r, "create coupon failed", err)
+		return
+	}
+
+	resp.OK(w, coupon, reqID, "")
+}
+
+// List 分页查询优惠券，支持按 status 过滤
+// GET /api/v1/admin/coupons?status=&page=&page_size=
+func (h *CouponHandler) List(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	filter := domain.CouponFilter{Status: domain.CouponStatus(r.URL.Query().Get("status"))}
+	switch filter.Status {
+	case "", domain.CouponStatusActive, domain.CouponStatusDisabled:
+		// ok
+	default:
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid status", reqID, "")
+		return
+	}
+	filter.Page, filter.PageSize = pageParams(r)
+
+	coupons, total, err := h.couponService.List(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, r, "list coupons failed", err)
+		return
+	}
+	if coupons == nil {
+		coupons = []*domain.Coupon{}
+	}
+
+	data := map[string]any{
+		"items":     coupons,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+		"total":     total,
+	}
+	resp.OK(w, &data, reqID, "")
+}
+
+// Get 查询优惠券详情
+// GET /api/v1/admin/coupons/{id}
+func (h *CouponHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, "get coupon failed", h.couponService.GetByID)
+}
+
+// Enable 启用优惠券
+// POST /api/v1/admin/coupons/{id}/enable
+func (h *CouponHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, "enable coupon failed", h.couponService.Enable)
+}
+
+// Disable 停用优惠券
+// POST /api/v1/admin/coupons/{id}/disable
+func (h *CouponHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, "disable coupon failed", h.couponService.Disable)
+}
+
+// handle 处理按ID操作单张优惠券的请求
+func (h *CouponHandler) handle(w http.ResponseWriter, r *http.Request, failMsg string,
+	fn func(ctx context.Context, id int64) (*domain.Coupon, error)) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	coupon, err := fn(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, failMsg, err)
+		return
+	}
+
+	resp.OK(w, coupon, reqID, "")
+}
+
+// writeError 将服务层错误映射为统一响应；请求已超时或被取消时返回统一超时响应
+func (h *CouponHandler) writeError(w http.ResponseWriter, r *http.Request, failMsg string, err error) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+	switch {
+	case errors.Is(err, service.ErrCouponNotFound):
+		resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "coupon not found", reqID, "")
+	case errors.Is(err, service.ErrInvalidCoupon):
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	case errors.Is(err, service.ErrCouponCodeExists):
+		resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, "coupon code already exists", reqID, "")
+	default:
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error(failMsg, zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, failMsg, reqID, "")
+	}
+}
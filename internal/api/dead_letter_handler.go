@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+// DeadLetterHandler 死信管理相关的HTTP处理器（管理端）
+type DeadLetterHandler struct {
+	deadLetterService service.DeadLetterService
+	logger            *zap.Logger
+}
+
+// NewDeadLetterHandler 创建死信管理处理器实例
+func NewDeadLetterHandler(deadLetterService service.DeadLetterService, logger *zap.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		deadLetterService: deadLetterService,
+		logger:            logger,
+	}
+}
+
+// deadLetterResponse 在死信字段基础上以文本形式附带消息体，便于排查
+type deadLetterResponse struct {
+	*domain.DeadLetter
+	Payload string `json:"payload"`
+}
+
+func newDeadLetterResponse(dl *domain.DeadLetter) deadLetterResponse {
+	return deadLetterResponse{DeadLetter: dl, Payload: string(dl.Payload)}
+}
+
+// List 分页查询死信，支持按 status、topic 过滤
+// GET /api/v1/admin/dead-letters?status=&topic=&page=&page_size=
+func (h *DeadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	filter := domain.DeadLetterFilter{
+		Status: domain.DeadLetterStatus(r.URL.Query().Get("status")),
+		Topic:  r.URL.Query().Get("topic"),
+	}
+	switch filter.Status {
+	case "", domain.DeadLetterStatusDead, domain.DeadLetterStatusReplayed, domain.DeadLetterStatusDiscarded:
+		// ok
+	default:
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid status", reqID, "")
+		return
+	}
+	filter.Page, filter.PageSize = pageParams(r)
+
+	items, total, err := h.deadLetterService.List(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, r, "list dead letters failed", err)
+		return
+	}
+
+	list := make([]deadLetterResponse, 0, len(items))
+	for _, dl := range items {
+		list = append(list, newDeadLetterResponse(dl))
+	}
+
+	data := map[string]any{
+		"items":     list,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+		"total":     total,
+	}
+	resp.OK(w, &data, reqID, "")
+}
+
+// Get 查询单条死信
+// GET /api/v1/admin/dead-letters/{id}
+func (h *DeadLetterHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, "get dead letter failed", h.deadLetterService.GetByID)
+}
+
+// Replay 将死信重新投递到原主题
+// POST /api/v1/admin/dead-letters/{id}/replay
+func (h *DeadLetterHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, "replay dead letter failed", h.deadLetterService.Replay)
+}
+
+// Discard 丢弃死信
+// POST /api/v1/admin/dead-letters/{id}/discard
+func (h *DeadLetterHandler) Discard(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, "discard dead letter failed", h.deadLetterService.Discard)
+}
+
+// handle 处理按ID操作单条死信的请求
+func (h *DeadLetterHandler) handle(w http.ResponseWriter, r *http.Request, failMsg string,
+	fn func(ctx context.Context, id int64) (*domain.DeadLetter, error)) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	dl, err := fn(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, failMsg, err)
+		return
+	}
+
+	data := newDeadLetterResponse(dl)
+	resp.OK(w, &data, reqID, "")
+}
+
+// writeError 将服务层错误映射为统一响应；请求已超时或被取消时返回统一超时响应
+func (h *DeadLetterHandler) writeError(w http.ResponseWriter, r *http.Request, failMsg string, err error) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+	switch {
+	case errors.Is(err, service.ErrDeadLetterNotFound):
+		resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "dead letter not found", reqID, "")
+	case errors.Is(err, service.ErrDeadLetterInvalidState):
+		resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, err.Error(), reqID, "")
+	default:
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error(failMsg, zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, failMsg, reqID, "")
+	}
+}
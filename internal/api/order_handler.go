@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+// OrderHandler 订单相关的HTTP处理器
+type OrderHandler struct {
+	orderService service.OrderService
+	logger       *zap.Logger
+}
+
+// NewOrderHandler 创建订单处理器实例
+func NewOrderHandler(orderService service.OrderService, logger *zap.Logger) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+		logger:       logger,
+	}
+}
+
+// Get 查询本人订单详情，包含订单行与逐张优惠券的优惠明细
+// GET /api/v1/orders/{id}
+func (h *OrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, err := currentUserID(r)
+	if err != nil {
+		resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	orderID, err := pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	order, err := h.orderService.GetByID(r.Context(), userID, orderID)
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) {
+			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "order not found", reqID, "")
+			return
+		}
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error("get order failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get order failed", reqID, "")
+		return
+	}
+
+	resp.OK(w, order, reqID, "")
+}
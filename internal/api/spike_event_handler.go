@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+// SpikeEventHandler 秒杀活动相关的HTTP处理器
+type SpikeEventHandler struct {
+	eventService service.SpikeEventService
+	logger       *zap.Logger
+}
+
+// NewSpikeEventHandler 创建秒杀活动处理器实例
+func NewSpikeEventHandler(eventService service.SpikeEventService, logger *zap.Logger) *SpikeEventHandler {
+	return &SpikeEventHandler{
+		eventService: eventService,
+		logger:       logger,
+	}
+}
+
+// spikeEventResponse 在活动字段基础上附带当前阶段
+type spikeEventResponse struct {
+	*domain.SpikeEvent
+	Phase domain.SpikeEventPhase `json:"phase"`
+}
+
+func newSpikeEventResponse(e *domain.SpikeEvent, now time.Time) spikeEventResponse {
+	return spikeEventResponse{SpikeEvent: e, Phase: e.Phase(now)}
+}
+
+// Create 创建秒杀活动（草稿）
+// POST /api/v1/admin/spike/events
+func (h *SpikeEventHandler) Create(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	var req domain.CreateSpikeEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+		return
+	}
+
+	event, err := h.eventService.Create(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, r, "create spike event failed", err)
+		return
+	}
+
+	data := newSpikeEventResponse(event, time.Now())
+	resp.OK(w, &data, reqID, "")
+}
+
+// Update 编辑秒杀活动
+// PUT /api/v1/admin/spike/events/{id}
+func (h *SpikeEventHandler) Update(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	var req domain.UpdateSpikeEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+		return
+	}
+
+	event, err := h.eventService.Update(r.Context(), id, &req)
+	if err != nil {
+		h.writeError(w, r, "update spike event failed", err)
+		return
+	}
+
+	data := newSpikeEventResponse(event, time.Now())
+	resp.OK(w, &data, reqID, "")
+}
+
+// Schedule 发布（排期）秒杀活动
+// POST /api/v1/admin/spike/events/{id}/schedule
+func (h *SpikeEventHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "schedule spike event failed", h.eventService.Schedule)
+}
+
+// Close 关闭秒杀活动
+// POST /api/v1/admin/spike/events/{id}/close
+func (h *SpikeEventHandler) Close(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "close spike event failed", h.eventService.Close)
+}
+
+// Get 查询单个秒杀活动（管理端）
+// GET /api/v1/admin/spike/events/{id}
+func (h *SpikeEventHandler) Get(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	event, err := h.eventService.GetByID(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, "get spike event failed", err)
+		return
+	}
+
+	data := newSpikeEventResponse(event, time.Now())
+	resp.OK(w, &data, reqID, "")
+}
+
+// List 分页查询秒杀活动（管理端），支持按 status 过滤
+// GET /api/v1/admin/spike/events?status=&page=&page_size=
+func (h *SpikeEventHandler) List(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	filter := domain.SpikeEventFilter{Status: domain.SpikeEventStatus(r.URL.Query().Get("status"))}
+	switch filter.Status {
+	case "", domain.SpikeEventStatusDraft, domain.SpikeEventStatusScheduled, domain.SpikeEventStatusClosed:
+		// ok
+	default:
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid status", reqID, "")
+		return
+	}
+	filter.Page, filter.PageSize = pageParams(r)
+
+	events, total, err := h.eventService.List(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, r, "list spike events failed", err)
+		return
+	}
+
+	now := time.Now()
+	items := make([]spikeEventResponse, 0, len(events))
+	for _, e := range events {
+		items = append(items, newSpikeEventResponse(e, now))
+	}
+
+	data := map[string]any{
+		"items":     items,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+		"total":     total,
+	}
+	resp.OK(w, &data, reqID, "")
+}
+
+// ListPublic 对外展示即将开始与进行中的秒杀活动
+// GET /api/v1/spike/events
+func (h *SpikeEventHandler) ListPublic(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	events, err := h.eventService.ListPublic(r.Context())
+	if err != nil {
+		h.writeError(w, r, "list spike events failed", err)
+		return
+	}
+
+	now := time.Now()
+	upcoming := make([]spikeEventResponse, 0)
+	live := make([]spikeEventResponse, 0)
+	for _, e := range events {
+		switch e.Phase(now) {
+		case domain.SpikeEventPhaseUpcoming:
+			upcoming = append(upcoming, newSpikeEventResponse(e, now))
+		case domain.SpikeEventPhaseLive:
+			live = append(live, newSpikeEventResponse(e, now))
+		}
+	}
+
+	data := map[string]any{
+		"live":     live,
+		"upcoming": upcoming,
+	}
+	resp.OK(w, &data, reqID, "")
+}
+
+// transition 处理活动状态变更类请求（排期/关闭）
+func (h *SpikeEventHandler) transition(w http.ResponseWriter, r *http.Request, failMsg string,
+	fn func(ctx context.Context, id int64) (*domain.SpikeEvent, error)) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	event, err := fn(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, failMsg, err)
+		return
+	}
+
+	data := newSpikeEventResponse(event, time.Now())
+	resp.OK(w, &data, reqID, "")
+}
+
+// writeError 将服务层错误映射为统一响应；请求已超时或被取消时返回统一超时响应
+func (h *SpikeEventHandler) writeError(w http.ResponseWriter, r *http.Request, failMsg string, err error) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+	switch {
+	case errors.Is(err, service.ErrSpikeEventNotFound):
+		resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "spike event not found", reqID, "")
+	case errors.Is(err, service.ErrInvalidSpikeEvent):
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	case errors.Is(err, service.ErrSpikeEventInvalidState):
+		resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, err.Error(), reqID, "")
+	default:
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error(failMsg, zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, failMsg, reqID, "")
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+// HeaderSpikeToken 携带一次性秒杀下单凭证的请求头
+const HeaderSpikeToken = "X-Spike-Token"
+
+// SpikeGuardHandler 秒杀防刷相关的HTTP处理器
+type SpikeGuardHandler struct {
+	guardService service.SpikeGuardService
+	logger       *zap.Logger
+}
+
+// NewSpikeGuardHandler 创建秒杀防刷处理器实例
+func NewSpikeGuardHandler(guardService service.SpikeGuardService, logger *zap.Logger) *SpikeGuardHandler {
+	return &SpikeGuardHandler{
+		guardService: guardService,
+		logger:       logger,
+	}
+}
+
+// Challenge 获取工作量证明挑战
+// GET /api/v1/spike/events/{id}/challenge
+func (h *SpikeGuardHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, eventID, ok := userAndEvent(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	challenge, err := h.guardService.Challenge(r.Context(), userID, eventID)
+	if err != nil {
+		h.writeError(w, r, "issue spike challenge failed", err)
+		return
+	}
+
+	resp.OK(w, challenge, reqID, "")
+}
+
+// Solve 提交挑战答案，换取一次性下单凭证
+// POST /api/v1/spike/events/{id}/challenge
+func (h *SpikeGuardHandler) Solve(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, eventID, ok := userAndEvent(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	var req domain.SolveSpikeChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+		return
+	}
+
+	token, err := h.guardService.Solve(r.Context(), userID, eventID, &req)
+	if err != nil {
+		h.writeError(w, r, "solve spike challenge failed", err)
+		return
+	}
+
+	resp.OK(w, token, reqID, "")
+}
+
+// Path 获取隐藏下单地址，活动开始后才会下发
+// GET /api/v1/spike/events/{id}/purchase-path
+func (h *SpikeGuardHandler) Path(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, eventID, ok := userAndEvent(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	path, err := h.guardService.PurchasePath(r.Context(), userID, eventID)
+	if err != nil {
+		h.writeError(w, r, "get spike purchase path failed", err)
+		return
+	}
+
+	resp.OK(w, path, reqID, "")
+}
+
+// Gate 返回一个包装器，校验隐藏下单路径（路径参数 path）并消耗一次性下单凭证（X-Spike-Token）
+func (h *SpikeGuardHandler) Gate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqID := middleware.RequestIDFromContext(r.Context())
+
+		userID, eventID, ok := userAndEvent(w, r, reqID)
+		if !ok {
+			return
+		}
+
+		if err := h.guardService.Verify(r.Context(), userID, eventID, r.PathValue("path"), r.Header.Get(HeaderSpikeToken)); err != nil {
+			h.writeError(w, r, "verify spike purchase failed", err)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// writeError 将服务层错误映射为统一响应；请求已超时或被取消时返回统一超时响应
+func (h *SpikeGuardHandler) writeError(w http.ResponseWriter, r *http.Request, failMsg string, err error) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+	switch {
+	case errors.Is(err, service.ErrSpikeEventNotFound), errors.Is(err, service.ErrSpikePathInvalid):
+		// 错误的隐藏地址与不存在的活动表现一致，不暴露地址是否有效
+		resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "not found", reqID, "")
+	case errors.Is(err, service.ErrSpikeEventNotLive):
+		resp.Error(w, resp.HTTPStatusFromCode(resp.CodeSpikeNotActive), resp.CodeSpikeNotActive, "spike event is not live", reqID, "")
+	case errors.Is(err, service.ErrSpikeChallengeInvalid), errors.Is(err, service.ErrSpikeChallengeFailed):
+		resp.Error(w, http.StatusBadRequest, resp.CodeSpikeChallenge, err.Error(), reqID, "")
+	case errors.Is(err, service.ErrSpikeTokenInvalid):
+		resp.Error(w, resp.HTTPStatusFromCode(resp.CodeSpikeChallenge), resp.CodeSpikeChallenge, err.Error(), reqID, "")
+	default:
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error(failMsg, zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, failMsg, reqID, "")
+	}
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+const (
+	// spikeStreamPollInterval SSE 推送时轮询结果存储的间隔
+	spikeStreamPollInterval = 500 * time.Millisecond
+	// spikeStreamHeartbeat SSE 心跳间隔，防止中间代理断开空闲连接
+	spikeStreamHeartbeat = 15 * time.Second
+	// spikeStreamMaxDuration 单个 SSE 连接最长保持时间，超时后客户端可重连
+	spikeStreamMaxDuration = 2 * time.Minute
+)
+
+// SpikeHandler 秒杀下单相关的HTTP处理器
+type SpikeHandler struct {
+	spikeService service.SpikeService
+	logger       *zap.Logger
+}
+
+// NewSpikeHandler 创建秒杀下单处理器实例
+func NewSpikeHandler(spikeService service.SpikeService, logger *zap.Logger) *SpikeHandler {
+	return &SpikeHandler{
+		spikeService: spikeService,
+		logger:       logger,
+	}
+}
+
+// Purchase 秒杀下单：受理成功后立即返回排队凭证（202），订单由消费者异步创建
+// POST /api/v1/spike/events/{id}/purchase
+//
+// 请求体：{"quantity":1,"coupon_codes":[],"address_id":0}，可省略
+// 收货地址必填：address_id 为 0 时使用默认地址；用户尚未创建收货地址时返回 400（shipping address required），
+// 须先通过 POST /api/v1/addresses 创建地址
+func (h *SpikeHandler) Purchase(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, err := currentUserID(r)
+	if err != nil {
+		resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	eventID, err := pathID(r, "id")
+	if err != nil {
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	// 请求体可省略，默认购买 1 件
+	var req domain.SpikePurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+		return
+	}
+
+	ticket, err := h.spikeService.Purchase(r.Context(), userID, eventID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSpikeEventNotFound):
+			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "spike event not found", reqID, "")
+		case errors.Is(err, service.ErrInvalidSpikePurchase):
+			resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+		case errors.Is(err, service.ErrSpikeEventNotLive):
+			resp.Error(w, resp.HTTPStatusFromCode(resp.CodeSpikeNotActive), resp.CodeSpikeNotActive, "spike event is not live", reqID, "")
+		case errors.Is(err, service.ErrSpikeSoldOut):
+			resp.Error(w, resp.HTTPStatusFromCode(resp.CodeSpikeSoldOut), resp.CodeSpikeSoldOut, "sold out", reqID, "")
+		case errors.Is(err, service.ErrSpikeDuplicate):
+			resp.Error(w, resp.HTTPStatusFromCode(resp.CodeSpikeDuplicate), resp.CodeSpikeDuplicate, "already purchased in this spike event", reqID, "")
+		case errors.Is(err, service.ErrCouponUnavailable):
+			resp.Error(w, resp.HTTPStatusFromCode(resp.CodeCouponUnavailable), resp.CodeCouponUnavailable, err.Error(), reqID, "")
+		case errors.Is(err, service.ErrAddressNotFound):
+			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "address not found", reqID, "")
+		case errors.Is(err, service.ErrAddressRequired):
+			resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "shipping address required", reqID, "")
+		default:
+			// 请求超时或客户端断开导致受理被取消
+			if middleware.HandleTimeout(w, r) {
+				return
+			}
+			h.logger.Error("spike purchase failed", zap.String("request_id", reqID), zap.Error(err))
+			resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "spike purchase failed", reqID, "")
+		}
+		return
+	}
+
+	resp.WriteJSON(w, http.StatusAccepted, resp.CodeOK, "queued", ticket, reqID, "")
+}
+
+// GetResult 查询秒杀排队凭证的处理结果
+// GET /api/v1/spike/orders/{ticket}
+func (h *SpikeHandler) GetResult(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, err := currentUserID(r)
+	if err != nil {
+		resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	result, err := h.spikeService.GetResult(r.Context(), userID, r.PathValue("ticket"))
+	if err != nil {
+		if errors.Is(err, service.ErrSpikeTicketNotFound) {
+			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "ticket not found", reqID, "")
+			return
+		}
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error("get spike result failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get spike result failed", reqID, "")
+		return
+	}
+
+	resp.OK(w, result, reqID, "")
+}
+
+// StreamResult 以 Server-Sent Events 推送秒杀排队凭证的状态变化，进入终态后关闭连接
+// GET /api/v1/spike/orders/{ticket}/stream
+//
+// 事件格式：
+//
+//	event: status
+//	data: {"ticket":"...","status":"queued|succeeded|failed|sold_out",...}
+func (h *SpikeHandler) StreamResult(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, err := currentUserID(r)
+	if err != nil {
+		resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, err.Error(), reqID, "")
+		return
+	}
+
+	ticket := r.PathValue("ticket")
+	result, err := h.spikeService.GetResult(r.Context(), userID, ticket)
+	if err != nil {
+		if errors.Is(err, service.ErrSpikeTicketNotFound) {
+			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "ticket not found", reqID, "")
+			return
+		}
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error("get spike result failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get spike result failed", reqID, "")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(result *domain.SpikeOrderResult) bool {
+		data, _ := json.Marshal(result)
+		if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !send(result) || result.Status.IsFinal() {
+		return
+	}
+
+	poll := time.NewTicker(spikeStreamPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(spikeStreamHeartbeat)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(spikeStreamMaxDuration)
+	defer deadline.Stop()
+
+	last := result.Status
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case <-poll.C:
+			result, err := h.spikeService.GetResult(r.Context(), userID, ticket)
+			if err != nil {
+				h.logger.Warn("poll spike result failed", zap.String("request_id", reqID), zap.Error(err))
+				return
+			}
+			if result.Status == last {
+				continue
+			}
+			last = result.Status
+			if !send(result) || result.Status.IsFinal() {
+				return
+			}
+		}
+	}
+}
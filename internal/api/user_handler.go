@@ -48,13 +48,17 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 调用服务层进行注册
-	user, err := h.userService.Register(&req)
+	user, err := h.userService.Register(r.Context(), &req)
 	if err != nil {
 		// 根据不同的错误类型返回不同的HTTP状态码
 		if errors.Is(err, service.ErrUserExists) {
 			resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, "username or email already exists", reqID, "")
 			return
 		}
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
 
 		h.logger.Error("register failed", zap.String("request_id", reqID), zap.Error(err))
 		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "register failed", reqID, "")
@@ -94,7 +98,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 调用服务层进行登陆
-	user, err := h.userService.Login(&req)
+	user, err := h.userService.Login(r.Context(), &req)
 	if err != nil {
 		// 根据不同的错误类型返回不同的HTTP状态码
 		if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrInvalidCredentials) {
@@ -105,6 +109,9 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 			resp.Error(w, http.StatusForbidden, resp.CodeInvalidParam, "user is inactive", reqID, "")
 			return
 		}
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
 
 		h.logger.Error("login failed", zap.String("request_id", reqID), zap.Error(err))
 		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "login failed", reqID, "")
@@ -151,12 +158,15 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 获取用户信息
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.GetUserByID(r.Context(), userID)
 	if err != nil {
 		if errors.Is(err, service.ErrUserNotFound) {
 			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, "")
 			return
 		}
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
 
 		h.logger.Error("get profile failed", zap.String("request_id", reqID), zap.Error(err))
 		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get profile failed", reqID, "")
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/danta7/go_mall/internal/middleware"
+	"github.com/danta7/go_mall/internal/resp"
+	"github.com/danta7/go_mall/internal/service"
+	"go.uber.org/zap"
+)
+
+// HeaderQueueToken 携带排队凭证的请求头
+const HeaderQueueToken = "X-Queue-Token"
+
+// WaitingRoomHandler 秒杀排队室相关的HTTP处理器
+type WaitingRoomHandler struct {
+	waitingRoomService service.WaitingRoomService
+	logger             *zap.Logger
+}
+
+// NewWaitingRoomHandler 创建排队室处理器实例
+func NewWaitingRoomHandler(waitingRoomService service.WaitingRoomService, logger *zap.Logger) *WaitingRoomHandler {
+	return &WaitingRoomHandler{
+		waitingRoomService: waitingRoomService,
+		logger:             logger,
+	}
+}
+
+// Join 进入活动排队，返回排队凭证、位置与预计等待时间
+// POST /api/v1/spike/events/{id}/queue
+func (h *WaitingRoomHandler) Join(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, eventID, ok := userAndEvent(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	status, err := h.waitingRoomService.Join(r.Context(), userID, eventID)
+	if err != nil {
+		h.writeError(w, r, "join waiting room failed", err)
+		return
+	}
+
+	resp.OK(w, status, reqID, "")
+}
+
+// Status 查询排队位置与预计等待时间，凭证通过 X-Queue-Token 请求头或 token 查询参数携带
+// GET /api/v1/spike/events/{id}/queue
+func (h *WaitingRoomHandler) Status(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+
+	userID, eventID, ok := userAndEvent(w, r, reqID)
+	if !ok {
+		return
+	}
+
+	token := r.Header.Get(HeaderQueueToken)
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+
+	status, err := h.waitingRoomService.Status(r.Context(), userID, eventID, token)
+	if err != nil {
+		h.writeError(w, r, "get waiting room status failed", err)
+		return
+	}
+
+	resp.OK(w, status, reqID, "")
+}
+
+// Gate 返回一个包装器，仅允许持有已放行排队凭证（X-Queue-Token）的用户访问被包装的处理器
+func (h *WaitingRoomHandler) Gate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqID := middleware.RequestIDFromContext(r.Context())
+
+		userID, eventID, ok := userAndEvent(w, r, reqID)
+		if !ok {
+			return
+		}
+
+		wait, err := h.waitingRoomService.CheckAdmitted(r.Context(), userID, eventID, r.Header.Get(HeaderQueueToken))
+		if err != nil {
+			if errors.Is(err, service.ErrQueueNotAdmitted) {
+				w.Header().Set("Retry-After", strconv.FormatInt(max(int64(math.Ceil(wait.Seconds())), 1), 10))
+			}
+			h.writeError(w, r, "check waiting room failed", err)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// writeError 将服务层错误映射为统一响应；请求已超时或被取消时返回统一超时响应
+func (h *WaitingRoomHandler) writeError(w http.ResponseWriter, r *http.Request, failMsg string, err error) {
+	reqID := middleware.RequestIDFromContext(r.Context())
+	switch {
+	case errors.Is(err, service.ErrSpikeEventNotFound):
+		resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "spike event not found", reqID, "")
+	case errors.Is(err, service.ErrSpikeEventNotLive):
+		resp.Error(w, resp.HTTPStatusFromCode(resp.CodeSpikeNotActive), resp.CodeSpikeNotActive, "spike event is not open for queueing", reqID, "")
+	case errors.Is(err, service.ErrQueueTokenInvalid):
+		resp.Error(w, resp.HTTPStatusFromCode(resp.CodeSpikeNotAdmitted), resp.CodeSpikeNotAdmitted, "missing or invalid queue token", reqID, "")
+	case errors.Is(err, service.ErrQueueNotAdmitted):
+		resp.Error(w, resp.HTTPStatusFromCode(resp.CodeSpikeNotAdmitted), resp.CodeSpikeNotAdmitted, "still waiting in queue", reqID, "")
+	default:
+		// 请求超时或客户端断开导致查询被取消
+		if middleware.HandleTimeout(w, r) {
+			return
+		}
+		h.logger.Error(failMsg, zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, failMsg, reqID, "")
+	}
+}
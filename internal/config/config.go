@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/joho/godotenv"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -18,9 +20,36 @@ import (
 //   - APP_ENV=dev|test|prod（默认 dev）
 //   - APP_PORT（默认 8080）
 //   - REQUEST_TIMEOUT_MS（默认 5000）
+//   - ADMIN_API_ENABLED（默认 false，生产环境不允许开启，见 App.AdminAPIEnabled）
 //   - LOG_LEVEL=debug|info|warn|error（默认 info）
 //   - LOG_ENCODING=json|console（默认 json）
 //   - CORS_ALLOWED_ORIGINS, CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS（CSV）
+//   - MYSQL_HOST（默认 localhost）、MYSQL_PORT（默认 3306）、MYSQL_USER、MYSQL_PASSWORD、MYSQL_DB
+//   - MYSQL_MAX_OPEN_CONNS（默认 25）、MYSQL_MAX_IDLE_CONNS（默认 10）、MYSQL_CONN_MAX_LIFETIME（默认 30m）、MYSQL_CONN_MAX_IDLE_TIME（默认 5m）
+//   - MYSQL_DIAL_TIMEOUT（默认 5s）、MYSQL_READ_TIMEOUT、MYSQL_WRITE_TIMEOUT（默认 0，不限制）
+//   - MYSQL_TLS=false|true|skip-verify|preferred（默认 false）、MYSQL_COLLATION（默认 utf8mb4_unicode_ci）、MYSQL_TIMEZONE（默认 Local）
+//   - MYSQL_REPLICAS（从库 host:port，CSV，默认为空）、MYSQL_REPLICA_CHECK_INTERVAL（默认 5s）
+//   - REDIS_HOST（默认 localhost）、REDIS_PORT（默认 6379）、REDIS_PASSWORD、REDIS_DB（默认 0）
+//   - SPIKE_STOCK_BACKEND=memory|redis（默认 memory）
+//   - SPIKE_ORDER_WORKERS（默认 4）
+//   - SPIKE_RESULT_TTL（默认 24h）
+//   - SPIKE_RECONCILE_INTERVAL（默认 1m）、SPIKE_RECONCILE_RESTORE_AFTER（默认 30m）
+//   - WAITING_ROOM_ENABLED（默认 false）、WAITING_ROOM_ADMIT_RATE（每秒放行人数，默认 100）、WAITING_ROOM_SECRET（默认同 JWT_SECRET）
+//   - ANTI_BOT_ENABLED（默认 false）、ANTI_BOT_DIFFICULTY（工作量证明前导零比特数，默认 20）、ANTI_BOT_SECRET（默认同 JWT_SECRET）
+//   - ANTI_BOT_CHALLENGE_TTL（默认 2m）、ANTI_BOT_TOKEN_TTL（默认 5m）
+//   - RATE_LIMIT_ENABLED（默认 true）、RATE_LIMIT_BACKEND=memory|redis（默认 memory）、RATE_LIMIT_TRUST_PROXY（默认 false）
+//   - RATE_LIMIT_GLOBAL_RPS（默认 5000）、RATE_LIMIT_GLOBAL_BURST（默认 10000）
+//   - RATE_LIMIT_IP_RPS（默认 50）、RATE_LIMIT_IP_BURST（默认 100）
+//   - RATE_LIMIT_SPIKE_USER_LIMIT（默认 5）、RATE_LIMIT_SPIKE_USER_WINDOW（默认 1s）
+//   - IDEMPOTENCY_ENABLED（默认 true）、IDEMPOTENCY_BACKEND=memory|redis（默认 memory）
+//   - IDEMPOTENCY_TTL（默认 24h）、IDEMPOTENCY_LOCK_TTL（默认 1m）
+//   - SHIPPING_MODE=flat（默认 flat）、SHIPPING_FLAT_FEE（分，默认 1000）、SHIPPING_FREE_THRESHOLD（分，0 表示不包邮，默认 0）
+//   - OUTBOX_POLL_INTERVAL_MS（默认 1000）、OUTBOX_BATCH_SIZE（默认 100）、OUTBOX_MAX_ATTEMPTS（默认 10）
+//   - MQ_BACKEND=memory|amqp（默认 memory）
+//   - MQ_RETRY_MAX_ATTEMPTS（默认 5）、MQ_RETRY_BASE_DELAY_MS（默认 200）、MQ_RETRY_MAX_DELAY_MS（默认 5000）
+//   - MIGRATIONS_DIR（默认为空，使用内嵌迁移文件）、MIGRATIONS_ON_STARTUP（默认 true）
+//   - MIGRATIONS_ON_DRIFT=fail|warn（默认 fail）、MIGRATIONS_LOCK_TIMEOUT（默认 1m）
+//   - RABBITMQ_HOST、RABBITMQ_AMQP_PORT（默认 5672）、RABBITMQ_USER、RABBITMQ_PASSWORD、RABBITMQ_VHOST（默认 /）
 type Config struct {
 	App struct {
 		Name            string
@@ -29,6 +58,9 @@ type Config struct {
 		RequestTimeout  time.Duration
 		Version         string
 		ShutdownTimeout time.Duration
+		// AdminAPIEnabled 是否开放管理端接口（/api/v1/admin/*、/debug/vars）
+		// 接入 JWT 之前操作人身份取自客户端传入的 user_id，任何人都能冒充管理员，因此默认关闭且生产环境不允许开启
+		AdminAPIEnabled bool
 	}
 
 	Log struct {
@@ -48,6 +80,135 @@ type Config struct {
 		User     string
 		Password string
 		DBName   string
+		// MaxOpenConns/MaxIdleConns 连接池最大连接数与最大空闲连接数
+		MaxOpenConns int
+		MaxIdleConns int
+		// ConnMaxLifetime/ConnMaxIdleTime 连接最长存活时间与最长空闲时间，应小于 MySQL wait_timeout 与中间代理的空闲超时；0 表示不限制
+		ConnMaxLifetime time.Duration
+		ConnMaxIdleTime time.Duration
+		// DialTimeout 建立连接超时
+		DialTimeout time.Duration
+		// ReadTimeout/WriteTimeout 单次读写 I/O 超时，0 表示不限制；
+		// 会作用于迁移中的长时间 DDL 与等待迁移锁，设置时须大于 MIGRATIONS_LOCK_TIMEOUT
+		ReadTimeout  time.Duration
+		WriteTimeout time.Duration
+		// TLS 连接加密方式：false（不加密）| true（校验证书）| skip-verify（加密但不校验证书）| preferred（服务端支持时加密）
+		TLS string
+		// Collation 连接使用的排序规则，须为 utf8mb4 字符集
+		Collation string
+		// Timezone 解析 DATETIME/TIMESTAMP 使用的时区（IANA 名称，如 Asia/Shanghai，或 Local、UTC）
+		Timezone string
+		// Replicas 只读从库地址（host:port），与主库使用相同的账号与连接参数；为空时读写均走主库
+		Replicas []string
+		// ReplicaCheckInterval 从库健康检查间隔，不可用的从库恢复后重新参与读流量
+		ReplicaCheckInterval time.Duration
+	}
+
+	Redis struct {
+		Host     string
+		Port     int
+		Password string
+		DB       int
+	}
+
+	RabbitMQ struct {
+		Host     string
+		Port     int
+		User     string
+		Password string
+		VHost    string
+	}
+
+	MQ struct {
+		// Backend 消息队列实现：memory（进程内，不持久化）| amqp（RabbitMQ）
+		Backend string
+		// RetryMaxAttempts 消费失败的最大处理次数（含首次），超过后转入死信
+		RetryMaxAttempts int
+		// RetryBaseDelay/RetryMaxDelay 消费重试的指数退避区间
+		RetryBaseDelay time.Duration
+		RetryMaxDelay  time.Duration
+	}
+
+	Spike struct {
+		// StockBackend 秒杀库存计数器、去重标记与下单结果存储实现：memory（单实例）| redis（多实例共享）
+		StockBackend string
+		// OrderWorkers 秒杀下单消费者并发数
+		OrderWorkers int
+		// ResultTTL 排队凭证处理结果的保留时长
+		ResultTTL time.Duration
+		// ReconcileInterval 库存计数器与数据库库存的对账间隔
+		ReconcileInterval time.Duration
+		// ReconcileRestoreAfter 计数器偏少且售出件数与计数器持续不变超过该时长才回补，
+		// 须覆盖消息积压与消费重试退避的最长时间，避免把在途订单误判为泄漏
+		ReconcileRestoreAfter time.Duration
+	}
+
+	WaitingRoom struct {
+		// Enabled 开启后秒杀下单须持有已放行的排队凭证
+		Enabled bool
+		// AdmitRate 每个活动每秒放行人数
+		AdmitRate int
+		// Secret 排队凭证签名密钥
+		Secret string
+	}
+
+	AntiBot struct {
+		// Enabled 开启后秒杀下单须通过隐藏下单地址并携带一次性下单凭证
+		Enabled bool
+		// Difficulty 工作量证明难度（哈希前导零比特数）
+		Difficulty int
+		// ChallengeTTL 挑战有效期
+		ChallengeTTL time.Duration
+		// TokenTTL 一次性下单凭证有效期
+		TokenTTL time.Duration
+		// Secret 挑战与隐藏地址签名密钥
+		Secret string
+	}
+
+	RateLimit struct {
+		Enabled bool
+		// Backend 限流计数存储：memory（单实例）| redis（多实例共享）
+		Backend string
+		// TrustProxy 是否信任 X-Forwarded-For 获取客户端 IP（仅在可信反向代理之后开启）
+		TrustProxy bool
+		// GlobalRPS/GlobalBurst 全局令牌桶
+		GlobalRPS   int
+		GlobalBurst int
+		// IPRPS/IPBurst 按客户端 IP 的令牌桶
+		IPRPS   int
+		IPBurst int
+		// SpikeUserLimit/SpikeUserWindow 秒杀下单接口按用户的滑动窗口
+		SpikeUserLimit  int
+		SpikeUserWindow time.Duration
+	}
+
+	Idempotency struct {
+		Enabled bool
+		// Backend 幂等记录存储：memory（单实例）| redis（多实例共享）
+		Backend string
+		// TTL 已完成响应的保留时长
+		TTL time.Duration
+		// LockTTL 处理中占位的有效期
+		LockTTL time.Duration
+	}
+
+	Shipping struct {
+		// Mode 运费计算方式：目前仅支持 flat（固定运费）；
+		// 商品尚无重量数据，按重量计费（首重 + 续重）在有数据来源前不开放
+		Mode string
+		// FlatFee 固定运费（分）
+		FlatFee int64
+		// FreeThreshold 商品小计满该金额（分）包邮，0 表示不包邮
+		FreeThreshold int64
+	}
+
+	Outbox struct {
+		// PollInterval relay 扫描本地消息表的间隔
+		PollInterval time.Duration
+		// BatchSize 每次认领的最大消息数
+		BatchSize int
+		// MaxAttempts 最大投递次数，超过后标记为 failed 不再重试
+		MaxAttempts int
 	}
 
 	JWT struct {
@@ -57,7 +218,14 @@ type Config struct {
 	}
 
 	Migrations struct {
+		// Dir 迁移文件目录，为空时使用编译进二进制的迁移文件（开发时可指向本地目录覆盖）
 		Dir string
+		// OnStartup 服务启动时是否自动执行迁移；关闭后由 cmd/migrate 在发布流程中单独执行
+		OnStartup bool
+		// OnDrift 已执行迁移被修改、缺失或存在漏执行的旧版本时的处理方式：fail（拒绝启动）| warn（记录警告后继续）
+		OnDrift string
+		// LockTimeout 多实例同时启动时等待其他实例完成迁移的最长时间
+		LockTimeout time.Duration
 	}
 }
 
@@ -71,6 +239,7 @@ func Load() (*Config, error) {
 
 	c.App.Name = getEnv("APP_NAME", "Spike-server")
 	c.App.Env = getEnv("APP_ENV", "dev")
+	c.App.AdminAPIEnabled = getEnvAsBool("ADMIN_API_ENABLED", false)
 	c.App.Port = getEnvAsInt("APP_PORT", 8080)
 	c.App.RequestTimeout = getEnvAsDurationMs("REQUEST_TIMEOUT_MS", 5000)
 	c.App.Version = getEnv("APP_VERSION", "0.1.0")
@@ -88,13 +257,83 @@ func Load() (*Config, error) {
 	c.Database.User = getEnv("MYSQL_USER", "spike")
 	c.Database.Password = getEnv("MYSQL_PASSWORD", "spike")
 	c.Database.DBName = getEnv("MYSQL_DB", "spike")
+	c.Database.MaxOpenConns = getEnvAsInt("MYSQL_MAX_OPEN_CONNS", 25)
+	c.Database.MaxIdleConns = getEnvAsInt("MYSQL_MAX_IDLE_CONNS", 10)
+	c.Database.ConnMaxLifetime = getEnvAsDuration("MYSQL_CONN_MAX_LIFETIME", "30m")
+	c.Database.ConnMaxIdleTime = getEnvAsDuration("MYSQL_CONN_MAX_IDLE_TIME", "5m")
+	c.Database.DialTimeout = getEnvAsDuration("MYSQL_DIAL_TIMEOUT", "5s")
+	c.Database.ReadTimeout = getEnvAsDuration("MYSQL_READ_TIMEOUT", "0s")
+	c.Database.WriteTimeout = getEnvAsDuration("MYSQL_WRITE_TIMEOUT", "0s")
+	c.Database.TLS = strings.ToLower(getEnv("MYSQL_TLS", "false"))
+	c.Database.Collation = strings.ToLower(getEnv("MYSQL_COLLATION", "utf8mb4_unicode_ci"))
+	c.Database.Timezone = getEnv("MYSQL_TIMEZONE", "Local")
+	c.Database.Replicas = getEnvAsCSV("MYSQL_REPLICAS", nil)
+	c.Database.ReplicaCheckInterval = getEnvAsDuration("MYSQL_REPLICA_CHECK_INTERVAL", "5s")
+
+	c.Redis.Host = getEnv("REDIS_HOST", "localhost")
+	c.Redis.Port = getEnvAsInt("REDIS_PORT", 6379)
+	c.Redis.Password = getEnv("REDIS_PASSWORD", "")
+	c.Redis.DB = getEnvAsInt("REDIS_DB", 0)
+
+	c.RabbitMQ.Host = getEnv("RABBITMQ_HOST", "localhost")
+	c.RabbitMQ.Port = getEnvAsInt("RABBITMQ_AMQP_PORT", 5672)
+	c.RabbitMQ.User = getEnv("RABBITMQ_USER", "guest")
+	c.RabbitMQ.Password = getEnv("RABBITMQ_PASSWORD", "guest")
+	c.RabbitMQ.VHost = getEnv("RABBITMQ_VHOST", "/")
+
+	c.MQ.Backend = strings.ToLower(getEnv("MQ_BACKEND", "memory"))
+	c.MQ.RetryMaxAttempts = getEnvAsInt("MQ_RETRY_MAX_ATTEMPTS", 5)
+	c.MQ.RetryBaseDelay = getEnvAsDurationMs("MQ_RETRY_BASE_DELAY_MS", 200)
+	c.MQ.RetryMaxDelay = getEnvAsDurationMs("MQ_RETRY_MAX_DELAY_MS", 5000)
+
+	c.Spike.StockBackend = strings.ToLower(getEnv("SPIKE_STOCK_BACKEND", "memory"))
+	c.Spike.OrderWorkers = getEnvAsInt("SPIKE_ORDER_WORKERS", 4)
+	c.Spike.ResultTTL = getEnvAsDuration("SPIKE_RESULT_TTL", "24h")
+	c.Spike.ReconcileInterval = getEnvAsDuration("SPIKE_RECONCILE_INTERVAL", "1m")
+	c.Spike.ReconcileRestoreAfter = getEnvAsDuration("SPIKE_RECONCILE_RESTORE_AFTER", "30m")
+
+	c.RateLimit.Enabled = getEnvAsBool("RATE_LIMIT_ENABLED", true)
+	c.RateLimit.Backend = strings.ToLower(getEnv("RATE_LIMIT_BACKEND", "memory"))
+	c.RateLimit.TrustProxy = getEnvAsBool("RATE_LIMIT_TRUST_PROXY", false)
+	c.RateLimit.GlobalRPS = getEnvAsInt("RATE_LIMIT_GLOBAL_RPS", 5000)
+	c.RateLimit.GlobalBurst = getEnvAsInt("RATE_LIMIT_GLOBAL_BURST", 10000)
+	c.RateLimit.IPRPS = getEnvAsInt("RATE_LIMIT_IP_RPS", 50)
+	c.RateLimit.IPBurst = getEnvAsInt("RATE_LIMIT_IP_BURST", 100)
+	c.RateLimit.SpikeUserLimit = getEnvAsInt("RATE_LIMIT_SPIKE_USER_LIMIT", 5)
+	c.RateLimit.SpikeUserWindow = getEnvAsDuration("RATE_LIMIT_SPIKE_USER_WINDOW", "1s")
+
+	c.Idempotency.Enabled = getEnvAsBool("IDEMPOTENCY_ENABLED", true)
+	c.Idempotency.Backend = strings.ToLower(getEnv("IDEMPOTENCY_BACKEND", "memory"))
+	c.Idempotency.TTL = getEnvAsDuration("IDEMPOTENCY_TTL", "24h")
+	c.Idempotency.LockTTL = getEnvAsDuration("IDEMPOTENCY_LOCK_TTL", "1m")
+
+	c.Shipping.Mode = strings.ToLower(getEnv("SHIPPING_MODE", "flat"))
+	c.Shipping.FlatFee = int64(getEnvAsInt("SHIPPING_FLAT_FEE", 1000))
+	c.Shipping.FreeThreshold = int64(getEnvAsInt("SHIPPING_FREE_THRESHOLD", 0))
+
+	c.Outbox.PollInterval = getEnvAsDurationMs("OUTBOX_POLL_INTERVAL_MS", 1000)
+	c.Outbox.BatchSize = getEnvAsInt("OUTBOX_BATCH_SIZE", 100)
+	c.Outbox.MaxAttempts = getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10)
 
 	c.JWT.Secret = getEnv("JWT_SECRET", "change_me_in_production")
 	c.JWT.AccessTokenTTL = getEnvAsDuration("ACCESS_TOKEN_TTL", "15m")
 	c.JWT.RefreshTokenTTL = getEnvAsDuration("REFRESH_TOKEN_TTL", "168h")
 
+	c.WaitingRoom.Enabled = getEnvAsBool("WAITING_ROOM_ENABLED", false)
+	c.WaitingRoom.AdmitRate = getEnvAsInt("WAITING_ROOM_ADMIT_RATE", 100)
+	c.WaitingRoom.Secret = getEnv("WAITING_ROOM_SECRET", c.JWT.Secret)
+
+	c.AntiBot.Enabled = getEnvAsBool("ANTI_BOT_ENABLED", false)
+	c.AntiBot.Difficulty = getEnvAsInt("ANTI_BOT_DIFFICULTY", 20)
+	c.AntiBot.ChallengeTTL = getEnvAsDuration("ANTI_BOT_CHALLENGE_TTL", "2m")
+	c.AntiBot.TokenTTL = getEnvAsDuration("ANTI_BOT_TOKEN_TTL", "5m")
+	c.AntiBot.Secret = getEnv("ANTI_BOT_SECRET", c.JWT.Secret)
+
 	// 数据库迁移配置
-	c.Migrations.Dir = getEnv("MIGRATIONS_DIR", "migrations")
+	c.Migrations.Dir = getEnv("MIGRATIONS_DIR", "")
+	c.Migrations.OnStartup = getEnvAsBool("MIGRATIONS_ON_STARTUP", true)
+	c.Migrations.OnDrift = strings.ToLower(getEnv("MIGRATIONS_ON_DRIFT", "fail"))
+	c.Migrations.LockTimeout = getEnvAsDuration("MIGRATIONS_LOCK_TIMEOUT", "1m")
 
 	if err := validate(c); err != nil {
 		return nil, err
@@ -108,7 +347,17 @@ func validate(c *Config) error {
 	errs = append(errs, validateApp(c)...)
 	errs = append(errs, validateLog(c)...)
 	errs = append(errs, validateDatabase(c)...)
+	errs = append(errs, validateRedis(c)...)
+	errs = append(errs, validateMQ(c)...)
+	errs = append(errs, validateSpike(c)...)
+	errs = append(errs, validateWaitingRoom(c)...)
+	errs = append(errs, validateAntiBot(c)...)
+	errs = append(errs, validateRateLimit(c)...)
+	errs = append(errs, validateIdempotency(c)...)
+	errs = append(errs, validateShipping(c)...)
+	errs = append(errs, validateOutbox(c)...)
 	errs = append(errs, validateJWT(c)...)
+	errs = append(errs, validateMigrations(c)...)
 
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "; "))
@@ -134,6 +383,10 @@ func validateApp(c *Config) []string {
 	if c.App.RequestTimeout <= 0 {
 		errs = append(errs, fmt.Sprintf("REQUEST_TIMEOUT_MS must be > 0, got %s", c.App.RequestTimeout))
 	}
+
+	if c.App.AdminAPIEnabled && c.App.Env == "prod" {
+		errs = append(errs, "ADMIN_API_ENABLED cannot be enabled in production until admin requests are authenticated")
+	}
 	return errs
 }
 
@@ -173,6 +426,243 @@ func validateDatabase(c *Config) []string {
 		errs = append(errs, "MYSQL_DB cannot be empty")
 	}
 
+	if c.Database.MaxOpenConns < 1 {
+		errs = append(errs, fmt.Sprintf("MYSQL_MAX_OPEN_CONNS must be >= 1, got %d", c.Database.MaxOpenConns))
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, fmt.Sprintf("MYSQL_MAX_IDLE_CONNS must be in range 0..MYSQL_MAX_OPEN_CONNS(%d), got %d", c.Database.MaxOpenConns, c.Database.MaxIdleConns))
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Sprintf("MYSQL_CONN_MAX_LIFETIME must be >= 0, got %s", c.Database.ConnMaxLifetime))
+	}
+	if c.Database.ConnMaxIdleTime < 0 {
+		errs = append(errs, fmt.Sprintf("MYSQL_CONN_MAX_IDLE_TIME must be >= 0, got %s", c.Database.ConnMaxIdleTime))
+	}
+	if c.Database.DialTimeout <= 0 {
+		errs = append(errs, fmt.Sprintf("MYSQL_DIAL_TIMEOUT must be > 0, got %s", c.Database.DialTimeout))
+	}
+	if c.Database.ReadTimeout < 0 {
+		errs = append(errs, fmt.Sprintf("MYSQL_READ_TIMEOUT must be >= 0, got %s", c.Database.ReadTimeout))
+	}
+	// 迁移锁通过 GET_LOCK 阻塞等待，读超时过短会在等待期间断开连接
+	if c.Database.ReadTimeout > 0 && c.Database.ReadTimeout <= c.Migrations.LockTimeout {
+		errs = append(errs, fmt.Sprintf("MYSQL_READ_TIMEOUT must be 0 or greater than MIGRATIONS_LOCK_TIMEOUT(%s), got %s", c.Migrations.LockTimeout, c.Database.ReadTimeout))
+	}
+	if c.Database.WriteTimeout < 0 {
+		errs = append(errs, fmt.Sprintf("MYSQL_WRITE_TIMEOUT must be >= 0, got %s", c.Database.WriteTimeout))
+	}
+
+	switch c.Database.TLS {
+	case "false", "true", "skip-verify", "preferred":
+		// ok
+	default:
+		errs = append(errs, fmt.Sprintf("MYSQL_TLS must be one of false|true|skip-verify|preferred, got %q", c.Database.TLS))
+	}
+	if !strings.HasPrefix(c.Database.Collation, "utf8mb4_") {
+		errs = append(errs, fmt.Sprintf("MYSQL_COLLATION must be a utf8mb4 collation, got %q", c.Database.Collation))
+	}
+	if _, err := time.LoadLocation(c.Database.Timezone); err != nil {
+		errs = append(errs, fmt.Sprintf("MYSQL_TIMEZONE is invalid: %v", err))
+	}
+
+	for _, addr := range c.Database.Replicas {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || strings.TrimSpace(host) == "" {
+			errs = append(errs, fmt.Sprintf("MYSQL_REPLICAS entries must be host:port, got %q", addr))
+			continue
+		}
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			errs = append(errs, fmt.Sprintf("MYSQL_REPLICAS port must be in range 1..65535, got %q", addr))
+		}
+	}
+	if len(c.Database.Replicas) > 0 && c.Database.ReplicaCheckInterval < time.Second {
+		errs = append(errs, fmt.Sprintf("MYSQL_REPLICA_CHECK_INTERVAL must be >= 1s, got %s", c.Database.ReplicaCheckInterval))
+	}
+
+	return errs
+}
+
+func validateRedis(c *Config) []string {
+	var errs []string
+
+	if strings.TrimSpace(c.Redis.Host) == "" {
+		errs = append(errs, "REDIS_HOST cannot be empty")
+	}
+	if c.Redis.Port < 1 || c.Redis.Port > 65535 {
+		errs = append(errs, fmt.Sprintf("REDIS_PORT must be in range 1..65535, got %d", c.Redis.Port))
+	}
+	if c.Redis.DB < 0 {
+		errs = append(errs, fmt.Sprintf("REDIS_DB must be >= 0, got %d", c.Redis.DB))
+	}
+
+	return errs
+}
+
+func validateMQ(c *Config) []string {
+	var errs []string
+
+	switch c.MQ.Backend {
+	case "memory":
+		// ok
+	case "amqp":
+		if strings.TrimSpace(c.RabbitMQ.Host) == "" {
+			errs = append(errs, "RABBITMQ_HOST cannot be empty")
+		}
+		if c.RabbitMQ.Port < 1 || c.RabbitMQ.Port > 65535 {
+			errs = append(errs, fmt.Sprintf("RABBITMQ_AMQP_PORT must be in range 1..65535, got %d", c.RabbitMQ.Port))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("MQ_BACKEND must be one of memory|amqp, got %q", c.MQ.Backend))
+	}
+	if c.MQ.RetryMaxAttempts < 1 {
+		errs = append(errs, fmt.Sprintf("MQ_RETRY_MAX_ATTEMPTS must be >= 1, got %d", c.MQ.RetryMaxAttempts))
+	}
+	if c.MQ.RetryBaseDelay <= 0 {
+		errs = append(errs, fmt.Sprintf("MQ_RETRY_BASE_DELAY_MS must be > 0, got %s", c.MQ.RetryBaseDelay))
+	}
+	if c.MQ.RetryMaxDelay < c.MQ.RetryBaseDelay {
+		errs = append(errs, fmt.Sprintf("MQ_RETRY_MAX_DELAY_MS must be >= MQ_RETRY_BASE_DELAY_MS, got %s", c.MQ.RetryMaxDelay))
+	}
+
+	return errs
+}
+
+func validateSpike(c *Config) []string {
+	var errs []string
+
+	switch c.Spike.StockBackend {
+	case "memory", "redis":
+		// ok
+	default:
+		errs = append(errs, fmt.Sprintf("SPIKE_STOCK_BACKEND must be one of memory|redis, got %q", c.Spike.StockBackend))
+	}
+	if c.Spike.OrderWorkers < 1 {
+		errs = append(errs, fmt.Sprintf("SPIKE_ORDER_WORKERS must be >= 1, got %d", c.Spike.OrderWorkers))
+	}
+	if c.Spike.ResultTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_RESULT_TTL must be > 0, got %s", c.Spike.ResultTTL))
+	}
+	if c.Spike.ReconcileInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_RECONCILE_INTERVAL must be > 0, got %s", c.Spike.ReconcileInterval))
+	}
+	if c.Spike.ReconcileRestoreAfter < c.Spike.ReconcileInterval {
+		errs = append(errs, fmt.Sprintf("SPIKE_RECONCILE_RESTORE_AFTER must be >= SPIKE_RECONCILE_INTERVAL, got %s", c.Spike.ReconcileRestoreAfter))
+	}
+
+	return errs
+}
+
+func validateWaitingRoom(c *Config) []string {
+	var errs []string
+
+	if c.WaitingRoom.AdmitRate < 1 {
+		errs = append(errs, fmt.Sprintf("WAITING_ROOM_ADMIT_RATE must be >= 1, got %d", c.WaitingRoom.AdmitRate))
+	}
+	if c.WaitingRoom.Enabled && strings.TrimSpace(c.WaitingRoom.Secret) == "" {
+		errs = append(errs, "WAITING_ROOM_SECRET cannot be empty when waiting room is enabled")
+	}
+
+	return errs
+}
+
+func validateAntiBot(c *Config) []string {
+	var errs []string
+
+	// 难度每增加 1 比特，客户端平均计算量翻倍
+	if c.AntiBot.Difficulty < 0 || c.AntiBot.Difficulty > 32 {
+		errs = append(errs, fmt.Sprintf("ANTI_BOT_DIFFICULTY must be in [0, 32], got %d", c.AntiBot.Difficulty))
+	}
+	if c.AntiBot.ChallengeTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("ANTI_BOT_CHALLENGE_TTL must be > 0, got %s", c.AntiBot.ChallengeTTL))
+	}
+	if c.AntiBot.TokenTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("ANTI_BOT_TOKEN_TTL must be > 0, got %s", c.AntiBot.TokenTTL))
+	}
+	if c.AntiBot.Enabled && strings.TrimSpace(c.AntiBot.Secret) == "" {
+		errs = append(errs, "ANTI_BOT_SECRET cannot be empty when anti-bot is enabled")
+	}
+
+	return errs
+}
+
+func validateRateLimit(c *Config) []string {
+	var errs []string
+
+	switch c.RateLimit.Backend {
+	case "memory", "redis":
+		// ok
+	default:
+		errs = append(errs, fmt.Sprintf("RATE_LIMIT_BACKEND must be one of memory|redis, got %q", c.RateLimit.Backend))
+	}
+	if c.RateLimit.GlobalRPS < 1 || c.RateLimit.GlobalBurst < 1 {
+		errs = append(errs, fmt.Sprintf("RATE_LIMIT_GLOBAL_RPS and RATE_LIMIT_GLOBAL_BURST must be >= 1, got %d/%d", c.RateLimit.GlobalRPS, c.RateLimit.GlobalBurst))
+	}
+	if c.RateLimit.IPRPS < 1 || c.RateLimit.IPBurst < 1 {
+		errs = append(errs, fmt.Sprintf("RATE_LIMIT_IP_RPS and RATE_LIMIT_IP_BURST must be >= 1, got %d/%d", c.RateLimit.IPRPS, c.RateLimit.IPBurst))
+	}
+	if c.RateLimit.SpikeUserLimit < 1 {
+		errs = append(errs, fmt.Sprintf("RATE_LIMIT_SPIKE_USER_LIMIT must be >= 1, got %d", c.RateLimit.SpikeUserLimit))
+	}
+	if c.RateLimit.SpikeUserWindow < time.Millisecond {
+		errs = append(errs, fmt.Sprintf("RATE_LIMIT_SPIKE_USER_WINDOW must be >= 1ms, got %s", c.RateLimit.SpikeUserWindow))
+	}
+
+	return errs
+}
+
+func validateIdempotency(c *Config) []string {
+	var errs []string
+
+	switch c.Idempotency.Backend {
+	case "memory", "redis":
+		// ok
+	default:
+		errs = append(errs, fmt.Sprintf("IDEMPOTENCY_BACKEND must be one of memory|redis, got %q", c.Idempotency.Backend))
+	}
+	if c.Idempotency.TTL <= 0 {
+		errs = append(errs, fmt.Sprintf("IDEMPOTENCY_TTL must be > 0, got %s", c.Idempotency.TTL))
+	}
+	// 占位须覆盖整个请求处理时长，否则慢请求处理期间的重试会被重复执行
+	if c.Idempotency.LockTTL < c.App.RequestTimeout {
+		errs = append(errs, fmt.Sprintf("IDEMPOTENCY_LOCK_TTL must be >= REQUEST_TIMEOUT_MS (%s), got %s", c.App.RequestTimeout, c.Idempotency.LockTTL))
+	}
+
+	return errs
+}
+
+func validateShipping(c *Config) []string {
+	var errs []string
+
+	switch c.Shipping.Mode {
+	case "flat":
+		if c.Shipping.FlatFee < 0 {
+			errs = append(errs, fmt.Sprintf("SHIPPING_FLAT_FEE must be >= 0, got %d", c.Shipping.FlatFee))
+		}
+	case "weight":
+		errs = append(errs, "SHIPPING_MODE=weight is not supported yet: products carry no weight")
+	default:
+		errs = append(errs, fmt.Sprintf("SHIPPING_MODE must be flat, got %q", c.Shipping.Mode))
+	}
+	if c.Shipping.FreeThreshold < 0 {
+		errs = append(errs, fmt.Sprintf("SHIPPING_FREE_THRESHOLD must be >= 0, got %d", c.Shipping.FreeThreshold))
+	}
+
+	return errs
+}
+
+func validateOutbox(c *Config) []string {
+	var errs []string
+
+	if c.Outbox.PollInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("OUTBOX_POLL_INTERVAL_MS must be > 0, got %s", c.Outbox.PollInterval))
+	}
+	if c.Outbox.BatchSize < 1 {
+		errs = append(errs, fmt.Sprintf("OUTBOX_BATCH_SIZE must be >= 1, got %d", c.Outbox.BatchSize))
+	}
+	if c.Outbox.MaxAttempts < 1 {
+		errs = append(errs, fmt.Sprintf("OUTBOX_MAX_ATTEMPTS must be >= 1, got %d", c.Outbox.MaxAttempts))
+	}
+
 	return errs
 }
 
@@ -194,6 +684,20 @@ func validateJWT(c *Config) []string {
 	return errs
 }
 
+// AMQPURL 返回 RabbitMQ 连接地址，默认 vhost "/" 对应路径 "/"
+func (c *Config) AMQPURL() string {
+	u := url.URL{
+		Scheme: "amqp",
+		User:   url.UserPassword(c.RabbitMQ.User, c.RabbitMQ.Password),
+		Host:   fmt.Sprintf("%s:%d", c.RabbitMQ.Host, c.RabbitMQ.Port),
+		Path:   "/",
+	}
+	if c.RabbitMQ.VHost != "/" {
+		u.Path = "/" + c.RabbitMQ.VHost
+	}
+	return u.String()
+}
+
 func getEnv(key, def string) string {
 	if v, ok := os.LookupEnv(key); ok && strings.TrimSpace(v) != "" {
 		return v
@@ -210,6 +714,15 @@ func getEnvAsInt(key string, def int) int {
 	return def
 }
 
+func getEnvAsBool(key string, def bool) bool {
+	if v, ok := os.LookupEnv(key); ok {
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
 func getEnvAsDurationMs(key string, defMs int) time.Duration {
 	ms := getEnvAsInt(key, defMs)
 	return time.Duration(ms) * time.Millisecond
@@ -245,3 +758,19 @@ func getEnvAsCSV(key string, def []string) []string {
 	}
 	return out
 }
+
+func validateMigrations(c *Config) []string {
+	var errs []string
+
+	switch c.Migrations.OnDrift {
+	case "fail", "warn":
+		// ok
+	default:
+		errs = append(errs, fmt.Sprintf("MIGRATIONS_ON_DRIFT must be one of fail|warn, got %q", c.Migrations.OnDrift))
+	}
+	if c.Migrations.LockTimeout < time.Second {
+		errs = append(errs, fmt.Sprintf("MIGRATIONS_LOCK_TIMEOUT must be >= 1s, got %s", c.Migrations.LockTimeout))
+	}
+
+	return errs
+}
@@ -45,3 +45,44 @@ func TestLoad_InvalidPort_ShouldError(t *testing.T) {
 		}
 	})
 }
+
+func TestLoad_InvalidDatabasePool_ShouldError(t *testing.T) {
+	withEnv("MYSQL_MAX_IDLE_CONNS", "50", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error when MYSQL_MAX_IDLE_CONNS exceeds MYSQL_MAX_OPEN_CONNS")
+		}
+	})
+	withEnv("MYSQL_READ_TIMEOUT", "30s", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error when MYSQL_READ_TIMEOUT is shorter than MIGRATIONS_LOCK_TIMEOUT")
+		}
+	})
+}
+
+func TestLoad_WeightShipping_ShouldError(t *testing.T) {
+	withEnv("SHIPPING_MODE", "weight", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for SHIPPING_MODE=weight while products carry no weight")
+		}
+	})
+}
+
+func TestLoad_AdminAPIInProduction_ShouldError(t *testing.T) {
+	withEnv("JWT_SECRET", "a-secure-secret", func() {
+		withEnv("APP_ENV", "prod", func() {
+			if _, err := Load(); err != nil {
+				t.Fatalf("expected production config without admin api to load, got %v", err)
+			}
+			withEnv("ADMIN_API_ENABLED", "true", func() {
+				if _, err := Load(); err == nil {
+					t.Fatalf("expected error when ADMIN_API_ENABLED is true in production")
+				}
+			})
+		})
+	})
+	withEnv("ADMIN_API_ENABLED", "true", func() {
+		if _, err := Load(); err != nil {
+			t.Fatalf("expected admin api to be allowed outside production, got %v", err)
+		}
+	})
+}
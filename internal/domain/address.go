@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// Address 表示用户收货地址，每个用户最多一个默认地址
+type Address struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"user_id"`
+	ReceiverName string    `json:"receiver_name"`
+	Phone        string    `json:"phone"`
+	Province     string    `json:"province"`
+	City         string    `json:"city"`
+	District     string    `json:"district"`
+	Detail       string    `json:"detail"`
+	PostalCode   string    `json:"postal_code"`
+	IsDefault    bool      `json:"is_default"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Snapshot 生成下单时的地址快照，之后修改或删除地址不影响已有订单
+func (a *Address) Snapshot() *OrderAddress {
+	return &OrderAddress{
+		ReceiverName: a.ReceiverName,
+		Phone:        a.Phone,
+		Province:     a.Province,
+		City:         a.City,
+		District:     a.District,
+		Detail:       a.Detail,
+		PostalCode:   a.PostalCode,
+	}
+}
+
+// OrderAddress 表示订单上的收货地址快照
+type OrderAddress struct {
+	ReceiverName string `json:"receiver_name"`
+	Phone        string `json:"phone"`
+	Province     string `json:"province"`
+	City         string `json:"city"`
+	District     string `json:"district"`
+	Detail       string `json:"detail"`
+	PostalCode   string `json:"postal_code"`
+}
+
+// AddressRequest 创建或修改收货地址请求（修改时整体覆盖）
+type AddressRequest struct {
+	ReceiverName string `json:"receiver_name"`
+	Phone        string `json:"phone"`
+	Province     string `json:"province"`
+	City         string `json:"city"`
+	District     string `json:"district"`
+	Detail       string `json:"detail"`
+	PostalCode   string `json:"postal_code"`
+	IsDefault    bool   `json:"is_default"`
+}
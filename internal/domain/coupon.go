@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// CouponType 定义优惠券类型
+type CouponType string
+
+const (
+	CouponTypeFixedAmount  CouponType = "fixed_amount"  // 满减：减免固定金额
+	CouponTypePercentage   CouponType = "percentage"    // 折扣：按适用商品小计的百分比减免
+	CouponTypeFreeShipping CouponType = "free_shipping" // 免运费
+)
+
+// CouponStatus 定义优惠券状态
+type CouponStatus string
+
+const (
+	CouponStatusActive   CouponStatus = "active"   // 可用
+	CouponStatusDisabled CouponStatus = "disabled" // 已停用
+)
+
+// Coupon 表示优惠券，金额统一以“分”为单位
+// ProductIDs/CategoryIDs 限定适用范围：均为空表示全场适用，否则商品命中任一列表即适用
+// （目前没有商品类目数据，创建时拒绝 CategoryIDs）；
+// MinSpend 与折扣金额均按适用商品的小计计算
+type Coupon struct {
+	ID   int64      `json:"id"`
+	Code string     `json:"code"`
+	Name string     `json:"name"`
+	Type CouponType `json:"type"`
+	// Value 满减券为减免金额（分），折扣券为减免百分比（1-100），免运费券忽略
+	Value int64 `json:"value"`
+	// MaxDiscount 折扣券单笔优惠上限（分），0 表示不限
+	MaxDiscount int64   `json:"max_discount"`
+	MinSpend    int64   `json:"min_spend"`
+	ProductIDs  []int64 `json:"product_ids,omitempty"`
+	CategoryIDs []int64 `json:"category_ids,omitempty"`
+	// TotalLimit/PerUserLimit 总可用次数与每用户可用次数，0 表示不限
+	TotalLimit   int          `json:"total_limit"`
+	PerUserLimit int          `json:"per_user_limit"`
+	UsedCount    int          `json:"used_count"`
+	StartAt      time.Time    `json:"start_at"`
+	EndAt        time.Time    `json:"end_at"`
+	Status       CouponStatus `json:"status"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// IsValidAt 判断优惠券在 now 时刻是否处于可用状态且在有效期内
+func (c *Coupon) IsValidAt(now time.Time) bool {
+	return c.Status == CouponStatusActive && !now.Before(c.StartAt) && now.Before(c.EndAt)
+}
+
+// AppliesTo 判断商品行是否在优惠券适用范围内
+func (c *Coupon) AppliesTo(item *PricingItem) bool {
+	if len(c.ProductIDs) == 0 && len(c.CategoryIDs) == 0 {
+		return true
+	}
+	return slices.Contains(c.ProductIDs, item.ProductID) ||
+		(item.CategoryID > 0 && slices.Contains(c.CategoryIDs, item.CategoryID))
+}
+
+// EligibleSubtotal 计算适用商品的小计
+func (c *Coupon) EligibleSubtotal(items []*PricingItem) int64 {
+	var subtotal int64
+	for _, item := range items {
+		if c.AppliesTo(item) {
+			subtotal += item.Amount()
+		}
+	}
+	return subtotal
+}
+
+// Discount 计算优惠金额，不校验有效期、门槛与使用次数；结果不超过可减免的金额
+func (c *Coupon) Discount(items []*PricingItem, shippingFee int64) int64 {
+	switch c.Type {
+	case CouponTypeFixedAmount:
+		return min(c.Value, c.EligibleSubtotal(items))
+	case CouponTypePercentage:
+		discount := c.EligibleSubtotal(items) * c.Value / 100
+		if c.MaxDiscount > 0 {
+			discount = min(discount, c.MaxDiscount)
+		}
+		return discount
+	case CouponTypeFreeShipping:
+		return shippingFee
+	default:
+		return 0
+	}
+}
+
+// CreateCouponRequest 创建优惠券请求
+type CreateCouponRequest struct {
+	Code         string     `json:"code"`
+	Name         string     `json:"name"`
+	Type         CouponType `json:"type"`
+	Value        int64      `json:"value"`
+	MaxDiscount  int64      `json:"max_discount"`
+	MinSpend     int64      `json:"min_spend"`
+	ProductIDs   []int64    `json:"product_ids"`
+	CategoryIDs  []int64    `json:"category_ids"`
+	TotalLimit   int        `json:"total_limit"`
+	PerUserLimit int        `json:"per_user_limit"`
+	StartAt      time.Time  `json:"start_at"`
+	EndAt        time.Time  `json:"end_at"`
+}
+
+// CouponFilter 管理端优惠券列表过滤与分页条件
+type CouponFilter struct {
+	Status   CouponStatus
+	Page     int
+	PageSize int
+}
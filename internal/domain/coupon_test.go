@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCoupon_Discount(t *testing.T) {
+	items := []*PricingItem{
+		{ProductID: 1, CategoryID: 10, Quantity: 2, UnitPrice: 5000},
+		{ProductID: 2, CategoryID: 20, Quantity: 1, UnitPrice: 3000},
+	}
+
+	cases := []struct {
+		name   string
+		coupon Coupon
+		want   int64
+	}{
+		{"fixed amount", Coupon{Type: CouponTypeFixedAmount, Value: 1000}, 1000},
+		{"fixed amount capped by eligible subtotal", Coupon{Type: CouponTypeFixedAmount, Value: 5000, ProductIDs: []int64{2}}, 3000},
+		{"percentage", Coupon{Type: CouponTypePercentage, Value: 10}, 1300},
+		{"percentage with max discount", Coupon{Type: CouponTypePercentage, Value: 50, MaxDiscount: 2000}, 2000},
+		{"percentage scoped by category", Coupon{Type: CouponTypePercentage, Value: 10, CategoryIDs: []int64{10}}, 1000},
+		{"free shipping", Coupon{Type: CouponTypeFreeShipping}, 800},
+		{"out of scope", Coupon{Type: CouponTypeFixedAmount, Value: 1000, ProductIDs: []int64{3}}, 0},
+	}
+
+	for _, tc := range cases {
+		if got := tc.coupon.Discount(items, 800); got != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestCoupon_IsValidAt(t *testing.T) {
+	start := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	c := &Coupon{Status: CouponStatusActive, StartAt: start, EndAt: start.Add(24 * time.Hour)}
+
+	if c.IsValidAt(start.Add(-time.Second)) || !c.IsValidAt(start) || c.IsValidAt(c.EndAt) {
+		t.Fatalf("unexpected validity window")
+	}
+
+	c.Status = CouponStatusDisabled
+	if c.IsValidAt(start.Add(time.Hour)) {
+		t.Fatalf("disabled coupon should not be valid")
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// DeadLetterStatus 表示死信的处理状态
+type DeadLetterStatus string
+
+const (
+	DeadLetterStatusDead      DeadLetterStatus = "dead"      // 待处理
+	DeadLetterStatusReplayed  DeadLetterStatus = "replayed"  // 已重新投递
+	DeadLetterStatusDiscarded DeadLetterStatus = "discarded" // 已丢弃
+)
+
+// DeadLetter 表示一条重试耗尽的消息
+type DeadLetter struct {
+	ID        int64             `json:"id"`
+	Topic     string            `json:"topic"`
+	MessageID string            `json:"message_id"`
+	Payload   []byte            `json:"-"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Attempts  int               `json:"attempts"`
+	LastError string            `json:"last_error"`
+	Status    DeadLetterStatus  `json:"status"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// DeadLetterFilter 死信分页查询条件
+type DeadLetterFilter struct {
+	Topic    string
+	Status   DeadLetterStatus
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,88 @@
+package domain
+
+import "time"
+
+// OrderStatus 定义订单状态
+type OrderStatus string
+
+const (
+	OrderStatusPendingPayment OrderStatus = "pending_payment" // 待支付
+	OrderStatusPaid           OrderStatus = "paid"            // 已支付
+	OrderStatusCancelled      OrderStatus = "cancelled"       // 已取消
+)
+
+// Order 表示订单，金额统一以“分”为单位
+// TotalAmount = SubtotalAmount + ShippingFee - DiscountAmount
+type Order struct {
+	ID             int64            `json:"id"`
+	UserID         int64            `json:"user_id"`
+	Status         OrderStatus      `json:"status"`
+	SubtotalAmount int64            `json:"subtotal_amount"`
+	ShippingFee    int64            `json:"shipping_fee"`
+	DiscountAmount int64            `json:"discount_amount"`
+	TotalAmount    int64            `json:"total_amount"`
+	Items          []*OrderItem     `json:"items,omitempty"`
+	Discounts      []*OrderDiscount `json:"discounts,omitempty"`
+	Address        *OrderAddress    `json:"address,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// ApplyPricing 将计价结果写入订单金额与优惠明细
+func (o *Order) ApplyPricing(p *OrderPricing) {
+	o.SubtotalAmount = p.SubtotalAmount
+	o.ShippingFee = p.ShippingFee
+	o.DiscountAmount = p.DiscountAmount
+	o.TotalAmount = p.TotalAmount
+	o.Discounts = p.Discounts
+}
+
+// OrderItem 表示订单行，Price 为下单时的成交单价快照
+type OrderItem struct {
+	ID        int64 `json:"id"`
+	OrderID   int64 `json:"order_id"`
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+	Price     int64 `json:"price"`
+}
+
+// OrderDiscount 表示订单的一条优惠明细，券码与类型为下单时的快照
+type OrderDiscount struct {
+	CouponID    int64      `json:"coupon_id"`
+	CouponCode  string     `json:"coupon_code"`
+	Type        CouponType `json:"type"`
+	Amount      int64      `json:"amount"`
+	Description string     `json:"description"`
+}
+
+// PricingItem 表示参与计价的商品行，单价由服务端确定（如秒杀价）
+type PricingItem struct {
+	ProductID int64 `json:"product_id"`
+	// CategoryID 商品类目，0 表示未知（不匹配按类目限定的优惠券）
+	CategoryID int64 `json:"category_id,omitempty"`
+	Quantity   int   `json:"quantity"`
+	UnitPrice  int64 `json:"unit_price"`
+	// UnitWeight 单件重量（克），0 表示未知（按重量计费时只收首重）；商品尚无重量数据，配置层暂不开放按重量计费
+	UnitWeight int64 `json:"unit_weight,omitempty"`
+}
+
+// Amount 计算商品行金额
+func (i *PricingItem) Amount() int64 {
+	return i.UnitPrice * int64(i.Quantity)
+}
+
+// PriceOrderRequest 订单计价请求，运费由服务端按收货地址与商品计算
+type PriceOrderRequest struct {
+	Items       []*PricingItem
+	Address     *OrderAddress
+	CouponCodes []string
+}
+
+// OrderPricing 订单计价结果，Discounts 为逐张优惠券的优惠明细
+type OrderPricing struct {
+	SubtotalAmount int64            `json:"subtotal_amount"`
+	ShippingFee    int64            `json:"shipping_fee"`
+	DiscountAmount int64            `json:"discount_amount"`
+	TotalAmount    int64            `json:"total_amount"`
+	Discounts      []*OrderDiscount `json:"discounts"`
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// 领域事件主题，由 outbox relay 投递到消息队列
+const (
+	TopicOrderCreated            = "order.created"
+	TopicSpikeEventStatusChanged = "spike.event.status_changed"
+)
+
+// OutboxStatus 表示本地消息的投递状态
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending" // 待投递
+	OutboxStatusSent    OutboxStatus = "sent"    // 已投递
+	OutboxStatusFailed  OutboxStatus = "failed"  // 超过最大重试次数，放弃投递
+)
+
+// OutboxMessage 表示一条本地消息表记录
+type OutboxMessage struct {
+	ID            int64        `json:"id"`
+	Topic         string       `json:"topic"`
+	MessageID     string       `json:"message_id"`
+	Payload       []byte       `json:"payload"`
+	Status        OutboxStatus `json:"status"`
+	Attempts      int          `json:"attempts"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	LastError     string       `json:"last_error"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// OrderCreatedEvent 订单创建事件载荷
+type OrderCreatedEvent struct {
+	OrderID      int64     `json:"order_id"`
+	UserID       int64     `json:"user_id"`
+	TotalAmount  int64     `json:"total_amount"`
+	SpikeEventID int64     `json:"spike_event_id,omitempty"`
+	Ticket       string    `json:"ticket,omitempty"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+// SpikeEventStatusChangedEvent 秒杀活动状态变更事件载荷
+type SpikeEventStatusChangedEvent struct {
+	EventID    int64            `json:"event_id"`
+	Status     SpikeEventStatus `json:"status"`
+	OccurredAt time.Time        `json:"occurred_at"`
+}
@@ -0,0 +1,211 @@
+package domain
+
+import "time"
+
+// SpikeEventStatus 表示秒杀活动的管理状态（由管理员操作驱动）
+type SpikeEventStatus string
+
+const (
+	SpikeEventStatusDraft     SpikeEventStatus = "draft"     // 草稿，尚未发布
+	SpikeEventStatusScheduled SpikeEventStatus = "scheduled" // 已排期，到 start_at 自动开始
+	SpikeEventStatusClosed    SpikeEventStatus = "closed"    // 已关闭（手动关闭）
+)
+
+// SpikeEventPhase 表示秒杀活动在某一时刻的对外阶段（由状态与时间窗口推导）
+type SpikeEventPhase string
+
+const (
+	SpikeEventPhaseDraft    SpikeEventPhase = "draft"
+	SpikeEventPhaseUpcoming SpikeEventPhase = "upcoming" // 已排期，尚未开始
+	SpikeEventPhaseLive     SpikeEventPhase = "live"     // 进行中
+	SpikeEventPhaseEnded    SpikeEventPhase = "ended"    // 已过 end_at
+	SpikeEventPhaseClosed   SpikeEventPhase = "closed"
+)
+
+// SpikeEvent 表示一次秒杀活动
+// 价格统一以“分”为单位存储，避免浮点误差
+type SpikeEvent struct {
+	ID           int64            `json:"id"`
+	ProductID    int64            `json:"product_id"`
+	Title        string           `json:"title"`
+	SpikePrice   int64            `json:"spike_price"`
+	TotalStock   int              `json:"total_stock"`    // 活动分配的库存
+	PerUserLimit int              `json:"per_user_limit"` // 每个用户最多可购买的件数
+	StartAt      time.Time        `json:"start_at"`
+	EndAt        time.Time        `json:"end_at"`
+	Status       SpikeEventStatus `json:"status"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// Phase 根据管理状态与时间窗口推导活动在 now 时刻所处的阶段
+func (e *SpikeEvent) Phase(now time.Time) SpikeEventPhase {
+	switch e.Status {
+	case SpikeEventStatusDraft:
+		return SpikeEventPhaseDraft
+	case SpikeEventStatusClosed:
+		return SpikeEventPhaseClosed
+	}
+
+	switch {
+	case now.Before(e.StartAt):
+		return SpikeEventPhaseUpcoming
+	case now.Before(e.EndAt):
+		return SpikeEventPhaseLive
+	default:
+		return SpikeEventPhaseEnded
+	}
+}
+
+// IsLive 判断活动在 now 时刻是否可以下单
+func (e *SpikeEvent) IsLive(now time.Time) bool {
+	return e.Phase(now) == SpikeEventPhaseLive
+}
+
+// CreateSpikeEventRequest 创建秒杀活动请求
+type CreateSpikeEventRequest struct {
+	ProductID    int64     `json:"product_id"`
+	Title        string    `json:"title"`
+	SpikePrice   int64     `json:"spike_price"`
+	TotalStock   int       `json:"total_stock"`
+	PerUserLimit int       `json:"per_user_limit"`
+	StartAt      time.Time `json:"start_at"`
+	EndAt        time.Time `json:"end_at"`
+}
+
+// UpdateSpikeEventRequest 编辑秒杀活动请求，仅更新非 nil 字段
+type UpdateSpikeEventRequest struct {
+	Title        *string    `json:"title"`
+	SpikePrice   *int64     `json:"spike_price"`
+	TotalStock   *int       `json:"total_stock"`
+	PerUserLimit *int       `json:"per_user_limit"`
+	StartAt      *time.Time `json:"start_at"`
+	EndAt        *time.Time `json:"end_at"`
+}
+
+// SpikeEventFilter 管理端活动列表过滤与分页条件
+type SpikeEventFilter struct {
+	Status   SpikeEventStatus
+	Page     int
+	PageSize int
+}
+
+// SpikeOrder 表示秒杀订单记录，关联活动、用户与实际订单
+// Ticket 为下单请求受理时发放的排队凭证，同时作为异步落库的幂等键
+type SpikeOrder struct {
+	ID           int64     `json:"id"`
+	SpikeEventID int64     `json:"spike_event_id"`
+	UserID       int64     `json:"user_id"`
+	OrderID      int64     `json:"order_id"`
+	Ticket       string    `json:"ticket"`
+	Quantity     int       `json:"quantity"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SpikeOrderMessage 是秒杀下单消息的载荷（JSON），由秒杀接口预减库存成功后投递，
+// 由下单消费者异步创建订单
+type SpikeOrderMessage struct {
+	Ticket     string `json:"ticket"`
+	EventID    int64  `json:"event_id"`
+	ProductID  int64  `json:"product_id"`
+	UserID     int64  `json:"user_id"`
+	Quantity   int    `json:"quantity"`
+	SpikePrice int64  `json:"spike_price"`
+	// CouponCodes 下单时使用的优惠券，由消费者在落库时计价并核销
+	CouponCodes []string `json:"coupon_codes,omitempty"`
+	// Address 受理时解析的收货地址快照，落库时写入订单
+	Address  *OrderAddress `json:"address,omitempty"`
+	QueuedAt time.Time     `json:"queued_at"`
+}
+
+// SpikePurchaseRequest 秒杀下单请求
+type SpikePurchaseRequest struct {
+	Quantity    int      `json:"quantity"`
+	CouponCodes []string `json:"coupon_codes"`
+	// AddressID 收货地址，为 0 时使用默认地址；收货地址必填，用户没有任何地址时下单返回 400
+	AddressID int64 `json:"address_id"`
+}
+
+// SpikeTicketStatus 表示秒杀排队凭证的处理状态
+type SpikeTicketStatus string
+
+const (
+	SpikeTicketStatusQueued    SpikeTicketStatus = "queued"    // 已受理，等待异步下单
+	SpikeTicketStatusSucceeded SpikeTicketStatus = "succeeded" // 下单成功
+	SpikeTicketStatusFailed    SpikeTicketStatus = "failed"    // 下单失败
+	SpikeTicketStatusSoldOut   SpikeTicketStatus = "sold_out"  // 落库时发现库存不足
+)
+
+// IsFinal 判断状态是否为终态
+func (s SpikeTicketStatus) IsFinal() bool {
+	return s != SpikeTicketStatusQueued
+}
+
+// SpikeTicket 是秒杀下单受理后返回给客户端的排队凭证
+type SpikeTicket struct {
+	Ticket  string            `json:"ticket"`
+	EventID int64             `json:"event_id"`
+	Status  SpikeTicketStatus `json:"status"`
+}
+
+// SpikeOrderResult 记录排队凭证的异步处理结果，供客户端轮询或订阅
+type SpikeOrderResult struct {
+	Ticket  string            `json:"ticket"`
+	EventID int64             `json:"event_id"`
+	UserID  int64             `json:"user_id"`
+	Status  SpikeTicketStatus `json:"status"`
+	OrderID int64             `json:"order_id,omitempty"`
+	Reason  string            `json:"reason,omitempty"`
+	// Pricing 下单成功时的价格明细（含优惠）
+	Pricing   *OrderPricing `json:"pricing,omitempty"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// SpikeStockDrift 记录一次库存对账发现的偏差：Drift = Cached - Expected
+// Expected 为活动库存减去已落库售出件数；Drift < 0 可能来自尚未落库的在途订单
+type SpikeStockDrift struct {
+	EventID  int64 `json:"event_id"`
+	Expected int64 `json:"expected"`
+	Cached   int64 `json:"cached"`
+	Drift    int64 `json:"drift"`
+	Fixed    bool  `json:"fixed"`
+}
+
+// WaitingRoomStatus 表示用户在秒杀排队室中的状态
+type WaitingRoomStatus struct {
+	EventID int64 `json:"event_id"`
+	// Token 排队凭证，查询排队状态与下单时通过 X-Queue-Token 请求头携带
+	Token    string `json:"token"`
+	Position int64  `json:"position"`
+	// Ahead 排在前面且尚未放行的人数
+	Ahead int64 `json:"ahead"`
+	// ETASeconds 预计放行等待秒数（活动未开始时包含距开始的时间）
+	ETASeconds int64 `json:"eta_seconds"`
+	Admitted   bool  `json:"admitted"`
+}
+
+// SpikeChallenge 秒杀防刷工作量证明挑战：客户端需找到 solution，
+// 使 SHA-256(challenge + solution) 的前 Difficulty 个比特为 0
+type SpikeChallenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// SolveSpikeChallengeRequest 提交挑战答案的请求
+type SolveSpikeChallengeRequest struct {
+	Challenge string `json:"challenge"`
+	Solution  string `json:"solution"`
+}
+
+// SpikePurchaseToken 一次性秒杀下单凭证，下单时通过 X-Spike-Token 请求头携带
+type SpikePurchaseToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SpikePurchasePath 活动开始后下发的隐藏下单地址
+type SpikePurchasePath struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+}
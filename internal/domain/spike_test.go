@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpikeEvent_Phase(t *testing.T) {
+	start := time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	cases := []struct {
+		name   string
+		status SpikeEventStatus
+		now    time.Time
+		want   SpikeEventPhase
+	}{
+		{"draft ignores time", SpikeEventStatusDraft, start.Add(time.Minute), SpikeEventPhaseDraft},
+		{"closed ignores time", SpikeEventStatusClosed, start.Add(time.Minute), SpikeEventPhaseClosed},
+		{"before start", SpikeEventStatusScheduled, start.Add(-time.Second), SpikeEventPhaseUpcoming},
+		{"at start", SpikeEventStatusScheduled, start, SpikeEventPhaseLive},
+		{"at end", SpikeEventStatusScheduled, end, SpikeEventPhaseEnded},
+	}
+
+	for _, tc := range cases {
+		e := &SpikeEvent{Status: tc.status, StartAt: start, EndAt: end}
+		if got := e.Phase(tc.now); got != tc.want {
+			t.Fatalf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package idempotency 提供幂等键存储：首次请求占位，处理完成后保存响应供重试时重放。
+// 进程内实现仅对单实例生效，多实例部署请使用 Redis 实现。
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record 表示一个幂等键对应的请求与响应
+type Record struct {
+	// Fingerprint 请求指纹（方法、路径与请求体的摘要），同键不同请求据此拒绝
+	Fingerprint string `json:"fingerprint"`
+	// Completed 为 false 表示首次请求仍在处理中
+	Completed bool `json:"completed"`
+	// Status 首次请求的响应状态码
+	Status int `json:"status,omitempty"`
+	// Header 需要重放的响应头
+	Header http.Header `json:"header,omitempty"`
+	// Body 首次请求的响应体
+	Body []byte `json:"body,omitempty"`
+}
+
+// Store 定义幂等键存储接口
+type Store interface {
+	// Begin 为 key 占位（记录指纹，状态为处理中），占位在 lockTTL 后自动失效。
+	// 返回 nil 表示占位成功；键已存在时返回已有记录且不做修改。
+	Begin(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, error)
+	// Complete 保存已完成的响应，保留 ttl
+	Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error
+	// Release 删除占位，允许使用同一个键重试（用于处理失败的请求）
+	Release(ctx context.Context, key string) error
+}
+
+// sweepInterval 进程内实现清理过期键的间隔
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	rec       Record
+	expiresAt time.Time
+}
+
+// memoryStore 是 Store 的进程内实现，过期键在写入时顺带清理
+type memoryStore struct {
+	mu        sync.Mutex
+	items     map[string]*memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore 创建进程内幂等键存储
+func NewMemoryStore() Store {
+	return &memoryStore{items: make(map[string]*memoryEntry), now: time.Now}
+}
+
+// Begin 占位
+func (s *memoryStore) Begin(_ context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, e := range s.items {
+			if now.After(e.expiresAt) {
+				delete(s.items, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if e, ok := s.items[key]; ok && !now.After(e.expiresAt) {
+		rec := e.rec
+		return &rec, nil
+	}
+	s.items[key] = &memoryEntry{rec: Record{Fingerprint: fingerprint}, expiresAt: now.Add(lockTTL)}
+	return nil, nil
+}
+
+// Complete 保存响应
+func (s *memoryStore) Complete(_ context.Context, key string, rec *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[key] = &memoryEntry{rec: *rec, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+// Release 删除占位
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+	return nil
+}
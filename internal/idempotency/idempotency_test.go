@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_BeginCompleteRelease(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	s := NewMemoryStore().(*memoryStore)
+	s.now = func() time.Time { return now }
+
+	if rec, err := s.Begin(ctx, "k", "fp", time.Minute); err != nil || rec != nil {
+		t.Fatalf("expected first begin to reserve key, got rec=%v err=%v", rec, err)
+	}
+
+	rec, _ := s.Begin(ctx, "k", "other", time.Minute)
+	if rec == nil || rec.Completed || rec.Fingerprint != "fp" {
+		t.Fatalf("expected in-progress record with original fingerprint, got %+v", rec)
+	}
+
+	_ = s.Complete(ctx, "k", &Record{Fingerprint: "fp", Completed: true, Status: 201, Body: []byte("ok")}, time.Hour)
+	rec, _ = s.Begin(ctx, "k", "fp", time.Minute)
+	if rec == nil || !rec.Completed || rec.Status != 201 || string(rec.Body) != "ok" {
+		t.Fatalf("expected completed record, got %+v", rec)
+	}
+
+	_ = s.Release(ctx, "k")
+	if rec, _ := s.Begin(ctx, "k", "fp", time.Minute); rec != nil {
+		t.Fatalf("expected key to be free after release, got %+v", rec)
+	}
+}
+
+func TestMemoryStore_LockExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	s := NewMemoryStore().(*memoryStore)
+	s.now = func() time.Time { return now }
+
+	_, _ = s.Begin(ctx, "k", "fp", time.Minute)
+	now = now.Add(2 * time.Minute)
+	if rec, _ := s.Begin(ctx, "k", "fp", time.Minute); rec != nil {
+		t.Fatalf("expected expired lock to be reclaimed, got %+v", rec)
+	}
+}
@@ -0,0 +1,76 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore 是 Store 的 Redis 实现，每个键保存一条 JSON 记录
+type redisStore struct {
+	rdb redis.UniversalClient
+}
+
+// NewRedisStore 创建基于 Redis 的幂等键存储
+func NewRedisStore(rdb redis.UniversalClient) Store {
+	return &redisStore{rdb: rdb}
+}
+
+// Begin 使用 SET NX 占位，失败时读取已有记录
+func (s *redisStore) Begin(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, error) {
+	data, err := json.Marshal(Record{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, fmt.Errorf("marshal idempotency record: %w", err)
+	}
+
+	ok, err := s.rdb.SetNX(ctx, redisKey(key), data, lockTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("begin idempotency key: %w", err)
+	}
+	if ok {
+		return nil, nil
+	}
+
+	raw, err := s.rdb.Get(ctx, redisKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// 占位在两次调用之间过期，按处理中处理，由客户端稍后重试
+		return &Record{Fingerprint: fingerprint}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	var rec Record
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("unmarshal idempotency record: %w", err)
+	}
+	return &rec, nil
+}
+
+// Complete 保存响应
+func (s *redisStore) Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal idempotency record: %w", err)
+	}
+	if err := s.rdb.Set(ctx, redisKey(key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release 删除占位
+func (s *redisStore) Release(ctx context.Context, key string) error {
+	if err := s.rdb.Del(ctx, redisKey(key)).Err(); err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func redisKey(key string) string {
+	return "idempotency:" + key
+}
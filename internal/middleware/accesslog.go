@@ -36,3 +36,8 @@ func (rw *responseWrite) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap 暴露底层 ResponseWriter，使 http.ResponseController 可以找到 Flush 等能力（SSE 需要）
+func (rw *responseWrite) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
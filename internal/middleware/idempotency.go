@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/danta7/go_mall/internal/idempotency"
+	"github.com/danta7/go_mall/internal/resp"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderIdempotencyKey 客户端生成的幂等键，同一操作的重试须携带相同的值
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed 重放响应时附带的标记头
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+	// maxIdempotentBodySize 计算请求指纹时允许读取的最大请求体
+	maxIdempotentBodySize = 1 << 20
+)
+
+// IdempotencyConfig 幂等中间件配置
+type IdempotencyConfig struct {
+	// TTL 已完成响应的保留时长，期间相同幂等键的重试直接重放
+	TTL time.Duration
+	// LockTTL 处理中占位的有效期，应大于请求超时，进程崩溃后占位到期自动释放
+	LockTTL time.Duration
+	// Scope 幂等键的作用域（如路由 + 用户），避免不同用户或接口间的键冲突
+	Scope KeyFunc
+}
+
+// Idempotency 为携带 Idempotency-Key 请求头的 POST 请求提供幂等保证：
+//   - 首次请求正常处理，响应（状态码、Content-Type、Retry-After 与响应体）保存 TTL 时长；
+//   - 相同键且相同请求体的重试直接重放首次响应，并设置 Idempotent-Replayed: true；
+//   - 相同键但请求体不同返回 422，首次请求仍在处理中返回 409；
+//   - 首次请求返回 5xx、403、429 或 panic 时释放占位，允许客户端使用同一个键重试。
+//     403/429 多为排队未放行、防刷校验失败或限流等暂时性拒绝，保存后放行的重试也会一直收到旧的拒绝。
+//
+// 未携带请求头的请求不受影响。存储出错时放行请求并记录日志，与限流中间件一致。
+// 需包裹单个路由的处理器使用（作用域依赖 r.Pattern）。
+func Idempotency(store idempotency.Store, cfg IdempotencyConfig, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderIdempotencyKey)
+			if key == "" || r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reqID := RequestIDFromContext(r.Context())
+			if len(key) > maxIdempotencyKeyLen {
+				resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "idempotency key too long", reqID, "")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodySize+1))
+			if err != nil {
+				resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "read request body failed", reqID, "")
+				return
+			}
+			if len(body) > maxIdempotentBodySize {
+				resp.Error(w, http.StatusRequestEntityTooLarge, resp.CodeInvalidParam, "request body too large", reqID, "")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := cfg.Scope(r) + "|" + key
+			fingerprint := requestFingerprint(r, body)
+			// 请求超时或客户端断开后仍需保存/释放记录
+			storeCtx := context.WithoutCancel(r.Context())
+
+			rec, err := store.Begin(storeCtx, storeKey, fingerprint, cfg.LockTTL)
+			if err != nil {
+				logger.Warn("idempotency store unavailable, request allowed", zap.String("request_id", reqID), zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if rec != nil {
+				switch {
+				case rec.Fingerprint != fingerprint:
+					resp.Error(w, http.StatusUnprocessableEntity, resp.CodeIdempotencyConflict,
+						"idempotency key reused with a different request", reqID, "")
+				case !rec.Completed:
+					resp.Error(w, http.StatusConflict, resp.CodeIdempotencyConflict,
+						"a request with this idempotency key is in progress", reqID, "")
+				default:
+					for k, v := range rec.Header {
+						w.Header()[k] = v
+					}
+					w.Header().Set(HeaderIdempotentReplayed, "true")
+					w.WriteHeader(rec.Status)
+					_, _ = w.Write(rec.Body)
+				}
+				return
+			}
+
+			rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				// 处理失败或 panic：释放占位，panic 继续交给 Recovery 处理
+				if err := store.Release(storeCtx, storeKey); err != nil {
+					logger.Warn("release idempotency key failed", zap.String("request_id", reqID), zap.Error(err))
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+
+			if !replayable(rw.status) {
+				return
+			}
+			completed = true
+			header := http.Header{}
+			for _, k := range replayedHeaders {
+				if v := rw.Header().Get(k); v != "" {
+					header.Set(k, v)
+				}
+			}
+			if err := store.Complete(storeCtx, storeKey, &idempotency.Record{
+				Fingerprint: fingerprint,
+				Completed:   true,
+				Status:      rw.status,
+				Header:      header,
+				Body:        rw.body.Bytes(),
+			}, cfg.TTL); err != nil {
+				logger.Warn("save idempotent response failed", zap.String("request_id", reqID), zap.Error(err))
+			}
+		})
+	}
+}
+
+// replayedHeaders 随响应一并保存、重放时还原的响应头
+var replayedHeaders = []string{"Content-Type", "Retry-After"}
+
+// replayable 判断响应是否作为最终结果保存：5xx 与暂时性拒绝（403/429）不保存
+func replayable(status int) bool {
+	return status < http.StatusInternalServerError &&
+		status != http.StatusForbidden && status != http.StatusTooManyRequests
+}
+
+// requestFingerprint 计算请求指纹：方法、路径（含查询参数）与请求体的 SHA-256
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter 在写出响应的同时记录状态码与响应体
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap 暴露底层 ResponseWriter
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/internal/idempotency"
+	"go.uber.org/zap"
+)
+
+func newIdempotentHandler(next http.HandlerFunc) http.Handler {
+	return Idempotency(idempotency.NewMemoryStore(), IdempotencyConfig{
+		TTL:     time.Minute,
+		LockTTL: time.Minute,
+		Scope:   func(*http.Request) string { return "test" },
+	}, zap.NewNop())(next)
+}
+
+func postWithKey(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(HeaderIdempotencyKey, key)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysCompletedResponse(t *testing.T) {
+	var calls atomic.Int32
+	h := newIdempotentHandler(func(w http.ResponseWriter, _ *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(int(n)) + `}`))
+	})
+
+	first := postWithKey(h, "k1", `{"a":1}`)
+	second := postWithKey(h, "k1", `{"a":1}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("expected replay of %d %q, got %d %q", first.Code, first.Body, second.Code, second.Body)
+	}
+	if second.Header().Get(HeaderIdempotentReplayed) != "true" || second.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected replay headers: %v", second.Header())
+	}
+}
+
+func TestIdempotency_FingerprintMismatch(t *testing.T) {
+	h := newIdempotentHandler(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	postWithKey(h, "k1", `{"a":1}`)
+	if rec := postWithKey(h, "k1", `{"a":2}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a different body with the same key, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	h := newIdempotentHandler(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postWithKey(h, "k1", `{"a":1}`) }()
+	<-started
+
+	if rec := postWithKey(h, "k1", `{"a":1}`); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while the first request is in progress, got %d", rec.Code)
+	}
+	close(release)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Fatalf("expected first request to complete with 201, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_ReleasesOnServerError(t *testing.T) {
+	var calls atomic.Int32
+	h := newIdempotentHandler(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if rec := postWithKey(h, "k1", `{"a":1}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected first attempt to fail with 500, got %d", rec.Code)
+	}
+	rec := postWithKey(h, "k1", `{"a":1}`)
+	if rec.Code != http.StatusCreated || rec.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Fatalf("expected retry after 5xx to run the handler again, got %d replayed=%q", rec.Code, rec.Header().Get(HeaderIdempotentReplayed))
+	}
+}
+
+func TestIdempotency_ReleasesOnPanic(t *testing.T) {
+	var calls atomic.Int32
+	h := newIdempotentHandler(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("expected panic to propagate to the recovery middleware")
+			}
+		}()
+		postWithKey(h, "k1", `{"a":1}`)
+	}()
+
+	if rec := postWithKey(h, "k1", `{"a":1}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected retry after panic to run the handler again, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_ReleasesOnTransientRejection(t *testing.T) {
+	for _, status := range []int{http.StatusForbidden, http.StatusTooManyRequests} {
+		var calls atomic.Int32
+		h := newIdempotentHandler(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "5")
+				w.WriteHeader(status)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		})
+
+		if rec := postWithKey(h, "k1", `{"a":1}`); rec.Code != status {
+			t.Fatalf("expected first attempt to be rejected with %d, got %d", status, rec.Code)
+		}
+		if rec := postWithKey(h, "k1", `{"a":1}`); rec.Code != http.StatusCreated || rec.Header().Get(HeaderIdempotentReplayed) != "" {
+			t.Fatalf("expected retry after %d to run the handler again, got %d", status, rec.Code)
+		}
+	}
+}
+
+func TestIdempotency_ReplaysRetryAfter(t *testing.T) {
+	h := newIdempotentHandler(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	postWithKey(h, "k1", `{"a":1}`)
+	rec := postWithKey(h, "k1", `{"a":1}`)
+	if rec.Header().Get(HeaderIdempotentReplayed) != "true" || rec.Header().Get("Retry-After") != "3" {
+		t.Fatalf("expected Retry-After to be replayed, got %v", rec.Header())
+	}
+}
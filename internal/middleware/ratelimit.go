@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danta7/go_mall/internal/ratelimit"
+	"github.com/danta7/go_mall/internal/resp"
+	"go.uber.org/zap"
+)
+
+// KeyFunc 从请求中提取限流键，返回空字符串表示该请求不受此规则限制
+type KeyFunc func(r *http.Request) string
+
+// KeyGlobal 所有请求共享同一个键（全局限流）
+func KeyGlobal(*http.Request) string { return "global" }
+
+// KeyByIP 按客户端 IP 限流；trustProxy 为 true 时优先使用 X-Forwarded-For 的第一个地址，
+// 仅应在服务部署于可信反向代理之后时开启，否则客户端可伪造该请求头绕过限流
+func KeyByIP(trustProxy bool) KeyFunc {
+	return func(r *http.Request) string {
+		return "ip:" + ClientIP(r, trustProxy)
+	}
+}
+
+// KeyByUser 按用户限流；userKey 返回空字符串（未登录）时退化为按 IP 限流
+func KeyByUser(userKey func(r *http.Request) string, trustProxy bool) KeyFunc {
+	return func(r *http.Request) string {
+		if u := userKey(r); u != "" {
+			return "user:" + u
+		}
+		return "ip:" + ClientIP(r, trustProxy)
+	}
+}
+
+// KeyByRoute 按路由模式限流（需在 ServeMux 路由之后使用，即包裹单个路由的处理器）
+func KeyByRoute(r *http.Request) string {
+	if r.Pattern != "" {
+		return "route:" + r.Pattern
+	}
+	return "route:" + r.Method + " " + r.URL.Path
+}
+
+// KeyJoin 组合多个键，例如按路由 + 用户限流；任一键为空时整体为空
+func KeyJoin(fns ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		parts := make([]string, 0, len(fns))
+		for _, fn := range fns {
+			k := fn(r)
+			if k == "" {
+				return ""
+			}
+			parts = append(parts, k)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// ClientIP 返回客户端 IP
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimit 按 key 对请求限流，超出配额时返回 429 并设置 Retry-After（秒）。
+// 限流器出错（如 Redis 不可用）时放行请求并记录日志，避免限流组件故障导致整体不可用。
+func RateLimit(limiter ratelimit.Limiter, key KeyFunc, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := limiter.Allow(r.Context(), k)
+			if err != nil {
+				logger.Warn("rate limiter unavailable, request allowed",
+					zap.String("request_id", RequestIDFromContext(r.Context())),
+					zap.Error(err),
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+			if !res.Allowed {
+				retry := int64(math.Ceil(res.RetryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.FormatInt(max(retry, 1), 10))
+				reqID := RequestIDFromContext(r.Context())
+				resp.Error(w, resp.HTTPStatusFromCode(resp.CodeRateLimited), resp.CodeRateLimited, "too many requests", reqID, "")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -11,12 +11,34 @@ import (
 // Timeout 为整个请求设置超时时间（基于标准库 http.TimeoutHandler）。
 // 注意：http.TimeoutHandler 到时会自动写入 503；如需统一超时响应，
 // 可在业务处理末尾调用 HandleTimeout 检查上下文错误并写入统一响应。
-func Timeout(d time.Duration) func(handler http.Handler) http.Handler {
+// exempt 返回 true 的请求不经过 TimeoutHandler（如 SSE 流式路由：其响应被缓冲且不支持 Flush），
+// 由处理器自行控制连接时长；exempt 须按服务端路由判断，不能依据客户端可控的请求头。
+func Timeout(d time.Duration, exempt func(r *http.Request) bool) func(handler http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		return http.TimeoutHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		th := http.TimeoutHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// http.TimeoutHandler writes 503 by default; we intercept context error on write
 			next.ServeHTTP(w, r)
 		}), d, "")
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt != nil && exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			th.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ExemptPatterns 返回按 mux 路由模式豁免超时的判断函数：请求匹配到的路由模式在 patterns 中时豁免
+func ExemptPatterns(mux *http.ServeMux, patterns ...string) func(r *http.Request) bool {
+	set := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		set[p] = true
+	}
+	return func(r *http.Request) bool {
+		_, pattern := mux.Handler(r)
+		return set[pattern]
 	}
 }
 
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout_ExemptsOnlyRegisteredPattern(t *testing.T) {
+	const streamPattern = "GET /stream/{id}"
+	slow := func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(streamPattern, slow)
+	mux.HandleFunc("POST /purchase", slow)
+	handler := Timeout(10*time.Millisecond, ExemptPatterns(mux, streamPattern))(mux)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream/1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected stream route to be exempt, got %d", rec.Code)
+	}
+
+	// 客户端声明 Accept: text/event-stream 不能绕过超时
+	req := httptest.NewRequest(http.MethodPost, "/purchase", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected timeout for non-exempt route, got %d", rec.Code)
+	}
+}
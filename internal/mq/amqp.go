@@ -0,0 +1,272 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// amqpBroker 是 Broker 的 RabbitMQ 实现
+//   - 每个主题对应一个同名持久化队列，通过默认交换机按队列名路由；
+//   - 发布开启 publisher confirm，Broker 确认后 Publish 才返回成功；
+//   - 消费采用手动确认，处理失败的消息 Nack；仅 ShouldRequeue 的错误重新入队，其余丢弃（死信已由 WithRetry 记录）；
+//   - 连接或 channel 断开（如 RabbitMQ 重启）后自动重连：Publish 在下次调用时重新建立连接，
+//     Consume 按指数退避重新连接并继续消费，只有 ctx 结束或 Close 后才返回。
+type amqpBroker struct {
+	url      string
+	mu       sync.Mutex // 保护 conn、pubCh、declared 与 closed，amqp.Channel 不支持并发发布
+	conn     *amqp.Connection
+	pubCh    *amqp.Channel
+	declared map[string]bool
+	closed   bool
+	// nextDial 之前不再尝试重连，避免 RabbitMQ 不可用期间每次发布都阻塞在建连上
+	nextDial time.Time
+	prefetch int
+	logger   *zap.Logger
+}
+
+// reconnectBackoff 消费端断线重连的等待策略；BaseDelay 同时是发布端两次建连尝试的最小间隔
+var reconnectBackoff = RetryPolicy{BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}
+
+// errReconnectPending 距上次建连失败未满 reconnectBackoff.BaseDelay，本次不再尝试
+var errReconnectPending = errors.New("amqp: connection lost, reconnect pending")
+
+// NewAMQPBroker 连接 RabbitMQ 并创建 Broker，prefetch 为每个消费者未确认消息的上限
+// 启动时连接失败直接返回错误，运行期间断线由 Broker 自行重连
+func NewAMQPBroker(url string, prefetch int, logger *zap.Logger) (Broker, error) {
+	if prefetch <= 0 {
+		prefetch = 16
+	}
+	b := &amqpBroker{
+		url:      url,
+		declared: make(map[string]bool),
+		prefetch: prefetch,
+		logger:   logger,
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.ensureConnLocked(); err != nil {
+		if b.conn != nil {
+			_ = b.conn.Close()
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// ensureConnLocked 确保连接与发布 channel 可用，已断开时重新建立；调用方须持有 mu
+func (b *amqpBroker) ensureConnLocked() error {
+	if b.closed {
+		return ErrClosed
+	}
+	if b.conn == nil || b.conn.IsClosed() {
+		if time.Now().Before(b.nextDial) {
+			return errReconnectPending
+		}
+		conn, err := amqp.Dial(b.url)
+		if err != nil {
+			b.nextDial = time.Now().Add(reconnectBackoff.BaseDelay)
+			return fmt.Errorf("dial amqp: %w", err)
+		}
+		if b.conn != nil {
+			b.logger.Info("amqp connection re-established")
+		}
+		b.conn, b.pubCh = conn, nil
+	}
+	if b.pubCh == nil || b.pubCh.IsClosed() {
+		ch, err := b.conn.Channel()
+		if err != nil {
+			return fmt.Errorf("open amqp channel: %w", err)
+		}
+		if err := ch.Confirm(false); err != nil {
+			_ = ch.Close()
+			return fmt.Errorf("enable publisher confirm: %w", err)
+		}
+		// 新 channel 上重新声明队列（声明是幂等的）
+		b.pubCh, b.declared = ch, make(map[string]bool)
+	}
+	return nil
+}
+
+// connection 返回可用的连接，断开时重新连接
+func (b *amqpBroker) connection() (*amqp.Connection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.ensureConnLocked(); err != nil {
+		return nil, err
+	}
+	return b.conn, nil
+}
+
+func (b *amqpBroker) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func declareQueue(ch *amqp.Channel, topic string) error {
+	_, err := ch.QueueDeclare(topic, true, false, false, false, nil)
+	return err
+}
+
+// Publish 以持久化消息投递，并等待 Broker 确认
+func (b *amqpBroker) Publish(ctx context.Context, topic string, msg Message) error {
+	headers := make(amqp.Table, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	b.mu.Lock()
+	if err := b.ensureConnLocked(); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	if !b.declared[topic] {
+		if err := declareQueue(b.pubCh, topic); err != nil {
+			b.mu.Unlock()
+			return fmt.Errorf("declare queue %s: %w", topic, err)
+		}
+		b.declared[topic] = true
+	}
+	confirm, err := b.pubCh.PublishWithDeferredConfirmWithContext(ctx, "", topic, false, false, amqp.Publishing{
+		MessageId:    msg.ID,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Headers:      headers,
+		Body:         msg.Body,
+	})
+	b.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("publish to %s: %w", topic, err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("wait publish confirm: %w", err)
+	}
+	if !acked {
+		return fmt.Errorf("publish to %s: nacked by broker", topic)
+	}
+	return nil
+}
+
+// Consume 持续消费直到 ctx 结束或 Broker 关闭；连接或 channel 断开时按 reconnectBackoff 重连后继续消费
+func (b *amqpBroker) Consume(ctx context.Context, topic string, handler Handler) error {
+	attempt := 0
+	for {
+		started, err := b.consume(ctx, topic, handler)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if b.isClosed() {
+			return ErrClosed
+		}
+		if started {
+			attempt = 0
+		}
+		attempt++
+
+		delay := reconnectBackoff.Backoff(attempt)
+		b.logger.Warn("amqp consumer disconnected, reconnecting",
+			zap.String("topic", topic),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// consume 在单独的 channel 上消费，直到 ctx 结束或 channel/连接断开；started 表示是否已成功开始消费
+func (b *amqpBroker) consume(ctx context.Context, topic string, handler Handler) (started bool, err error) {
+	conn, err := b.connection()
+	if err != nil {
+		return false, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return false, fmt.Errorf("open amqp channel: %w", err)
+	}
+	defer func() { _ = ch.Close() }()
+
+	if err := declareQueue(ch, topic); err != nil {
+		return false, fmt.Errorf("declare queue %s: %w", topic, err)
+	}
+	if err := ch.Qos(b.prefetch, 0, false); err != nil {
+		return false, fmt.Errorf("set qos: %w", err)
+	}
+
+	deliveries, err := ch.ConsumeWithContext(ctx, topic, "", false, false, false, false, nil)
+	if err != nil {
+		return false, fmt.Errorf("consume %s: %w", topic, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return true, ErrClosed
+			}
+
+			msg := Message{ID: d.MessageId, Topic: topic, Body: d.Body, Headers: make(map[string]string, len(d.Headers))}
+			for k, v := range d.Headers {
+				if s, ok := v.(string); ok {
+					msg.Headers[k] = s
+				}
+			}
+
+			if err := handler(ctx, msg); err != nil {
+				requeue := ShouldRequeue(err)
+				b.logger.Error("message handling failed",
+					zap.String("topic", topic),
+					zap.String("message_id", msg.ID),
+					zap.Bool("requeue", requeue),
+					zap.Error(err),
+				)
+				if nackErr := d.Nack(false, requeue); nackErr != nil {
+					return true, fmt.Errorf("nack message: %w", nackErr)
+				}
+				continue
+			}
+			if err := d.Ack(false); err != nil {
+				return true, fmt.Errorf("ack message: %w", err)
+			}
+		}
+	}
+}
+
+// Close 关闭发布 channel 与连接，之后不再重连，正在消费的 Consume 返回 ErrClosed
+func (b *amqpBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	var errs []error
+	if b.pubCh != nil {
+		if err := b.pubCh.Close(); err != nil && !errors.Is(err, amqp.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	if b.conn != nil {
+		if err := b.conn.Close(); err != nil && !errors.Is(err, amqp.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package mq
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// memoryBroker 是 Broker 的进程内实现：每个主题对应一个带缓冲的 channel，
+// 多个消费者竞争消费同一主题。消息不持久化，进程退出即丢失，仅适用于开发与测试。
+// 发布到的每个主题都须有消费者，否则缓冲区写满后 Publish 将一直阻塞。
+type memoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]chan Message
+	buffer int
+	closed chan struct{}
+	once   sync.Once
+	logger *zap.Logger
+}
+
+// NewMemoryBroker 创建进程内消息队列，buffer 为每个主题的缓冲大小
+func NewMemoryBroker(buffer int, logger *zap.Logger) Broker {
+	if buffer <= 0 {
+		buffer = 1024
+	}
+	return &memoryBroker{
+		topics: make(map[string]chan Message),
+		buffer: buffer,
+		closed: make(chan struct{}),
+		logger: logger,
+	}
+}
+
+func (b *memoryBroker) topic(name string) chan Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch, ok := b.topics[name]
+	if !ok {
+		ch = make(chan Message, b.buffer)
+		b.topics[name] = ch
+	}
+	return ch
+}
+
+// Publish 投递消息；缓冲区满时阻塞直到有空位、ctx 结束或队列关闭
+func (b *memoryBroker) Publish(ctx context.Context, topic string, msg Message) error {
+	msg.Topic = topic
+	select {
+	case <-b.closed:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case b.topic(topic) <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.closed:
+		return ErrClosed
+	}
+}
+
+// Consume 消费消息，处理失败的消息记录日志后丢弃；ShouldRequeue 的错误尝试放回缓冲区，
+// 缓冲区已满时同样丢弃
+func (b *memoryBroker) Consume(ctx context.Context, topic string, handler Handler) error {
+	ch := b.topic(topic)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.closed:
+			return ErrClosed
+		case msg := <-ch:
+			if err := handler(ctx, msg); err != nil {
+				requeue := ShouldRequeue(err)
+				if requeue {
+					select {
+					case ch <- msg:
+					default:
+						requeue = false
+					}
+				}
+				b.logger.Error("message handling failed",
+					zap.String("topic", topic),
+					zap.String("message_id", msg.ID),
+					zap.Bool("requeue", requeue),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// Close 关闭队列，正在阻塞的发布与消费会返回 ErrClosed
+func (b *memoryBroker) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
@@ -0,0 +1,76 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestMemoryBroker_PublishConsume(t *testing.T) {
+	b := NewMemoryBroker(8, zap.NewNop())
+	defer func() { _ = b.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := b.Publish(ctx, "t", Message{ID: "1", Body: []byte("hello")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	got := make(chan Message, 1)
+	go func() {
+		_ = b.Consume(ctx, "t", func(_ context.Context, msg Message) error {
+			got <- msg
+			return nil
+		})
+	}()
+
+	select {
+	case msg := <-got:
+		if msg.ID != "1" || msg.Topic != "t" || string(msg.Body) != "hello" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatalf("message not consumed")
+	}
+}
+
+func TestMemoryBroker_Closed(t *testing.T) {
+	b := NewMemoryBroker(1, zap.NewNop())
+	_ = b.Close()
+
+	if err := b.Publish(context.Background(), "t", Message{ID: "1"}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if err := b.Consume(context.Background(), "t", func(context.Context, Message) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestMemoryBroker_RequeuesOnDeadLetterFailure(t *testing.T) {
+	b := NewMemoryBroker(8, zap.NewNop())
+	defer func() { _ = b.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := b.Publish(ctx, "t", Message{ID: "1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	calls := 0
+	_ = b.Consume(ctx, "t", func(context.Context, Message) error {
+		calls++
+		if calls == 1 {
+			return ErrDeadLetterFailed
+		}
+		cancel()
+		return nil
+	})
+	if calls != 2 {
+		t.Fatalf("expected message to be redelivered once, got %d calls", calls)
+	}
+}
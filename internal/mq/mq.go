@@ -0,0 +1,45 @@
+// Package mq 定义消息队列的发布/消费抽象，并提供进程内与 AMQP（RabbitMQ）两种实现。
+// 业务代码只依赖 Publisher/Consumer 接口，便于在测试与单机环境中使用内存实现。
+package mq
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrClosed 表示队列已关闭
+	ErrClosed = errors.New("mq: closed")
+	// ErrDeadLetterFailed 表示重试耗尽后写入死信失败，消息须交还给队列以免丢失
+	ErrDeadLetterFailed = errors.New("mq: dead letter failed")
+)
+
+// Message 表示一条待投递/已接收的消息
+type Message struct {
+	ID      string            // 消息唯一ID，消费者可据此去重
+	Topic   string            // 主题（AMQP 实现中对应队列名）
+	Body    []byte            // 消息体，约定为 JSON
+	Headers map[string]string // 附加元数据
+}
+
+// Handler 处理一条消息；返回 nil 表示处理成功（确认消息），否则视为处理失败
+type Handler func(ctx context.Context, msg Message) error
+
+// Publisher 消息发布者
+type Publisher interface {
+	// Publish 发布消息到指定主题，返回 nil 表示消息已被队列接收
+	Publish(ctx context.Context, topic string, msg Message) error
+}
+
+// Consumer 消息消费者
+type Consumer interface {
+	// Consume 持续消费指定主题的消息直到 ctx 结束或队列关闭；同一主题可被多个 goroutine 并发消费
+	Consume(ctx context.Context, topic string, handler Handler) error
+}
+
+// Broker 同时具备发布与消费能力的队列实现
+type Broker interface {
+	Publisher
+	Consumer
+	Close() error
+}
@@ -0,0 +1,117 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RetryPolicy 消费失败时的重试策略
+type RetryPolicy struct {
+	// MaxAttempts 最大处理次数（含首次），超过后转入死信
+	MaxAttempts int
+	// BaseDelay 首次重试前的等待时间，之后按 2 倍递增
+	BaseDelay time.Duration
+	// MaxDelay 单次等待时间上限
+	MaxDelay time.Duration
+}
+
+// Backoff 返回第 attempt 次失败后的等待时间
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// DeadLetterFunc 处理重试耗尽的消息，attempts 为实际处理次数，err 为最后一次错误；
+// 返回错误时 WithRetry 以 ErrDeadLetterFailed 包装，消息重新入队
+type DeadLetterFunc func(ctx context.Context, msg Message, attempts int, err error) error
+
+// permanentError 标记不可重试的错误
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 包装不可重试的错误（如消息格式错误），WithRetry 遇到后直接转入死信
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判断错误是否被标记为不可重试
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// ShouldRequeue 判断处理失败的消息是否应重新入队：ctx 结束（服务关闭）或死信写入失败时
+// 消息尚未被妥善处理，丢弃会导致库存预扣与去重标记无法补偿
+func ShouldRequeue(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrDeadLetterFailed)
+}
+
+// WithRetry 为 handler 增加有限次数的指数退避重试，重试耗尽后交给 deadLetter 处理
+// 重试在当前消费 goroutine 内进行，等待期间 ctx 结束时立即返回 ctx 错误，消息交还给队列；
+// deadLetter 失败时返回 ErrDeadLetterFailed，消息同样交还给队列。
+func WithRetry(handler Handler, policy RetryPolicy, deadLetter DeadLetterFunc, logger *zap.Logger) Handler {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return func(ctx context.Context, msg Message) error {
+		var err error
+		attempt := 0
+		for attempt < policy.MaxAttempts {
+			attempt++
+			if err = handler(ctx, msg); err == nil {
+				return nil
+			}
+			if IsPermanent(err) || attempt == policy.MaxAttempts {
+				break
+			}
+
+			delay := policy.Backoff(attempt)
+			logger.Warn("message handling failed, will retry",
+				zap.String("topic", msg.Topic),
+				zap.String("message_id", msg.ID),
+				zap.Int("attempt", attempt),
+				zap.Duration("retry_in", delay),
+				zap.Error(err),
+			)
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		logger.Error("message dead-lettered",
+			zap.String("topic", msg.Topic),
+			zap.String("message_id", msg.ID),
+			zap.Int("attempts", attempt),
+			zap.Error(err),
+		)
+		if deadLetter == nil {
+			return err
+		}
+		if dlErr := deadLetter(ctx, msg, attempt, err); dlErr != nil {
+			return fmt.Errorf("%w: %w", ErrDeadLetterFailed, dlErr)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	cases := map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		50: time.Second,
+	}
+	for attempt, want := range cases {
+		if got := p.Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestWithRetry_SucceedsAfterRetry(t *testing.T) {
+	calls := 0
+	h := WithRetry(func(context.Context, Message) error {
+		calls++
+		if calls < 3 {
+			return errors.New("boom")
+		}
+		return nil
+	}, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, func(context.Context, Message, int, error) error {
+		t.Fatalf("unexpected dead letter")
+		return nil
+	}, zap.NewNop())
+
+	if err := h(context.Background(), Message{ID: "1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestWithRetry_DeadLetter(t *testing.T) {
+	boom := errors.New("boom")
+	calls, deadAttempts := 0, 0
+	h := WithRetry(func(context.Context, Message) error {
+		calls++
+		return boom
+	}, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, func(_ context.Context, _ Message, attempts int, err error) error {
+		deadAttempts = attempts
+		if !errors.Is(err, boom) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return nil
+	}, zap.NewNop())
+
+	if err := h(context.Background(), Message{ID: "1"}); err != nil {
+		t.Fatalf("dead-lettered message should be acknowledged, got %v", err)
+	}
+	if calls != 3 || deadAttempts != 3 {
+		t.Fatalf("expected 3 attempts, got calls=%d dead=%d", calls, deadAttempts)
+	}
+}
+
+func TestWithRetry_PermanentSkipsRetry(t *testing.T) {
+	calls, dead := 0, false
+	h := WithRetry(func(context.Context, Message) error {
+		calls++
+		return Permanent(errors.New("bad message"))
+	}, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, func(context.Context, Message, int, error) error {
+		dead = true
+		return nil
+	}, zap.NewNop())
+
+	_ = h(context.Background(), Message{ID: "1"})
+	if calls != 1 || !dead {
+		t.Fatalf("expected single attempt then dead letter, got calls=%d dead=%v", calls, dead)
+	}
+}
+
+func TestWithRetry_CanceledDuringBackoffRequeues(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := WithRetry(func(context.Context, Message) error {
+		cancel()
+		return errors.New("boom")
+	}, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}, func(context.Context, Message, int, error) error {
+		t.Fatalf("unexpected dead letter")
+		return nil
+	}, zap.NewNop())
+
+	err := h(ctx, Message{ID: "1"})
+	if !errors.Is(err, context.Canceled) || !ShouldRequeue(err) {
+		t.Fatalf("expected message to be requeued on shutdown, got %v", err)
+	}
+}
+
+func TestWithRetry_DeadLetterFailureRequeues(t *testing.T) {
+	dbErr := errors.New("db down")
+	h := WithRetry(func(context.Context, Message) error {
+		return Permanent(errors.New("bad message"))
+	}, RetryPolicy{MaxAttempts: 1}, func(context.Context, Message, int, error) error {
+		return dbErr
+	}, zap.NewNop())
+
+	err := h(context.Background(), Message{ID: "1"})
+	if !errors.Is(err, ErrDeadLetterFailed) || !errors.Is(err, dbErr) || !ShouldRequeue(err) {
+		t.Fatalf("expected message to be requeued when dead letter fails, got %v", err)
+	}
+}
+
+func TestShouldRequeue(t *testing.T) {
+	cases := map[error]bool{
+		context.Canceled:             true,
+		context.DeadlineExceeded:     true,
+		ErrDeadLetterFailed:          true,
+		errors.New("boom"):           false,
+		Permanent(errors.New("bad")): false,
+	}
+	for err, want := range cases {
+		if got := ShouldRequeue(err); got != want {
+			t.Errorf("ShouldRequeue(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
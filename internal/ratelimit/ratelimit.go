@@ -0,0 +1,62 @@
+// Package ratelimit 提供按键限流器：令牌桶（允许突发）与滑动窗口（平滑计数）两种算法，
+// 各有进程内与 Redis 两种实现。进程内实现仅对单实例生效，多实例部署请使用 Redis 实现。
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result 表示一次限流判定结果
+type Result struct {
+	Allowed bool
+	// Remaining 本次判定后剩余的可用次数（近似值）
+	Remaining int64
+	// RetryAfter 被拒绝时建议的重试等待时间
+	RetryAfter time.Duration
+}
+
+// Limiter 定义按键限流器接口
+type Limiter interface {
+	// Allow 判定 key 的一次请求是否放行，放行时消耗一次配额
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// idleSweepInterval 进程内实现清理空闲键的间隔，避免按 IP/用户限流时键无限增长
+const idleSweepInterval = time.Minute
+
+// memoryStore 为进程内实现提供带空闲清理的键值存储
+type memoryStore[T any] struct {
+	mu        sync.Mutex
+	items     map[string]*T
+	lastSweep time.Time
+	// idle 判断条目在 now 时刻是否已空闲（等价于新建状态），空闲条目在清理时删除
+	idle func(v *T, now time.Time) bool
+}
+
+func newMemoryStore[T any](idle func(v *T, now time.Time) bool) *memoryStore[T] {
+	return &memoryStore[T]{items: make(map[string]*T), idle: idle}
+}
+
+// with 在持锁状态下取出（必要时创建）key 对应的条目并执行 fn
+func (s *memoryStore[T]) with(key string, now time.Time, fn func(v *T) Result) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= idleSweepInterval {
+		for k, v := range s.items {
+			if s.idle(v, now) {
+				delete(s.items, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	v, ok := s.items[key]
+	if !ok {
+		v = new(T)
+		s.items[key] = v
+	}
+	return fn(v)
+}
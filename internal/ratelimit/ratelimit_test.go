@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func allowN(t *testing.T, l Limiter, key string, n int) int {
+	t.Helper()
+	allowed := 0
+	for i := 0; i < n; i++ {
+		res, err := l.Allow(context.Background(), key)
+		if err != nil {
+			t.Fatalf("allow: %v", err)
+		}
+		if res.Allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := NewMemoryTokenBucket(10, 5).(*memoryTokenBucket)
+	l.now = clock.now
+
+	if got := allowN(t, l, "a", 10); got != 5 {
+		t.Fatalf("expected burst of 5, got %d", got)
+	}
+
+	res, _ := l.Allow(context.Background(), "a")
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > 100*time.Millisecond {
+		t.Fatalf("expected rejection with retry <= 100ms, got %+v", res)
+	}
+
+	// 其他键不受影响
+	if got := allowN(t, l, "b", 1); got != 1 {
+		t.Fatalf("expected independent bucket per key")
+	}
+
+	// 200ms 补充 2 个令牌
+	clock.advance(200 * time.Millisecond)
+	if got := allowN(t, l, "a", 5); got != 2 {
+		t.Fatalf("expected 2 refilled tokens, got %d", got)
+	}
+}
+
+func TestMemorySlidingWindow(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := NewMemorySlidingWindow(10, time.Second).(*memorySlidingWindow)
+	l.now = clock.now
+
+	if got := allowN(t, l, "a", 15); got != 10 {
+		t.Fatalf("expected 10 allowed in first window, got %d", got)
+	}
+
+	// 进入下一个窗口的 50%：上一窗口按 50% 计入，剩余 5 次
+	clock.advance(1500 * time.Millisecond)
+	if got := allowN(t, l, "a", 10); got != 5 {
+		t.Fatalf("expected 5 allowed at half window, got %d", got)
+	}
+
+	res, _ := l.Allow(context.Background(), "a")
+	if res.Allowed || res.RetryAfter <= 0 {
+		t.Fatalf("expected rejection with retry-after, got %+v", res)
+	}
+
+	// 空闲超过两个窗口后计数清零
+	clock.advance(3 * time.Second)
+	if got := allowN(t, l, "a", 10); got != 10 {
+		t.Fatalf("expected full quota after idle, got %d", got)
+	}
+}
+
+func TestSlidingWindowDecide_RetryAfter(t *testing.T) {
+	// 上一窗口 10 次，当前窗口 0 次，上限 10：需等到上一窗口权重降至 0.9 以下
+	ok, _, retry := slidingWindowDecide(10, time.Second, 0, 0, 10)
+	if ok {
+		t.Fatalf("expected rejection")
+	}
+	if retry != 100*time.Millisecond {
+		t.Fatalf("expected retry 100ms, got %s", retry)
+	}
+}
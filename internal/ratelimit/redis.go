@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript 令牌桶判定，时间取自 Redis 服务器，避免多实例时钟偏差
+// ARGV: rate（个/秒）、burst；返回 {allowed, remaining, retry_after_ms}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+local s = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(s[1]) or burst
+local ts = tonumber(s[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, math.floor(tokens), retry}
+`)
+
+// slidingWindowScript 滑动窗口计数判定，每个键一个 Hash，字段为固定窗口序号
+// ARGV: limit、window（毫秒）；返回 {allowed, remaining, retry_after_ms}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local idx = math.floor(now / window)
+local curr = tonumber(redis.call('HGET', KEYS[1], tostring(idx))) or 0
+local prev = tonumber(redis.call('HGET', KEYS[1], tostring(idx - 1))) or 0
+local elapsed = now - idx * window
+local estimated = prev * (1 - elapsed / window) + curr
+if estimated + 1 > limit then
+	local retry = window - elapsed
+	if curr + 1 <= limit and prev > 0 then
+		retry = math.max(math.ceil(window * (1 - (limit - curr - 1) / prev)) - elapsed, 1)
+	end
+	return {0, 0, retry}
+end
+redis.call('HINCRBY', KEYS[1], tostring(idx), 1)
+redis.call('HDEL', KEYS[1], tostring(idx - 2))
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {1, math.floor(limit - estimated - 1), 0}
+`)
+
+// redisLimiter 是 Limiter 的 Redis 实现，多实例共享配额
+type redisLimiter struct {
+	rdb    redis.UniversalClient
+	prefix string
+	script *redis.Script
+	args   []any
+}
+
+// NewRedisTokenBucket 创建基于 Redis 的令牌桶限流器，prefix 用于区分不同限流规则
+func NewRedisTokenBucket(rdb redis.UniversalClient, prefix string, rate float64, burst int) Limiter {
+	return &redisLimiter{rdb: rdb, prefix: prefix, script: tokenBucketScript, args: []any{rate, burst}}
+}
+
+// NewRedisSlidingWindow 创建基于 Redis 的滑动窗口限流器，prefix 用于区分不同限流规则
+func NewRedisSlidingWindow(rdb redis.UniversalClient, prefix string, limit int, window time.Duration) Limiter {
+	return &redisLimiter{rdb: rdb, prefix: prefix, script: slidingWindowScript, args: []any{limit, window.Milliseconds()}}
+}
+
+// Allow 判定一次请求
+func (l *redisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	res, err := l.script.Run(ctx, l.rdb, []string{"ratelimit:" + l.prefix + ":" + key}, l.args...).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit: %w", err)
+	}
+	if len(res) != 3 {
+		return Result{}, fmt.Errorf("rate limit: unexpected script result %v", res)
+	}
+	return Result{
+		Allowed:    res[0] == 1,
+		Remaining:  res[1],
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// window 滑动窗口计数状态：当前固定窗口与上一个固定窗口的计数
+type window struct {
+	start time.Time
+	curr  int64
+	prev  int64
+}
+
+// memorySlidingWindow 是滑动窗口计数算法的进程内实现：
+// 以上一个固定窗口的计数按重叠比例加权，加上当前窗口计数，近似任意 window 时长内的请求数，
+// 相比固定窗口不会在窗口边界放过两倍流量，且每个键只需保存两个计数
+type memorySlidingWindow struct {
+	limit  int64
+	window time.Duration
+	store  *memoryStore[window]
+	now    func() time.Time
+}
+
+// NewMemorySlidingWindow 创建进程内滑动窗口限流器，任意 window 时长内最多放行 limit 次
+func NewMemorySlidingWindow(limit int, w time.Duration) Limiter {
+	l := &memorySlidingWindow{limit: int64(limit), window: w, now: time.Now}
+	l.store = newMemoryStore(func(s *window, now time.Time) bool {
+		return now.Sub(s.start) >= 2*l.window
+	})
+	return l
+}
+
+// Allow 判定一次请求
+func (l *memorySlidingWindow) Allow(_ context.Context, key string) (Result, error) {
+	now := l.now()
+	return l.store.with(key, now, func(s *window) Result {
+		start := now.Truncate(l.window)
+		if start.After(s.start) {
+			if start.Sub(s.start) == l.window {
+				s.prev = s.curr
+			} else {
+				s.prev = 0
+			}
+			s.curr, s.start = 0, start
+		}
+
+		elapsed := now.Sub(start)
+		allowed, remaining, retry := slidingWindowDecide(l.limit, l.window, elapsed, s.curr, s.prev)
+		if allowed {
+			s.curr++
+		}
+		return Result{Allowed: allowed, Remaining: remaining, RetryAfter: retry}
+	}), nil
+}
+
+// slidingWindowDecide 根据当前窗口已过时长与两个窗口的计数判定是否放行
+func slidingWindowDecide(limit int64, w, elapsed time.Duration, curr, prev int64) (bool, int64, time.Duration) {
+	weight := 1 - float64(elapsed)/float64(w)
+	estimated := float64(prev)*weight + float64(curr)
+	if estimated+1 <= float64(limit) {
+		return true, int64(float64(limit) - estimated - 1), 0
+	}
+
+	// 当前窗口已满或上一个窗口无计数时，只能等到下一个窗口
+	if curr+1 > limit || prev == 0 {
+		return false, 0, w - elapsed
+	}
+	// 否则等到上一个窗口的加权计数衰减到足以容纳本次请求
+	until := time.Duration(math.Ceil(float64(w) * (1 - float64(limit-curr-1)/float64(prev))))
+	return false, 0, max(until-elapsed, time.Millisecond)
+}
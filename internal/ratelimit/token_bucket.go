@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// bucket 令牌桶状态
+type bucket struct {
+	started bool
+	tokens  float64
+	last    time.Time
+}
+
+// memoryTokenBucket 是令牌桶算法的进程内实现：
+// 每个键独立一个桶，以 rate 个/秒补充令牌，最多积攒 burst 个，每次请求消耗一个
+type memoryTokenBucket struct {
+	rate  float64
+	burst float64
+	store *memoryStore[bucket]
+	now   func() time.Time
+}
+
+// NewMemoryTokenBucket 创建进程内令牌桶限流器，rate 为每秒补充的令牌数，burst 为桶容量
+func NewMemoryTokenBucket(rate float64, burst int) Limiter {
+	l := &memoryTokenBucket{rate: rate, burst: float64(burst), now: time.Now}
+	l.store = newMemoryStore(func(b *bucket, now time.Time) bool {
+		return l.refill(b, now) >= l.burst
+	})
+	return l
+}
+
+// refill 返回 now 时刻桶内的令牌数（不修改状态）
+func (l *memoryTokenBucket) refill(b *bucket, now time.Time) float64 {
+	if !b.started {
+		return l.burst
+	}
+	elapsed := max(now.Sub(b.last).Seconds(), 0)
+	return math.Min(l.burst, b.tokens+elapsed*l.rate)
+}
+
+// Allow 判定一次请求
+func (l *memoryTokenBucket) Allow(_ context.Context, key string) (Result, error) {
+	now := l.now()
+	return l.store.with(key, now, func(b *bucket) Result {
+		tokens := l.refill(b, now)
+		b.started, b.last = true, now
+
+		if tokens < 1 {
+			b.tokens = tokens
+			wait := time.Duration((1 - tokens) / l.rate * float64(time.Second))
+			return Result{Allowed: false, RetryAfter: wait}
+		}
+		b.tokens = tokens - 1
+		return Result{Allowed: true, Remaining: int64(b.tokens)}
+	}), nil
+}
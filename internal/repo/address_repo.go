@@ -0,0 +1,266 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+// AddressRepository 定义用户收货地址数据访问接口
+// 写操作均限定 user_id，避免越权修改他人地址；设为默认地址时在同一事务中清除其他默认标记
+type AddressRepository interface {
+	Create(ctx context.Context, address *domain.Address) error
+	// GetByID 查询用户的地址，不存在或不属于该用户时返回 nil, nil
+	GetByID(ctx context.Context, userID, id int64) (*domain.Address, error)
+	// GetDefault 查询用户的默认地址，没有时返回 nil, nil
+	GetDefault(ctx context.Context, userID int64) (*domain.Address, error)
+	// ListByUser 查询用户的全部地址，默认地址在前
+	ListByUser(ctx context.Context, userID int64) ([]*domain.Address, error)
+	CountByUser(ctx context.Context, userID int64) (int, error)
+	Update(ctx context.Context, address *domain.Address) error
+	// Delete 删除地址；删除的是默认地址时将最近更新的另一个地址设为默认
+	Delete(ctx context.Context, userID, id int64) error
+	SetDefault(ctx context.Context, userID, id int64) error
+}
+
+// addressRepo 是 AddressRepository 接口的数据库实现
+type addressRepo struct {
+	db *database.DB
+}
+
+// NewAddressRepository 创建收货地址仓储实例
+func NewAddressRepository(db *database.DB) AddressRepository {
+	return &addressRepo{db: db}
+}
+
+const addressColumns = `id, user_id, receiver_name, phone, province, city, district, detail, postal_code, is_default, created_at, updated_at`
+
+// Create 创建地址
+func (r *addressRepo) Create(ctx context.Context, address *domain.Address) error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(ctx, tx, address.UserID); err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO user_addresses (user_id, receiver_name, phone, province, city, district, detail, postal_code, is_default)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			address.UserID,
+			address.ReceiverName,
+			address.Phone,
+			address.Province,
+			address.City,
+			address.District,
+			address.Detail,
+			address.PostalCode,
+			address.IsDefault,
+		)
+		if err != nil {
+			return fmt.Errorf("insert address: %w", err)
+		}
+
+		if address.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("get last insert id: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("create address: %w", err)
+	}
+	return nil
+}
+
+// GetByID 查询地址
+func (r *addressRepo) GetByID(ctx context.Context, userID, id int64) (*domain.Address, error) {
+	address, err := scanAddress(r.db.QueryRowContext(ctx,
+		`SELECT `+addressColumns+` FROM user_addresses WHERE id = ? AND user_id = ?`, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get address: %w", err)
+	}
+	return address, nil
+}
+
+// GetDefault 查询默认地址
+func (r *addressRepo) GetDefault(ctx context.Context, userID int64) (*domain.Address, error) {
+	address, err := scanAddress(r.db.QueryRowContext(ctx,
+		`SELECT `+addressColumns+` FROM user_addresses WHERE user_id = ? AND is_default = 1 LIMIT 1`, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get default address: %w", err)
+	}
+	return address, nil
+}
+
+// ListByUser 查询用户地址列表
+func (r *addressRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.Address, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+addressColumns+` FROM user_addresses WHERE user_id = ? ORDER BY is_default DESC, updated_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list addresses: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var addresses []*domain.Address
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan address: %w", err)
+		}
+		addresses = append(addresses, address)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+// CountByUser 统计用户地址数量
+func (r *addressRepo) CountByUser(ctx context.Context, userID int64) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_addresses WHERE user_id = ?`, userID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count addresses: %w", err)
+	}
+	return n, nil
+}
+
+// Update 修改地址
+func (r *addressRepo) Update(ctx context.Context, address *domain.Address) error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(ctx, tx, address.UserID); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_addresses
+			SET receiver_name = ?, phone = ?, province = ?, city = ?, district = ?, detail = ?, postal_code = ?, is_default = ?
+			WHERE id = ? AND user_id = ?
+		`,
+			address.ReceiverName,
+			address.Phone,
+			address.Province,
+			address.City,
+			address.District,
+			address.Detail,
+			address.PostalCode,
+			address.IsDefault,
+			address.ID,
+			address.UserID,
+		)
+		if err != nil {
+			return fmt.Errorf("update address: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("update address: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除地址
+func (r *addressRepo) Delete(ctx context.Context, userID, id int64) error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		var isDefault bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT is_default FROM user_addresses WHERE id = ? AND user_id = ? FOR UPDATE`, id, userID,
+		).Scan(&isDefault)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return fmt.Errorf("lock address: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_addresses WHERE id = ? AND user_id = ?`, id, userID); err != nil {
+			return fmt.Errorf("delete address: %w", err)
+		}
+		if !isDefault {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`UPDATE user_addresses SET is_default = 1 WHERE user_id = ? ORDER BY updated_at DESC, id DESC LIMIT 1`, userID)
+		if err != nil {
+			return fmt.Errorf("promote default address: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("delete address: %w", err)
+	}
+	return nil
+}
+
+// SetDefault 设为默认地址
+func (r *addressRepo) SetDefault(ctx context.Context, userID, id int64) error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := clearDefaultAddress(ctx, tx, userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE user_addresses SET is_default = 1 WHERE id = ? AND user_id = ?`, id, userID); err != nil {
+			return fmt.Errorf("set default address: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("set default address: %w", err)
+	}
+	return nil
+}
+
+// clearDefaultAddress 清除用户的默认地址标记
+func clearDefaultAddress(ctx context.Context, exec database.Executor, userID int64) error {
+	if _, err := exec.ExecContext(ctx,
+		`UPDATE user_addresses SET is_default = 0 WHERE user_id = ? AND is_default = 1`, userID); err != nil {
+		return fmt.Errorf("clear default address: %w", err)
+	}
+	return nil
+}
+
+// insertOrderAddress 写入订单收货地址快照，需由调用方在创建订单的事务中执行
+func insertOrderAddress(ctx context.Context, exec database.Executor, orderID int64, a *domain.OrderAddress) error {
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO order_addresses (order_id, receiver_name, phone, province, city, district, detail, postal_code)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, orderID, a.ReceiverName, a.Phone, a.Province, a.City, a.District, a.Detail, a.PostalCode)
+	if err != nil {
+		return fmt.Errorf("insert order address: %w", err)
+	}
+	return nil
+}
+
+func scanAddress(s rowScanner) (*domain.Address, error) {
+	a := &domain.Address{}
+	err := s.Scan(
+		&a.ID,
+		&a.UserID,
+		&a.ReceiverName,
+		&a.Phone,
+		&a.Province,
+		&a.City,
+		&a.District,
+		&a.Detail,
+		&a.PostalCode,
+		&a.IsDefault,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
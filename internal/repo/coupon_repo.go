@@ -0,0 +1,278 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+var (
+	// ErrDuplicateCouponCode 表示券码已存在
+	ErrDuplicateCouponCode = errors.New("coupon code already exists")
+	// ErrCouponExhausted 表示优惠券已停用或总可用次数已用完
+	ErrCouponExhausted = errors.New("coupon is disabled or exhausted")
+	// ErrCouponUserLimitReached 表示用户使用该优惠券的次数已达上限
+	ErrCouponUserLimitReached = errors.New("coupon per-user limit reached")
+)
+
+// CouponRepository 定义优惠券数据访问接口
+type CouponRepository interface {
+	// Create 创建优惠券，券码已存在时返回 ErrDuplicateCouponCode
+	Create(ctx context.Context, coupon *domain.Coupon) error
+	GetByID(ctx context.Context, id int64) (*domain.Coupon, error)
+	GetByCode(ctx context.Context, code string) (*domain.Coupon, error)
+	UpdateStatus(ctx context.Context, id int64, status domain.CouponStatus) error
+	List(ctx context.Context, filter domain.CouponFilter) ([]*domain.Coupon, int, error)
+	// CountUserRedemptions 统计用户已使用该优惠券的次数
+	CountUserRedemptions(ctx context.Context, couponID, userID int64) (int, error)
+}
+
+// couponRepo 是 CouponRepository 接口的数据库实现
+type couponRepo struct {
+	db *database.DB
+}
+
+// NewCouponRepository 创建优惠券仓储实例
+func NewCouponRepository(db *database.DB) CouponRepository {
+	return &couponRepo{db: db}
+}
+
+const couponColumns = `id, code, name, type, value, max_discount, min_spend, product_ids, category_ids,
+	total_limit, per_user_limit, used_count, start_at, end_at, status, created_at, updated_at`
+
+// Create 创建优惠券
+func (r *couponRepo) Create(ctx context.Context, coupon *domain.Coupon) error {
+	productIDs, err := marshalIDs(coupon.ProductIDs)
+	if err != nil {
+		return err
+	}
+	categoryIDs, err := marshalIDs(coupon.CategoryIDs)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO coupons (code, name, type, value, max_discount, min_spend, product_ids, category_ids,
+			total_limit, per_user_limit, start_at, end_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		coupon.Code,
+		coupon.Name,
+		string(coupon.Type),
+		coupon.Value,
+		coupon.MaxDiscount,
+		coupon.MinSpend,
+		productIDs,
+		categoryIDs,
+		coupon.TotalLimit,
+		coupon.PerUserLimit,
+		coupon.StartAt,
+		coupon.EndAt,
+		string(coupon.Status),
+	)
+	if err != nil {
+		if isDuplicateKeyOn(err, "uk_code") {
+			return ErrDuplicateCouponCode
+		}
+		return fmt.Errorf("create coupon: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get last insert id: %w", err)
+	}
+	coupon.ID = id
+
+	return nil
+}
+
+// GetByID 根据ID查询优惠券，不存在时返回 nil, nil
+func (r *couponRepo) GetByID(ctx context.Context, id int64) (*domain.Coupon, error) {
+	return r.get(ctx, `SELECT `+couponColumns+` FROM coupons WHERE id = ?`, id)
+}
+
+// GetByCode 根据券码查询优惠券，不存在时返回 nil, nil
+func (r *couponRepo) GetByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	return r.get(ctx, `SELECT `+couponColumns+` FROM coupons WHERE code = ?`, code)
+}
+
+func (r *couponRepo) get(ctx context.Context, query string, arg any) (*domain.Coupon, error) {
+	coupon, err := scanCoupon(r.db.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get coupon: %w", err)
+	}
+	return coupon, nil
+}
+
+// UpdateStatus 更新优惠券状态
+func (r *couponRepo) UpdateStatus(ctx context.Context, id int64, status domain.CouponStatus) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE coupons SET status = ? WHERE id = ?`, string(status), id); err != nil {
+		return fmt.Errorf("update coupon status: %w", err)
+	}
+	return nil
+}
+
+// List 分页查询优惠券（走从库）
+func (r *couponRepo) List(ctx context.Context, filter domain.CouponFilter) ([]*domain.Coupon, int, error) {
+	where := ""
+	var args []any
+	if filter.Status != "" {
+		where = " WHERE status = ?"
+		args = append(args, string(filter.Status))
+	}
+
+	var total int
+	if err := r.db.Reader().QueryRowContext(ctx, `SELECT COUNT(*) FROM coupons`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count coupons: %w", err)
+	}
+
+	query := `SELECT ` + couponColumns + ` FROM coupons` + where + ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	rows, err := r.db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list coupons: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var coupons []*domain.Coupon
+	for rows.Next() {
+		coupon, err := scanCoupon(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan coupon: %w", err)
+		}
+		coupons = append(coupons, coupon)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("list coupons: %w", err)
+	}
+
+	return coupons, total, nil
+}
+
+// CountUserRedemptions 统计用户使用次数
+func (r *couponRepo) CountUserRedemptions(ctx context.Context, couponID, userID int64) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM coupon_redemptions WHERE coupon_id = ? AND user_id = ?`, couponID, userID,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count coupon redemptions: %w", err)
+	}
+	return n, nil
+}
+
+// redeemCoupons 核销订单使用的优惠券并写入优惠明细，需由调用方在创建订单的事务中执行
+// 先以条件更新占用总次数（同时锁定券行，使同一张券的核销串行化），再校验用户使用次数
+func redeemCoupons(ctx context.Context, exec database.Executor, order *domain.Order) error {
+	for _, d := range order.Discounts {
+		result, err := exec.ExecContext(ctx, `
+			UPDATE coupons SET used_count = used_count + 1
+			WHERE id = ? AND status = ? AND (total_limit = 0 OR used_count < total_limit)
+		`, d.CouponID, string(domain.CouponStatusActive))
+		if err != nil {
+			return fmt.Errorf("redeem coupon: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("get rows affected: %w", err)
+		} else if n == 0 {
+			return fmt.Errorf("%w: %s", ErrCouponExhausted, d.CouponCode)
+		}
+
+		var perUserLimit, used int
+		err = exec.QueryRowContext(ctx, `
+			SELECT c.per_user_limit, (SELECT COUNT(*) FROM coupon_redemptions WHERE coupon_id = c.id AND user_id = ?)
+			FROM coupons c WHERE c.id = ?
+		`, order.UserID, d.CouponID).Scan(&perUserLimit, &used)
+		if err != nil {
+			return fmt.Errorf("check coupon redemptions: %w", err)
+		}
+		if perUserLimit > 0 && used >= perUserLimit {
+			return fmt.Errorf("%w: %s", ErrCouponUserLimitReached, d.CouponCode)
+		}
+
+		_, err = exec.ExecContext(ctx,
+			`INSERT INTO coupon_redemptions (coupon_id, user_id, order_id, amount) VALUES (?, ?, ?, ?)`,
+			d.CouponID, order.UserID, order.ID, d.Amount,
+		)
+		if err != nil {
+			return fmt.Errorf("insert coupon redemption: %w", err)
+		}
+
+		_, err = exec.ExecContext(ctx,
+			`INSERT INTO order_discounts (order_id, coupon_id, coupon_code, type, amount, description) VALUES (?, ?, ?, ?, ?, ?)`,
+			order.ID, d.CouponID, d.CouponCode, string(d.Type), d.Amount, d.Description,
+		)
+		if err != nil {
+			return fmt.Errorf("insert order discount: %w", err)
+		}
+	}
+	return nil
+}
+
+func scanCoupon(s rowScanner) (*domain.Coupon, error) {
+	coupon := &domain.Coupon{}
+	var productIDs, categoryIDs []byte
+	err := s.Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Name,
+		&coupon.Type,
+		&coupon.Value,
+		&coupon.MaxDiscount,
+		&coupon.MinSpend,
+		&productIDs,
+		&categoryIDs,
+		&coupon.TotalLimit,
+		&coupon.PerUserLimit,
+		&coupon.UsedCount,
+		&coupon.StartAt,
+		&coupon.EndAt,
+		&coupon.Status,
+		&coupon.CreatedAt,
+		&coupon.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if coupon.ProductIDs, err = unmarshalIDs(productIDs); err != nil {
+		return nil, err
+	}
+	if coupon.CategoryIDs, err = unmarshalIDs(categoryIDs); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// marshalIDs 将ID列表编码为 JSON 列，空列表存为 NULL
+func marshalIDs(ids []int64) (any, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("marshal ids: %w", err)
+	}
+	return string(b), nil
+}
+
+func unmarshalIDs(b []byte) ([]int64, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var ids []int64
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return nil, fmt.Errorf("unmarshal ids: %w", err)
+	}
+	return ids, nil
+}
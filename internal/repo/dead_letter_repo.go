@@ -0,0 +1,164 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+// DeadLetterRepository 定义死信数据访问接口
+type DeadLetterRepository interface {
+	Create(ctx context.Context, dl *domain.DeadLetter) error
+	GetByID(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, int, error)
+	// UpdateStatus 仅当当前状态为 from 时更新为 to，返回是否更新成功
+	UpdateStatus(ctx context.Context, id int64, from, to domain.DeadLetterStatus) (bool, error)
+}
+
+// deadLetterRepo 是 DeadLetterRepository 接口的数据库实现
+type deadLetterRepo struct {
+	db *database.DB
+}
+
+// NewDeadLetterRepository 创建死信仓储实例
+func NewDeadLetterRepository(db *database.DB) DeadLetterRepository {
+	return &deadLetterRepo{db: db}
+}
+
+const deadLetterColumns = `id, topic, message_id, payload, headers, attempts, last_error, status, created_at, updated_at`
+
+// Create 保存一条死信
+func (r *deadLetterRepo) Create(ctx context.Context, dl *domain.DeadLetter) error {
+	var headers any
+	if len(dl.Headers) > 0 {
+		data, err := json.Marshal(dl.Headers)
+		if err != nil {
+			return fmt.Errorf("marshal dead letter headers: %w", err)
+		}
+		headers = data
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO dead_letters (topic, message_id, payload, headers, attempts, last_error)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		dl.Topic,
+		dl.MessageID,
+		dl.Payload,
+		headers,
+		dl.Attempts,
+		truncate(dl.LastError, 512),
+	)
+	if err != nil {
+		return fmt.Errorf("insert dead letter: %w", err)
+	}
+
+	if dl.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("get last insert id: %w", err)
+	}
+	dl.Status = domain.DeadLetterStatusDead
+	return nil
+}
+
+// GetByID 根据ID查询死信，不存在时返回 nil, nil
+func (r *deadLetterRepo) GetByID(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	query := `SELECT ` + deadLetterColumns + ` FROM dead_letters WHERE id = ?`
+
+	dl, err := scanDeadLetter(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 死信不存在
+		}
+		return nil, fmt.Errorf("get dead letter by id: %w", err)
+	}
+
+	return dl, nil
+}
+
+// List 分页查询死信，返回当前页数据与总数
+func (r *deadLetterRepo) List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, int, error) {
+	where := " WHERE 1 = 1"
+	var args []any
+	if filter.Status != "" {
+		where += " AND status = ?"
+		args = append(args, string(filter.Status))
+	}
+	if filter.Topic != "" {
+		where += " AND topic = ?"
+		args = append(args, filter.Topic)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM dead_letters`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count dead letters: %w", err)
+	}
+
+	query := `SELECT ` + deadLetterColumns + ` FROM dead_letters` + where + ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list dead letters: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*domain.DeadLetter
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan dead letter: %w", err)
+		}
+		items = append(items, dl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate dead letters: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// UpdateStatus 以条件更新实现状态流转，避免并发重放同一条死信
+func (r *deadLetterRepo) UpdateStatus(ctx context.Context, id int64, from, to domain.DeadLetterStatus) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE dead_letters SET status = ? WHERE id = ? AND status = ?`, string(to), id, string(from),
+	)
+	if err != nil {
+		return false, fmt.Errorf("update dead letter status: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected: %w", err)
+	}
+	return n > 0, nil
+}
+
+func scanDeadLetter(s rowScanner) (*domain.DeadLetter, error) {
+	dl := &domain.DeadLetter{}
+	var headers []byte
+	err := s.Scan(
+		&dl.ID,
+		&dl.Topic,
+		&dl.MessageID,
+		&dl.Payload,
+		&headers,
+		&dl.Attempts,
+		&dl.LastError,
+		&dl.Status,
+		&dl.CreatedAt,
+		&dl.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &dl.Headers); err != nil {
+			return nil, fmt.Errorf("unmarshal dead letter headers: %w", err)
+		}
+	}
+	return dl, nil
+}
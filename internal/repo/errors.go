@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlErrDuplicateEntry 是 MySQL 违反唯一约束的错误码
+const mysqlErrDuplicateEntry = 1062
+
+// isDuplicateKeyOn 判断错误是否为违反指定名称的唯一约束
+// MySQL 错误信息形如：Duplicate entry 'x' for key 'spike_orders.uk_ticket'
+func isDuplicateKeyOn(err error, key string) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == mysqlErrDuplicateEntry && strings.Contains(me.Message, key)
+}
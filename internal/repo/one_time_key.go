@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// oneTimeKeySweepInterval 进程内实现清理过期键的间隔
+const oneTimeKeySweepInterval = time.Minute
+
+// OneTimeKeyStore 定义一次性键存储接口，用于一次性凭证与防重放标记
+type OneTimeKeyStore interface {
+	// Add 写入键，返回 false 表示键已存在（未过期）
+	Add(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Consume 删除键，返回 false 表示键不存在或已过期（已被使用）
+	Consume(ctx context.Context, key string) (bool, error)
+}
+
+// memoryOneTimeKeyStore 是 OneTimeKeyStore 的进程内实现，过期键在写入时顺带清理
+type memoryOneTimeKeyStore struct {
+	mu        sync.Mutex
+	keys      map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryOneTimeKeyStore 创建进程内一次性键存储
+func NewMemoryOneTimeKeyStore() OneTimeKeyStore {
+	return &memoryOneTimeKeyStore{keys: make(map[string]time.Time)}
+}
+
+// Add 写入键
+func (s *memoryOneTimeKeyStore) Add(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > oneTimeKeySweepInterval {
+		for k, exp := range s.keys {
+			if now.After(exp) {
+				delete(s.keys, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if exp, ok := s.keys[key]; ok && !now.After(exp) {
+		return false, nil
+	}
+	s.keys[key] = now.Add(ttl)
+	return true, nil
+}
+
+// Consume 删除键
+func (s *memoryOneTimeKeyStore) Consume(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exp, ok := s.keys[key]
+	if !ok {
+		return false, nil
+	}
+	delete(s.keys, key)
+	return !time.Now().After(exp), nil
+}
@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisOneTimeKeyStore 是 OneTimeKeyStore 的 Redis 实现
+type redisOneTimeKeyStore struct {
+	rdb redis.UniversalClient
+}
+
+// NewRedisOneTimeKeyStore 创建基于 Redis 的一次性键存储
+func NewRedisOneTimeKeyStore(rdb redis.UniversalClient) OneTimeKeyStore {
+	return &redisOneTimeKeyStore{rdb: rdb}
+}
+
+// Add 写入键（SET NX PX）
+func (s *redisOneTimeKeyStore) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := s.rdb.SetNX(ctx, key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("add one-time key: %w", err)
+	}
+	return ok, nil
+}
+
+// Consume 删除键，DEL 的原子性保证并发使用时只有一方成功
+func (s *redisOneTimeKeyStore) Consume(ctx context.Context, key string) (bool, error) {
+	n, err := s.rdb.Del(ctx, key).Result()
+	if err != nil {
+		return false, fmt.Errorf("consume one-time key: %w", err)
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryOneTimeKeyStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryOneTimeKeyStore()
+
+	if ok, _ := s.Add(ctx, "k", time.Minute); !ok {
+		t.Fatalf("expected first add to succeed")
+	}
+	if ok, _ := s.Add(ctx, "k", time.Minute); ok {
+		t.Fatalf("expected duplicate add to be rejected")
+	}
+	if ok, _ := s.Consume(ctx, "k"); !ok {
+		t.Fatalf("expected consume to succeed")
+	}
+	if ok, _ := s.Consume(ctx, "k"); ok {
+		t.Fatalf("expected second consume to fail")
+	}
+
+	_, _ = s.Add(ctx, "expired", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := s.Consume(ctx, "expired"); ok {
+		t.Fatalf("expected expired key to be unusable")
+	}
+}
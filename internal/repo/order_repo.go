@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+// OrderRepository 定义订单数据访问接口
+// 订单随业务流程（如秒杀下单）在各自仓储的事务中创建，这里只提供查询
+type OrderRepository interface {
+	// GetByID 查询订单及其订单行、优惠明细与收货地址快照，不存在时返回 nil, nil
+	GetByID(ctx context.Context, id int64) (*domain.Order, error)
+}
+
+// orderRepo 是 OrderRepository 接口的数据库实现
+type orderRepo struct {
+	db *database.DB
+}
+
+// NewOrderRepository 创建订单仓储实例
+func NewOrderRepository(db *database.DB) OrderRepository {
+	return &orderRepo{db: db}
+}
+
+// GetByID 查询订单详情
+func (r *orderRepo) GetByID(ctx context.Context, id int64) (*domain.Order, error) {
+	order := &domain.Order{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, status, subtotal_amount, shipping_fee, discount_amount, total_amount, created_at, updated_at
+		FROM orders WHERE id = ?
+	`, id).Scan(
+		&order.ID,
+		&order.UserID,
+		&order.Status,
+		&order.SubtotalAmount,
+		&order.ShippingFee,
+		&order.DiscountAmount,
+		&order.TotalAmount,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, order_id, product_id, quantity, price FROM order_items WHERE order_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list order items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		item := &domain.OrderItem{}
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.Price); err != nil {
+			return nil, fmt.Errorf("scan order item: %w", err)
+		}
+		order.Items = append(order.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list order items: %w", err)
+	}
+
+	discounts, err := r.db.QueryContext(ctx,
+		`SELECT coupon_id, coupon_code, type, amount, description FROM order_discounts WHERE order_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list order discounts: %w", err)
+	}
+	defer func() { _ = discounts.Close() }()
+	for discounts.Next() {
+		d := &domain.OrderDiscount{}
+		if err := discounts.Scan(&d.CouponID, &d.CouponCode, &d.Type, &d.Amount, &d.Description); err != nil {
+			return nil, fmt.Errorf("scan order discount: %w", err)
+		}
+		order.Discounts = append(order.Discounts, d)
+	}
+	if err := discounts.Err(); err != nil {
+		return nil, fmt.Errorf("list order discounts: %w", err)
+	}
+
+	address := &domain.OrderAddress{}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT receiver_name, phone, province, city, district, detail, postal_code
+		FROM order_addresses WHERE order_id = ?
+	`, id).Scan(&address.ReceiverName, &address.Phone, &address.Province, &address.City, &address.District, &address.Detail, &address.PostalCode)
+	switch {
+	case err == nil:
+		order.Address = address
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("get order address: %w", err)
+	}
+
+	return order, nil
+}
+
+// insertOrder 写入订单、订单行、收货地址快照并核销使用的优惠券，需由调用方在事务中执行以保证一致
+// 优惠券已停用或次数用尽时返回 ErrCouponExhausted / ErrCouponUserLimitReached
+func insertOrder(ctx context.Context, exec database.Executor, order *domain.Order) error {
+	result, err := exec.ExecContext(ctx,
+		`INSERT INTO orders (user_id, status, subtotal_amount, shipping_fee, discount_amount, total_amount) VALUES (?, ?, ?, ?, ?, ?)`,
+		order.UserID,
+		string(order.Status),
+		order.SubtotalAmount,
+		order.ShippingFee,
+		order.DiscountAmount,
+		order.TotalAmount,
+	)
+	if err != nil {
+		return fmt.Errorf("insert order: %w", err)
+	}
+
+	orderID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get last insert id: %w", err)
+	}
+	order.ID = orderID
+
+	for _, item := range order.Items {
+		item.OrderID = orderID
+		result, err := exec.ExecContext(ctx,
+			`INSERT INTO order_items (order_id, product_id, quantity, price) VALUES (?, ?, ?, ?)`,
+			item.OrderID,
+			item.ProductID,
+			item.Quantity,
+			item.Price,
+		)
+		if err != nil {
+			return fmt.Errorf("insert order item: %w", err)
+		}
+		if item.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("get last insert id: %w", err)
+		}
+	}
+
+	if order.Address != nil {
+		if err := insertOrderAddress(ctx, exec, orderID, order.Address); err != nil {
+			return err
+		}
+	}
+
+	return redeemCoupons(ctx, exec, order)
+}
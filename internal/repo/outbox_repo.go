@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/google/uuid"
+)
+
+// OutboxRepository 定义本地消息表数据访问接口（供 relay 使用）
+// 写入由各业务仓储在自身事务中通过 insertOutboxEvent 完成。
+type OutboxRepository interface {
+	// Claim 以租约方式认领一批到期的待投递消息，租约到期前其他 relay 不会重复认领
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error)
+	MarkSent(ctx context.Context, id int64) error
+	// MarkRetry 记录一次投递失败，并在 delay 之后重新可被认领
+	MarkRetry(ctx context.Context, id int64, lastErr string, delay time.Duration) error
+	// MarkFailed 记录最后一次投递失败并放弃投递
+	MarkFailed(ctx context.Context, id int64, lastErr string) error
+	// CountPending 统计待投递消息数量（积压量）
+	CountPending(ctx context.Context) (int64, error)
+}
+
+// outboxRepo 是 OutboxRepository 接口的数据库实现
+// 时间判断统一使用数据库时间 NOW(3)，避免多实例之间的时钟偏差
+type outboxRepo struct {
+	db *database.DB
+}
+
+// NewOutboxRepository 创建本地消息表仓储实例
+func NewOutboxRepository(db *database.DB) OutboxRepository {
+	return &outboxRepo{db: db}
+}
+
+// insertOutboxEvent 在调用方事务中写入一条待投递消息，payload 以 JSON 编码
+func insertOutboxEvent(ctx context.Context, exec database.Executor, topic string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal outbox payload: %w", err)
+	}
+
+	_, err = exec.ExecContext(ctx,
+		`INSERT INTO outbox (topic, message_id, payload) VALUES (?, ?, ?)`,
+		topic,
+		uuid.NewString(),
+		data,
+	)
+	if err != nil {
+		return fmt.Errorf("insert outbox: %w", err)
+	}
+	return nil
+}
+
+// Claim 认领一批消息：先用唯一令牌 UPDATE 占住行，再按令牌查询本次认领的行
+func (r *outboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	token := uuid.NewString()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox
+		SET claim_token = ?, claimed_until = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+		WHERE status = 'pending' AND next_attempt_at <= NOW(3)
+		  AND (claimed_until IS NULL OR claimed_until < NOW(3))
+		ORDER BY id
+		LIMIT ?
+	`, token, lease.Microseconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, topic, message_id, payload, status, attempts, next_attempt_at, last_error, created_at
+		FROM outbox WHERE claim_token = ? ORDER BY id
+	`, token)
+	if err != nil {
+		return nil, fmt.Errorf("query claimed outbox: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var msgs []*domain.OutboxMessage
+	for rows.Next() {
+		m := &domain.OutboxMessage{}
+		if err := rows.Scan(&m.ID, &m.Topic, &m.MessageID, &m.Payload, &m.Status, &m.Attempts,
+			&m.NextAttemptAt, &m.LastError, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, rows.Err()
+}
+
+// MarkSent 标记消息已投递
+func (r *outboxRepo) MarkSent(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox
+		SET status = 'sent', attempts = attempts + 1, sent_at = NOW(3), claim_token = NULL, claimed_until = NULL
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return fmt.Errorf("mark outbox sent: %w", err)
+	}
+	return nil
+}
+
+// MarkRetry 记录失败并安排重试
+func (r *outboxRepo) MarkRetry(ctx context.Context, id int64, lastErr string, delay time.Duration) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = ?, next_attempt_at = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND),
+		    claim_token = NULL, claimed_until = NULL
+		WHERE id = ?
+	`, truncate(lastErr, 512), delay.Microseconds(), id)
+	if err != nil {
+		return fmt.Errorf("mark outbox retry: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed 记录失败并放弃投递
+func (r *outboxRepo) MarkFailed(ctx context.Context, id int64, lastErr string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox
+		SET status = 'failed', attempts = attempts + 1, last_error = ?, claim_token = NULL, claimed_until = NULL
+		WHERE id = ?
+	`, truncate(lastErr, 512), id)
+	if err != nil {
+		return fmt.Errorf("mark outbox failed: %w", err)
+	}
+	return nil
+}
+
+// CountPending 统计待投递消息数量
+func (r *outboxRepo) CountPending(ctx context.Context) (int64, error) {
+	var n int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM outbox WHERE status = 'pending'`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count pending outbox: %w", err)
+	}
+	return n, nil
+}
+
+// truncate 按字符截断字符串，保证写入定长列时不超长
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
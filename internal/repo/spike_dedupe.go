@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpikeDedupe 定义秒杀“用户-活动”去重标记接口
+// 在预减库存之前拦截重复购买，避免同一用户重复占用库存；数据库唯一约束作为最终防线。
+type SpikeDedupe interface {
+	// Mark 为用户在活动中写入已购买标记，返回 false 表示标记已存在（重复购买）
+	// ttl 为标记保留时长，应覆盖活动剩余时间
+	Mark(ctx context.Context, eventID, userID int64, ttl time.Duration) (bool, error)
+	// Unmark 清除已购买标记（用于预减失败或下单失败后的补偿）
+	Unmark(ctx context.Context, eventID, userID int64) error
+}
+
+// memorySpikeDedupe 是 SpikeDedupe 的进程内实现
+type memorySpikeDedupe struct {
+	mu     sync.Mutex
+	events map[int64]*memoryBuyers
+}
+
+type memoryBuyers struct {
+	users     map[int64]struct{}
+	expiresAt time.Time
+}
+
+// NewMemorySpikeDedupe 创建进程内去重标记
+func NewMemorySpikeDedupe() SpikeDedupe {
+	return &memorySpikeDedupe{events: make(map[int64]*memoryBuyers)}
+}
+
+// Mark 写入已购买标记；同一活动的标记整体过期，与 Redis 实现保持一致
+func (d *memorySpikeDedupe) Mark(_ context.Context, eventID, userID int64, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	buyers, ok := d.events[eventID]
+	if !ok || now.After(buyers.expiresAt) {
+		buyers = &memoryBuyers{users: make(map[int64]struct{})}
+		d.events[eventID] = buyers
+	}
+	if _, ok := buyers.users[userID]; ok {
+		return false, nil
+	}
+	buyers.users[userID] = struct{}{}
+	if exp := now.Add(ttl); exp.After(buyers.expiresAt) {
+		buyers.expiresAt = exp
+	}
+	return true, nil
+}
+
+// Unmark 清除已购买标记
+func (d *memorySpikeDedupe) Unmark(_ context.Context, eventID, userID int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if buyers, ok := d.events[eventID]; ok {
+		delete(buyers.users, userID)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// markScript 原子执行 SADD 并在新增成员时刷新过期时间
+// 返回值：1 新增标记；0 标记已存在
+var markScript = redis.NewScript(`
+local added = redis.call('SADD', KEYS[1], ARGV[1])
+if added == 1 then
+	local ttl = tonumber(ARGV[2])
+	if redis.call('PTTL', KEYS[1]) < ttl then
+		redis.call('PEXPIRE', KEYS[1], ttl)
+	end
+end
+return added
+`)
+
+// redisSpikeDedupe 是 SpikeDedupe 的 Redis 实现：每个活动一个 Set，成员为用户ID
+type redisSpikeDedupe struct {
+	rdb redis.UniversalClient
+}
+
+// NewRedisSpikeDedupe 创建基于 Redis 的去重标记
+func NewRedisSpikeDedupe(rdb redis.UniversalClient) SpikeDedupe {
+	return &redisSpikeDedupe{rdb: rdb}
+}
+
+func buyersKey(eventID int64) string { return fmt.Sprintf("spike:{%d}:buyers", eventID) }
+
+// Mark 写入已购买标记
+func (d *redisSpikeDedupe) Mark(ctx context.Context, eventID, userID int64, ttl time.Duration) (bool, error) {
+	added, err := markScript.Run(ctx, d.rdb, []string{buyersKey(eventID)}, userID, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("mark spike buyer: %w", err)
+	}
+	return added == 1, nil
+}
+
+// Unmark 清除已购买标记
+func (d *redisSpikeDedupe) Unmark(ctx context.Context, eventID, userID int64) error {
+	if err := d.rdb.SRem(ctx, buyersKey(eventID), userID).Err(); err != nil {
+		return fmt.Errorf("unmark spike buyer: %w", err)
+	}
+	return nil
+}
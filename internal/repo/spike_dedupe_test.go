@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemorySpikeDedupe_MarkUnmark(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemorySpikeDedupe()
+
+	if ok, _ := d.Mark(ctx, 1, 100, time.Minute); !ok {
+		t.Fatalf("expected first mark to succeed")
+	}
+	if ok, _ := d.Mark(ctx, 1, 100, time.Minute); ok {
+		t.Fatalf("expected duplicate mark to be rejected")
+	}
+	if ok, _ := d.Mark(ctx, 2, 100, time.Minute); !ok {
+		t.Fatalf("expected mark on another event to succeed")
+	}
+
+	_ = d.Unmark(ctx, 1, 100)
+	if ok, _ := d.Mark(ctx, 1, 100, time.Minute); !ok {
+		t.Fatalf("expected mark to succeed after unmark")
+	}
+}
+
+func TestMemorySpikeDedupe_Expire(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemorySpikeDedupe()
+
+	_, _ = d.Mark(ctx, 1, 100, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := d.Mark(ctx, 1, 100, time.Minute); !ok {
+		t.Fatalf("expected mark to succeed after expiry")
+	}
+}
@@ -0,0 +1,207 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+// SpikeEventRepository 定义秒杀活动数据访问接口
+type SpikeEventRepository interface {
+	Create(ctx context.Context, event *domain.SpikeEvent) error
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	Update(ctx context.Context, event *domain.SpikeEvent) error
+	UpdateStatus(ctx context.Context, id int64, status domain.SpikeEventStatus) error
+	List(ctx context.Context, filter domain.SpikeEventFilter) ([]*domain.SpikeEvent, int, error)
+	// ListActive 返回在 now 时刻尚未结束的已排期活动（包含未开始与进行中），按开始时间升序
+	ListActive(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error)
+}
+
+// spikeEventRepo 是 SpikeEventRepository 接口的数据库实现
+type spikeEventRepo struct {
+	db *database.DB
+}
+
+// NewSpikeEventRepository 创建秒杀活动仓储实例
+func NewSpikeEventRepository(db *database.DB) SpikeEventRepository {
+	return &spikeEventRepo{db: db}
+}
+
+const spikeEventColumns = `id, product_id, title, spike_price, total_stock, per_user_limit, start_at, end_at, status, created_at, updated_at`
+
+// Create 创建秒杀活动
+func (r *spikeEventRepo) Create(ctx context.Context, event *domain.SpikeEvent) error {
+	query := `
+		INSERT INTO spike_events (product_id, title, spike_price, total_stock, per_user_limit, start_at, end_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		event.ProductID,
+		event.Title,
+		event.SpikePrice,
+		event.TotalStock,
+		event.PerUserLimit,
+		event.StartAt,
+		event.EndAt,
+		string(event.Status),
+	)
+	if err != nil {
+		return fmt.Errorf("create spike event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get last insert id: %w", err)
+	}
+
+	event.ID = id
+	return nil
+}
+
+// GetByID 根据ID查询秒杀活动，不存在时返回 nil, nil
+// 查询默认走从库；下单热路径（经本地缓存）、管理端详情与读-改-写场景须以 database.WithPrimary 强制读主库
+func (r *spikeEventRepo) GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error) {
+	query := `SELECT ` + spikeEventColumns + ` FROM spike_events WHERE id = ?`
+
+	event, err := scanSpikeEvent(r.db.Reader().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 活动不存在
+		}
+		return nil, fmt.Errorf("get spike event by id: %w", err)
+	}
+
+	return event, nil
+}
+
+// Update 更新秒杀活动的可编辑字段
+func (r *spikeEventRepo) Update(ctx context.Context, event *domain.SpikeEvent) error {
+	query := `
+		UPDATE spike_events
+		SET title = ?, spike_price = ?, total_stock = ?, per_user_limit = ?, start_at = ?, end_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		event.Title,
+		event.SpikePrice,
+		event.TotalStock,
+		event.PerUserLimit,
+		event.StartAt,
+		event.EndAt,
+		event.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update spike event: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus 更新秒杀活动状态，并在同一事务中写入 spike.event.status_changed 本地消息
+func (r *spikeEventRepo) UpdateStatus(ctx context.Context, id int64, status domain.SpikeEventStatus) error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		query := `UPDATE spike_events SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, string(status), id); err != nil {
+			return fmt.Errorf("update spike event status: %w", err)
+		}
+
+		return insertOutboxEvent(ctx, tx, domain.TopicSpikeEventStatusChanged, domain.SpikeEventStatusChangedEvent{
+			EventID:    id,
+			Status:     status,
+			OccurredAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("update spike event status: %w", err)
+	}
+
+	return nil
+}
+
+// List 分页查询秒杀活动，返回当前页数据与总数
+func (r *spikeEventRepo) List(ctx context.Context, filter domain.SpikeEventFilter) ([]*domain.SpikeEvent, int, error) {
+	where := ""
+	var args []any
+	if filter.Status != "" {
+		where = " WHERE status = ?"
+		args = append(args, string(filter.Status))
+	}
+
+	var total int
+	if err := r.db.Reader().QueryRowContext(ctx, `SELECT COUNT(*) FROM spike_events`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count spike events: %w", err)
+	}
+
+	query := `SELECT ` + spikeEventColumns + ` FROM spike_events` + where + ` ORDER BY start_at DESC, id DESC LIMIT ? OFFSET ?`
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	events, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list spike events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// ListActive 查询未结束的已排期活动
+func (r *spikeEventRepo) ListActive(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error) {
+	query := `SELECT ` + spikeEventColumns + ` FROM spike_events WHERE status = ? AND end_at > ? ORDER BY start_at ASC, id ASC`
+
+	events, err := r.query(ctx, query, string(domain.SpikeEventStatusScheduled), now)
+	if err != nil {
+		return nil, fmt.Errorf("list active spike events: %w", err)
+	}
+
+	return events, nil
+}
+
+// query 执行列表查询（走从库）
+func (r *spikeEventRepo) query(ctx context.Context, query string, args ...any) ([]*domain.SpikeEvent, error) {
+	rows, err := r.db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []*domain.SpikeEvent
+	for rows.Next() {
+		event, err := scanSpikeEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// rowScanner 抽象 *sql.Row 与 *sql.Rows 的 Scan 方法
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSpikeEvent(s rowScanner) (*domain.SpikeEvent, error) {
+	event := &domain.SpikeEvent{}
+	err := s.Scan(
+		&event.ID,
+		&event.ProductID,
+		&event.Title,
+		&event.SpikePrice,
+		&event.TotalStock,
+		&event.PerUserLimit,
+		&event.StartAt,
+		&event.EndAt,
+		&event.Status,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
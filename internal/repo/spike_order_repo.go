@@ -0,0 +1,149 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+var (
+	// ErrDuplicateTicket 表示该排队凭证已经落库（消息被重复投递）
+	ErrDuplicateTicket = errors.New("spike order ticket already exists")
+	// ErrSpikeStockExhausted 表示活动已落库的售出件数达到分配库存，作为计数器之外的最终防超卖校验
+	ErrSpikeStockExhausted = errors.New("spike event persisted stock exhausted")
+	// ErrDuplicateSpikeOrder 表示用户在该活动中已有秒杀订单（违反 uk_user_event 唯一约束）
+	ErrDuplicateSpikeOrder = errors.New("user already purchased in this spike event")
+)
+
+// SpikeOrderRepository 定义秒杀订单数据访问接口
+type SpikeOrderRepository interface {
+	// Create 在同一事务中创建订单、订单行、秒杀订单记录与 order.created 本地消息；
+	// ticket 已存在时返回 ErrDuplicateTicket，用户已在该活动下过单时返回 ErrDuplicateSpikeOrder，
+	// 售出件数将超过活动库存时返回 ErrSpikeStockExhausted，
+	// 订单使用的优惠券不可用时返回 ErrCouponExhausted / ErrCouponUserLimitReached
+	Create(ctx context.Context, order *domain.Order, spikeOrder *domain.SpikeOrder) error
+	GetByTicket(ctx context.Context, ticket string) (*domain.SpikeOrder, error)
+	// SoldQuantity 统计活动已落库的售出件数
+	SoldQuantity(ctx context.Context, eventID int64) (int64, error)
+}
+
+// spikeOrderRepo 是 SpikeOrderRepository 接口的数据库实现
+type spikeOrderRepo struct {
+	db *database.DB
+}
+
+// NewSpikeOrderRepository 创建秒杀订单仓储实例
+func NewSpikeOrderRepository(db *database.DB) SpikeOrderRepository {
+	return &spikeOrderRepo{db: db}
+}
+
+// Create 创建秒杀订单
+// 不锁定活动行：ticket 幂等与同一用户重复购买由唯一键保证，冲突时回滚整个事务；
+// 防超卖由 sold_stock 的条件更新兜底，放在事务末尾，使活动行的行锁只持有到提交
+func (r *spikeOrderRepo) Create(ctx context.Context, order *domain.Order, spikeOrder *domain.SpikeOrder) error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := insertOrder(ctx, tx, order); err != nil {
+			return err
+		}
+
+		spikeOrder.OrderID = order.ID
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO spike_orders (spike_event_id, user_id, order_id, ticket, quantity) VALUES (?, ?, ?, ?, ?)`,
+			spikeOrder.SpikeEventID,
+			spikeOrder.UserID,
+			spikeOrder.OrderID,
+			spikeOrder.Ticket,
+			spikeOrder.Quantity,
+		)
+		if err != nil {
+			switch {
+			case isDuplicateKeyOn(err, "uk_ticket"):
+				return ErrDuplicateTicket
+			case isDuplicateKeyOn(err, "uk_user_event"):
+				return ErrDuplicateSpikeOrder
+			}
+			return fmt.Errorf("insert spike order: %w", err)
+		}
+		if spikeOrder.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("get last insert id: %w", err)
+		}
+
+		result, err = tx.ExecContext(ctx,
+			`UPDATE spike_events SET sold_stock = sold_stock + ? WHERE id = ? AND sold_stock + ? <= total_stock`,
+			spikeOrder.Quantity, spikeOrder.SpikeEventID, spikeOrder.Quantity,
+		)
+		if err != nil {
+			return fmt.Errorf("increase spike event sold stock: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("get rows affected: %w", err)
+		}
+		if affected == 0 {
+			return ErrSpikeStockExhausted
+		}
+
+		return insertOutboxEvent(ctx, tx, domain.TopicOrderCreated, domain.OrderCreatedEvent{
+			OrderID:      order.ID,
+			UserID:       order.UserID,
+			TotalAmount:  order.TotalAmount,
+			SpikeEventID: spikeOrder.SpikeEventID,
+			Ticket:       spikeOrder.Ticket,
+			OccurredAt:   time.Now(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrDuplicateTicket) || errors.Is(err, ErrDuplicateSpikeOrder) || errors.Is(err, ErrSpikeStockExhausted) ||
+			errors.Is(err, ErrCouponExhausted) || errors.Is(err, ErrCouponUserLimitReached) {
+			return err
+		}
+		return fmt.Errorf("create spike order: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTicket 根据排队凭证查询秒杀订单，不存在时返回 nil, nil
+func (r *spikeOrderRepo) GetByTicket(ctx context.Context, ticket string) (*domain.SpikeOrder, error) {
+	so := &domain.SpikeOrder{}
+	query := `
+		SELECT id, spike_event_id, user_id, order_id, ticket, quantity, created_at
+		FROM spike_orders WHERE ticket = ?
+	`
+
+	err := r.db.QueryRowContext(ctx, query, ticket).Scan(
+		&so.ID,
+		&so.SpikeEventID,
+		&so.UserID,
+		&so.OrderID,
+		&so.Ticket,
+		&so.Quantity,
+		&so.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 尚未落库
+		}
+		return nil, fmt.Errorf("get spike order by ticket: %w", err)
+	}
+
+	return so, nil
+}
+
+// SoldQuantity 读取活动已售出件数（与秒杀订单在同一事务内维护的 sold_stock），活动不存在时返回 0
+func (r *spikeOrderRepo) SoldQuantity(ctx context.Context, eventID int64) (int64, error) {
+	var sold int64
+	err := r.db.QueryRowContext(ctx, `SELECT sold_stock FROM spike_events WHERE id = ?`, eventID).Scan(&sold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("get spike event sold stock: %w", err)
+	}
+	return sold, nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+// SpikeResultStore 定义秒杀下单结果存储接口
+// 秒杀接口受理时写入 queued，消费者落库后更新为终态，客户端按 ticket 查询。
+type SpikeResultStore interface {
+	Set(ctx context.Context, result *domain.SpikeOrderResult) error
+	// Get 查询结果，不存在或已过期时返回 nil, nil
+	Get(ctx context.Context, ticket string) (*domain.SpikeOrderResult, error)
+}
+
+// memorySpikeResultStore 是 SpikeResultStore 的进程内实现，过期数据在写入时顺带清理
+type memorySpikeResultStore struct {
+	mu        sync.RWMutex
+	results   map[string]memorySpikeResult
+	ttl       time.Duration
+	lastSweep time.Time
+}
+
+type memorySpikeResult struct {
+	result    domain.SpikeOrderResult
+	expiresAt time.Time
+}
+
+// NewMemorySpikeResultStore 创建进程内结果存储，ttl 为结果保留时长
+func NewMemorySpikeResultStore(ttl time.Duration) SpikeResultStore {
+	return &memorySpikeResultStore{
+		results: make(map[string]memorySpikeResult),
+		ttl:     ttl,
+	}
+}
+
+// Set 写入结果（保存副本，避免调用方后续修改影响存储内容）
+func (s *memorySpikeResultStore) Set(_ context.Context, result *domain.SpikeOrderResult) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > s.ttl {
+		for ticket, r := range s.results {
+			if now.After(r.expiresAt) {
+				delete(s.results, ticket)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	s.results[result.Ticket] = memorySpikeResult{result: *result, expiresAt: now.Add(s.ttl)}
+	return nil
+}
+
+// Get 查询结果
+func (s *memorySpikeResultStore) Get(_ context.Context, ticket string) (*domain.SpikeOrderResult, error) {
+	s.mu.RLock()
+	r, ok := s.results[ticket]
+	s.mu.RUnlock()
+
+	if !ok || time.Now().After(r.expiresAt) {
+		return nil, nil
+	}
+	result := r.result
+	return &result, nil
+}
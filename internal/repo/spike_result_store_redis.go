@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisSpikeResultStore 是 SpikeResultStore 的 Redis 实现，结果以 JSON 形式保存并设置过期时间
+type redisSpikeResultStore struct {
+	rdb redis.UniversalClient
+	ttl time.Duration
+}
+
+// NewRedisSpikeResultStore 创建基于 Redis 的结果存储，ttl 为结果保留时长
+func NewRedisSpikeResultStore(rdb redis.UniversalClient, ttl time.Duration) SpikeResultStore {
+	return &redisSpikeResultStore{rdb: rdb, ttl: ttl}
+}
+
+func resultKey(ticket string) string { return "spike:result:" + ticket }
+
+// Set 写入结果
+func (s *redisSpikeResultStore) Set(ctx context.Context, result *domain.SpikeOrderResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal spike result: %w", err)
+	}
+	if err := s.rdb.Set(ctx, resultKey(result.Ticket), data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("set spike result: %w", err)
+	}
+	return nil
+}
+
+// Get 查询结果
+func (s *redisSpikeResultStore) Get(ctx context.Context, ticket string) (*domain.SpikeOrderResult, error) {
+	data, err := s.rdb.Get(ctx, resultKey(ticket)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get spike result: %w", err)
+	}
+
+	var result domain.SpikeOrderResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal spike result: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,163 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrStockSoldOut 表示活动库存不足（已售罄）
+	ErrStockSoldOut = errors.New("spike stock sold out")
+	// ErrStockNotWarmed 表示活动库存尚未预热到计数器中
+	ErrStockNotWarmed = errors.New("spike stock not warmed up")
+)
+
+// StockCounter 定义秒杀库存计数器接口
+// 秒杀热路径只与计数器交互（预减库存 + 售罄标记），不直接访问 MySQL；
+// 持久化库存由异步下单流程负责落库。
+type StockCounter interface {
+	// Init 预热活动库存，仅当计数器不存在时写入（用于启动时从数据库预热，避免覆盖运行中的计数）
+	Init(ctx context.Context, eventID int64, stock int64) error
+	// Reset 强制覆盖活动库存并清除售罄标记（用于活动排期或编辑库存）
+	Reset(ctx context.Context, eventID int64, stock int64) error
+	// Decrement 在库存充足时原子扣减 n 件并返回剩余库存；
+	// 库存不足返回 ErrStockSoldOut 并设置售罄标记，未预热返回 ErrStockNotWarmed
+	Decrement(ctx context.Context, eventID int64, n int64) (int64, error)
+	// Restore 回补 n 件库存并清除售罄标记（用于下单失败或取消后的补偿）
+	Restore(ctx context.Context, eventID int64, n int64) error
+	// IsSoldOut 查询售罄标记，用于在扣减前快速短路
+	IsSoldOut(ctx context.Context, eventID int64) (bool, error)
+	// Stock 查询当前剩余库存，未预热返回 ErrStockNotWarmed
+	Stock(ctx context.Context, eventID int64) (int64, error)
+}
+
+const memoryStockShards = 32
+
+// memoryStockCounter 是 StockCounter 的进程内实现
+// 按活动ID分片降低锁竞争，单个活动的扣减通过 CAS 完成，无需持有分片锁。
+// 仅适用于单实例部署或测试；多实例部署请使用 Redis 实现。
+type memoryStockCounter struct {
+	shards [memoryStockShards]memoryStockShard
+}
+
+type memoryStockShard struct {
+	mu     sync.RWMutex
+	stocks map[int64]*memoryStock
+}
+
+type memoryStock struct {
+	stock   atomic.Int64
+	soldOut atomic.Bool
+}
+
+// NewMemoryStockCounter 创建进程内分片库存计数器
+func NewMemoryStockCounter() StockCounter {
+	c := &memoryStockCounter{}
+	for i := range c.shards {
+		c.shards[i].stocks = make(map[int64]*memoryStock)
+	}
+	return c
+}
+
+func (c *memoryStockCounter) shard(eventID int64) *memoryStockShard {
+	return &c.shards[uint64(eventID)%memoryStockShards]
+}
+
+func (c *memoryStockCounter) get(eventID int64) *memoryStock {
+	sh := c.shard(eventID)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.stocks[eventID]
+}
+
+// Init 仅当计数器不存在时写入库存
+func (c *memoryStockCounter) Init(_ context.Context, eventID int64, stock int64) error {
+	sh := c.shard(eventID)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if _, ok := sh.stocks[eventID]; ok {
+		return nil
+	}
+	s := &memoryStock{}
+	s.stock.Store(stock)
+	s.soldOut.Store(stock <= 0)
+	sh.stocks[eventID] = s
+	return nil
+}
+
+// Reset 覆盖库存并重置售罄标记
+func (c *memoryStockCounter) Reset(_ context.Context, eventID int64, stock int64) error {
+	sh := c.shard(eventID)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	s, ok := sh.stocks[eventID]
+	if !ok {
+		s = &memoryStock{}
+		sh.stocks[eventID] = s
+	}
+	s.stock.Store(stock)
+	s.soldOut.Store(stock <= 0)
+	return nil
+}
+
+// Decrement 通过 CAS 循环实现“库存充足才扣减”
+func (c *memoryStockCounter) Decrement(_ context.Context, eventID int64, n int64) (int64, error) {
+	s := c.get(eventID)
+	if s == nil {
+		return 0, ErrStockNotWarmed
+	}
+
+	for {
+		if s.soldOut.Load() {
+			return 0, ErrStockSoldOut
+		}
+		cur := s.stock.Load()
+		if cur < n {
+			// 剩余库存不足本次购买量时只拒绝本次请求；仍有库存时不设置售罄标记，购买量更小的请求仍可成功
+			if cur <= 0 {
+				s.soldOut.Store(true)
+			}
+			return 0, ErrStockSoldOut
+		}
+		if s.stock.CompareAndSwap(cur, cur-n) {
+			left := cur - n
+			if left == 0 {
+				s.soldOut.Store(true)
+			}
+			return left, nil
+		}
+	}
+}
+
+// Restore 回补库存并清除售罄标记
+func (c *memoryStockCounter) Restore(_ context.Context, eventID int64, n int64) error {
+	s := c.get(eventID)
+	if s == nil {
+		return ErrStockNotWarmed
+	}
+	s.stock.Add(n)
+	s.soldOut.Store(false)
+	return nil
+}
+
+// IsSoldOut 查询售罄标记，未预热的活动视为未售罄
+func (c *memoryStockCounter) IsSoldOut(_ context.Context, eventID int64) (bool, error) {
+	s := c.get(eventID)
+	if s == nil {
+		return false, nil
+	}
+	return s.soldOut.Load(), nil
+}
+
+// Stock 查询剩余库存
+func (c *memoryStockCounter) Stock(_ context.Context, eventID int64) (int64, error) {
+	s := c.get(eventID)
+	if s == nil {
+		return 0, ErrStockNotWarmed
+	}
+	return s.stock.Load(), nil
+}
@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// decrementScript 原子执行“检查售罄标记 -> 检查库存 -> 扣减 -> 必要时设置售罄标记”
+// 库存不足本次购买量但仍有剩余时只拒绝本次请求，售罄标记仅在库存归零时设置
+// 返回值：>=0 剩余库存；-1 售罄；-2 未预热
+var decrementScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	return -1
+end
+local stock = redis.call('GET', KEYS[1])
+if not stock then
+	return -2
+end
+local n = tonumber(ARGV[1])
+if tonumber(stock) < n then
+	if tonumber(stock) <= 0 then
+		redis.call('SET', KEYS[2], '1')
+	end
+	return -1
+end
+local left = redis.call('DECRBY', KEYS[1], n)
+if left == 0 then
+	redis.call('SET', KEYS[2], '1')
+end
+return left
+`)
+
+// restoreScript 仅在计数器存在时回补库存并清除售罄标记，避免凭空创建计数器
+// 返回值：>=0 回补后库存；-2 未预热
+var restoreScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -2
+end
+local left = redis.call('INCRBY', KEYS[1], tonumber(ARGV[1]))
+redis.call('DEL', KEYS[2])
+return left
+`)
+
+// redisStockCounter 是 StockCounter 的 Redis 实现，扣减与回补通过 Lua 脚本保证原子性
+type redisStockCounter struct {
+	rdb redis.UniversalClient
+}
+
+// NewRedisStockCounter 创建基于 Redis + Lua 的库存计数器
+func NewRedisStockCounter(rdb redis.UniversalClient) StockCounter {
+	return &redisStockCounter{rdb: rdb}
+}
+
+// 使用 hash tag 保证同一活动的库存与售罄标记落在同一个 slot（兼容 Redis Cluster 执行 Lua）
+func stockKey(eventID int64) string   { return fmt.Sprintf("spike:{%d}:stock", eventID) }
+func soldOutKey(eventID int64) string { return fmt.Sprintf("spike:{%d}:soldout", eventID) }
+
+// Init 仅当计数器不存在时写入库存
+func (c *redisStockCounter) Init(ctx context.Context, eventID int64, stock int64) error {
+	ok, err := c.rdb.SetNX(ctx, stockKey(eventID), stock, 0).Result()
+	if err != nil {
+		return fmt.Errorf("init spike stock: %w", err)
+	}
+	if ok && stock <= 0 {
+		if err := c.rdb.Set(ctx, soldOutKey(eventID), "1", 0).Err(); err != nil {
+			return fmt.Errorf("mark spike sold out: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reset 覆盖库存并重置售罄标记
+func (c *redisStockCounter) Reset(ctx context.Context, eventID int64, stock int64) error {
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, stockKey(eventID), stock, 0)
+		if stock <= 0 {
+			pipe.Set(ctx, soldOutKey(eventID), "1", 0)
+		} else {
+			pipe.Del(ctx, soldOutKey(eventID))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reset spike stock: %w", err)
+	}
+	return nil
+}
+
+// Decrement 原子预减库存
+func (c *redisStockCounter) Decrement(ctx context.Context, eventID int64, n int64) (int64, error) {
+	left, err := decrementScript.Run(ctx, c.rdb, []string{stockKey(eventID), soldOutKey(eventID)}, n).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("decrement spike stock: %w", err)
+	}
+
+	switch left {
+	case -1:
+		return 0, ErrStockSoldOut
+	case -2:
+		return 0, ErrStockNotWarmed
+	}
+	return left, nil
+}
+
+// Restore 回补库存并清除售罄标记
+func (c *redisStockCounter) Restore(ctx context.Context, eventID int64, n int64) error {
+	left, err := restoreScript.Run(ctx, c.rdb, []string{stockKey(eventID), soldOutKey(eventID)}, n).Int64()
+	if err != nil {
+		return fmt.Errorf("restore spike stock: %w", err)
+	}
+	if left == -2 {
+		return ErrStockNotWarmed
+	}
+	return nil
+}
+
+// IsSoldOut 查询售罄标记
+func (c *redisStockCounter) IsSoldOut(ctx context.Context, eventID int64) (bool, error) {
+	n, err := c.rdb.Exists(ctx, soldOutKey(eventID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("check spike sold out: %w", err)
+	}
+	return n == 1, nil
+}
+
+// Stock 查询剩余库存
+func (c *redisStockCounter) Stock(ctx context.Context, eventID int64) (int64, error) {
+	stock, err := c.rdb.Get(ctx, stockKey(eventID)).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrStockNotWarmed
+		}
+		return 0, fmt.Errorf("get spike stock: %w", err)
+	}
+	return stock, nil
+}
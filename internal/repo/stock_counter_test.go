@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMemoryStockCounter_NotWarmed(t *testing.T) {
+	c := NewMemoryStockCounter()
+	if _, err := c.Decrement(context.Background(), 1, 1); !errors.Is(err, ErrStockNotWarmed) {
+		t.Fatalf("expected ErrStockNotWarmed, got %v", err)
+	}
+	if err := c.Restore(context.Background(), 1, 1); !errors.Is(err, ErrStockNotWarmed) {
+		t.Fatalf("expected ErrStockNotWarmed, got %v", err)
+	}
+}
+
+func TestMemoryStockCounter_InitDoesNotOverwrite(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryStockCounter()
+	_ = c.Init(ctx, 1, 10)
+	if _, err := c.Decrement(ctx, 1, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = c.Init(ctx, 1, 10)
+	if stock, _ := c.Stock(ctx, 1); stock != 7 {
+		t.Fatalf("expected 7 after re-init, got %d", stock)
+	}
+
+	_ = c.Reset(ctx, 1, 10)
+	if stock, _ := c.Stock(ctx, 1); stock != 10 {
+		t.Fatalf("expected 10 after reset, got %d", stock)
+	}
+}
+
+func TestMemoryStockCounter_SoldOutAndRestore(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryStockCounter()
+	_ = c.Init(ctx, 1, 2)
+
+	if left, err := c.Decrement(ctx, 1, 2); err != nil || left != 0 {
+		t.Fatalf("expected left=0, got %d err=%v", left, err)
+	}
+	if soldOut, _ := c.IsSoldOut(ctx, 1); !soldOut {
+		t.Fatalf("expected sold out flag after stock reaches zero")
+	}
+	if _, err := c.Decrement(ctx, 1, 1); !errors.Is(err, ErrStockSoldOut) {
+		t.Fatalf("expected ErrStockSoldOut, got %v", err)
+	}
+
+	if err := c.Restore(ctx, 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if soldOut, _ := c.IsSoldOut(ctx, 1); soldOut {
+		t.Fatalf("expected sold out flag cleared after restore")
+	}
+	if left, err := c.Decrement(ctx, 1, 1); err != nil || left != 0 {
+		t.Fatalf("expected left=0, got %d err=%v", left, err)
+	}
+}
+
+func TestMemoryStockCounter_InsufficientStockDoesNotMarkSoldOut(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryStockCounter()
+	_ = c.Init(ctx, 1, 1)
+
+	// 剩 1 件时购买 2 件被拒绝，但不能影响只买 1 件的用户
+	if _, err := c.Decrement(ctx, 1, 2); !errors.Is(err, ErrStockSoldOut) {
+		t.Fatalf("expected ErrStockSoldOut, got %v", err)
+	}
+	if soldOut, _ := c.IsSoldOut(ctx, 1); soldOut {
+		t.Fatalf("expected no sold out flag while stock remains")
+	}
+	if left, err := c.Decrement(ctx, 1, 1); err != nil || left != 0 {
+		t.Fatalf("expected left=0, got %d err=%v", left, err)
+	}
+	if soldOut, _ := c.IsSoldOut(ctx, 1); !soldOut {
+		t.Fatalf("expected sold out flag after stock reaches zero")
+	}
+}
+
+func TestMemoryStockCounter_ConcurrentNoOversell(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryStockCounter()
+	const stock, buyers = 100, 1000
+	_ = c.Init(ctx, 42, stock)
+
+	var sold atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Decrement(ctx, 42, 1); err == nil {
+				sold.Add(1)
+			} else if !errors.Is(err, ErrStockSoldOut) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if sold.Load() != stock {
+		t.Fatalf("expected %d sold, got %d", stock, sold.Load())
+	}
+	if left, _ := c.Stock(ctx, 42); left != 0 {
+		t.Fatalf("expected 0 left, got %d", left)
+	}
+}
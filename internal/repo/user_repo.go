@@ -4,6 +4,7 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/danta7/go_mall/database"
@@ -13,12 +14,12 @@ import (
 // UserRepository 定义用户数据访问接口
 // 使用接口可以方便单元测试时进行模拟（mock）
 type UserRepository interface {
-	Create(user *domain.User) error
-	GetByID(id int64) (*domain.User, error)
-	GetByUsername(username string) (*domain.User, error)
-	GetByEmail(email string) (*domain.User, error)
-	Update(user *domain.User) error
-	Delete(id int64) error
+	Create(ctx context.Context, user *domain.User) error
+	GetByID(ctx context.Context, id int64) (*domain.User, error)
+	GetByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id int64) error
 }
 
 // userRepo 是 UserRepository 接口的数据库实现
@@ -33,13 +34,13 @@ func NewUserRepository(db *database.DB) UserRepository {
 
 // Create 创建新用户
 // 注意：这里不处理密码哈希，密码哈希应该在服务层处理
-func (r *userRepo) Create(user *domain.User) error {
+func (r *userRepo) Create(ctx context.Context, user *domain.User) error {
 	query := `
 		INSERT INTO users (username, email, password_hash, role, is_active)
 		VALUES (?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		user.Username,
 		user.Email,
 		user.PasswordHash,
@@ -61,14 +62,14 @@ func (r *userRepo) Create(user *domain.User) error {
 }
 
 // GetByID 根据ID查询用户
-func (r *userRepo) GetByID(id int64) (*domain.User, error) {
+func (r *userRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
 		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
 		FROM users WHERE id = ?
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -89,14 +90,14 @@ func (r *userRepo) GetByID(id int64) (*domain.User, error) {
 }
 
 // GetByUsername 根据用户名查询用户
-func (r *userRepo) GetByUsername(username string) (*domain.User, error) {
+func (r *userRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
 		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
 		FROM users WHERE username = ?
 	`
 
-	err := r.db.QueryRow(query, username).Scan(
+	err := r.db.QueryRowContext(ctx, query, username).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -117,14 +118,14 @@ func (r *userRepo) GetByUsername(username string) (*domain.User, error) {
 }
 
 // GetByEmail 根据邮箱查询用户
-func (r *userRepo) GetByEmail(email string) (*domain.User, error) {
+func (r *userRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
 		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
 		FROM users WHERE email = ?
 	`
 
-	err := r.db.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -145,14 +146,14 @@ func (r *userRepo) GetByEmail(email string) (*domain.User, error) {
 }
 
 // Update 更新用户信息
-func (r *userRepo) Update(user *domain.User) error {
+func (r *userRepo) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users 
 		SET username = ?, email = ?, password_hash = ?, role = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		user.Username,
 		user.Email,
 		user.PasswordHash,
@@ -168,10 +169,10 @@ func (r *userRepo) Update(user *domain.User) error {
 }
 
 // Delete 删除用户（软删除，设置is_active为false）
-func (r *userRepo) Delete(id int64) error {
+func (r *userRepo) Delete(ctx context.Context, id int64) error {
 	query := `UPDATE users SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
 
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("delete user: %w", err)
 	}
@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// WaitingRoom 定义秒杀虚拟排队室的存储接口
+// 用户进入排队时获得递增的序号；放行水位按固定速率推进，序号不超过水位的用户被放行。
+// 水位按“距上次推进经过的时间 × 速率”增长且不超过已排队人数，任意实例调用 Admit 都只会同步推进，
+// 多实例部署时总放行速率不会随实例数放大。
+type WaitingRoom interface {
+	// Join 用户进入排队，返回排队序号（从 1 开始）；重复进入返回原序号；ttl 为排队数据保留时长
+	Join(ctx context.Context, eventID, userID int64, ttl time.Duration) (int64, error)
+	// Admit 按 rate（人/秒）推进放行水位并返回当前水位；首次调用只记录起点
+	Admit(ctx context.Context, eventID int64, rate float64) (int64, error)
+	// Stats 返回已排队人数与上次推进后的放行水位（不推进）
+	Stats(ctx context.Context, eventID int64) (joined, admitted int64, err error)
+}
+
+// memoryWaitingRoom 是 WaitingRoom 的进程内实现
+type memoryWaitingRoom struct {
+	mu    sync.Mutex
+	rooms map[int64]*memoryRoom
+	now   func() time.Time
+}
+
+type memoryRoom struct {
+	seq       int64
+	users     map[int64]int64
+	admitted  float64
+	last      time.Time
+	expiresAt time.Time
+}
+
+// NewMemoryWaitingRoom 创建进程内排队室
+func NewMemoryWaitingRoom() WaitingRoom {
+	return &memoryWaitingRoom{rooms: make(map[int64]*memoryRoom), now: time.Now}
+}
+
+// room 返回未过期的排队室，create 为 true 时不存在则创建；调用方需持有锁
+func (w *memoryWaitingRoom) room(eventID int64, now time.Time, create bool) *memoryRoom {
+	r, ok := w.rooms[eventID]
+	if ok && now.After(r.expiresAt) {
+		delete(w.rooms, eventID)
+		ok = false
+	}
+	if !ok && create {
+		r = &memoryRoom{users: make(map[int64]int64)}
+		w.rooms[eventID] = r
+	}
+	return r
+}
+
+// Join 进入排队
+func (w *memoryWaitingRoom) Join(_ context.Context, eventID, userID int64, ttl time.Duration) (int64, error) {
+	now := w.now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r := w.room(eventID, now, true)
+	if seq, ok := r.users[userID]; ok {
+		return seq, nil
+	}
+	r.seq++
+	r.users[userID] = r.seq
+	r.expiresAt = now.Add(ttl)
+	return r.seq, nil
+}
+
+// Admit 推进放行水位
+func (w *memoryWaitingRoom) Admit(_ context.Context, eventID int64, rate float64) (int64, error) {
+	now := w.now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r := w.room(eventID, now, false)
+	if r == nil {
+		return 0, nil
+	}
+	if !r.last.IsZero() {
+		elapsed := max(now.Sub(r.last).Seconds(), 0)
+		r.admitted = math.Min(float64(r.seq), r.admitted+elapsed*rate)
+	}
+	r.last = now
+	return int64(r.admitted), nil
+}
+
+// Stats 返回排队统计
+func (w *memoryWaitingRoom) Stats(_ context.Context, eventID int64) (int64, int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r := w.room(eventID, w.now(), false)
+	if r == nil {
+		return 0, 0, nil
+	}
+	return r.seq, int64(r.admitted), nil
+}
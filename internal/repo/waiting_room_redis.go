@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// joinScript 进入排队：已有序号直接返回，否则分配新序号
+// KEYS[1] 用户->序号 Hash，KEYS[2] 排队状态 Hash；ARGV: user_id, ttl_ms
+var joinScript = redis.NewScript(`
+local seq = redis.call('HGET', KEYS[1], ARGV[1])
+if seq then
+	return tonumber(seq)
+end
+seq = redis.call('HINCRBY', KEYS[2], 'seq', 1)
+redis.call('HSET', KEYS[1], ARGV[1], seq)
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+return seq
+`)
+
+// admitScript 按服务器时间推进放行水位，水位不超过已排队人数
+// KEYS[1] 排队状态 Hash；ARGV: rate（人/秒）
+var admitScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local s = redis.call('HMGET', KEYS[1], 'seq', 'admitted', 'ts')
+local joined = tonumber(s[1]) or 0
+if joined == 0 then
+	return 0
+end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+local admitted = tonumber(s[2]) or 0
+local ts = tonumber(s[3])
+if ts then
+	admitted = math.min(joined, admitted + math.max(0, now - ts) * rate)
+end
+redis.call('HSET', KEYS[1], 'admitted', tostring(admitted), 'ts', tostring(now))
+return math.floor(admitted)
+`)
+
+// redisWaitingRoom 是 WaitingRoom 的 Redis 实现，多实例共享排队序号与放行水位
+type redisWaitingRoom struct {
+	rdb redis.UniversalClient
+}
+
+// NewRedisWaitingRoom 创建基于 Redis 的排队室
+func NewRedisWaitingRoom(rdb redis.UniversalClient) WaitingRoom {
+	return &redisWaitingRoom{rdb: rdb}
+}
+
+func waitingUsersKey(eventID int64) string { return fmt.Sprintf("spike:{%d}:wr:users", eventID) }
+func waitingStateKey(eventID int64) string { return fmt.Sprintf("spike:{%d}:wr:state", eventID) }
+
+// Join 进入排队
+func (w *redisWaitingRoom) Join(ctx context.Context, eventID, userID int64, ttl time.Duration) (int64, error) {
+	seq, err := joinScript.Run(ctx, w.rdb, []string{waitingUsersKey(eventID), waitingStateKey(eventID)}, userID, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("join waiting room: %w", err)
+	}
+	return seq, nil
+}
+
+// Admit 推进放行水位
+func (w *redisWaitingRoom) Admit(ctx context.Context, eventID int64, rate float64) (int64, error) {
+	admitted, err := admitScript.Run(ctx, w.rdb, []string{waitingStateKey(eventID)}, rate).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("admit waiting room: %w", err)
+	}
+	return admitted, nil
+}
+
+// Stats 返回排队统计
+func (w *redisWaitingRoom) Stats(ctx context.Context, eventID int64) (int64, int64, error) {
+	vals, err := w.rdb.HMGet(ctx, waitingStateKey(eventID), "seq", "admitted").Result()
+	if err != nil {
+		return 0, 0, fmt.Errorf("waiting room stats: %w", err)
+	}
+
+	// 字段不存在时 HMGET 返回 nil，按 0 处理
+	parse := func(v any) int64 {
+		s, _ := v.(string)
+		f, _ := strconv.ParseFloat(s, 64)
+		return int64(f)
+	}
+	return parse(vals[0]), parse(vals[1]), nil
+}
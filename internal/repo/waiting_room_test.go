@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryWaitingRoom_JoinIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	w := NewMemoryWaitingRoom()
+
+	a, _ := w.Join(ctx, 1, 100, time.Minute)
+	b, _ := w.Join(ctx, 1, 200, time.Minute)
+	again, _ := w.Join(ctx, 1, 100, time.Minute)
+	if a != 1 || b != 2 || again != 1 {
+		t.Fatalf("unexpected sequence: a=%d b=%d again=%d", a, b, again)
+	}
+	if other, _ := w.Join(ctx, 2, 100, time.Minute); other != 1 {
+		t.Fatalf("expected independent sequence per event, got %d", other)
+	}
+}
+
+func TestMemoryWaitingRoom_AdmitAtRate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	w := NewMemoryWaitingRoom().(*memoryWaitingRoom)
+	w.now = func() time.Time { return now }
+
+	for u := int64(1); u <= 50; u++ {
+		_, _ = w.Join(ctx, 1, u, time.Hour)
+	}
+
+	// 首次调用只记录起点
+	if n, _ := w.Admit(ctx, 1, 10); n != 0 {
+		t.Fatalf("expected 0 admitted on first call, got %d", n)
+	}
+
+	now = now.Add(2 * time.Second)
+	if n, _ := w.Admit(ctx, 1, 10); n != 20 {
+		t.Fatalf("expected 20 admitted after 2s, got %d", n)
+	}
+
+	// 水位不超过已排队人数
+	now = now.Add(time.Minute)
+	if n, _ := w.Admit(ctx, 1, 10); n != 50 {
+		t.Fatalf("expected admitted capped at 50, got %d", n)
+	}
+
+	joined, admitted, _ := w.Stats(ctx, 1)
+	if joined != 50 || admitted != 50 {
+		t.Fatalf("unexpected stats: joined=%d admitted=%d", joined, admitted)
+	}
+}
@@ -10,10 +10,22 @@ import (
 type Code int
 
 const (
-	CodeOK            Code = 0
-	CodeInternalError Code = 10000
-	CodeInvalidParam  Code = 10001
-	CodeTimeout       Code = 10002
+	CodeOK                  Code = 0
+	CodeInternalError       Code = 10000
+	CodeInvalidParam        Code = 10001
+	CodeTimeout             Code = 10002
+	CodeRateLimited         Code = 10003
+	CodeIdempotencyConflict Code = 10004 // 幂等键冲突：请求处理中或同键不同请求
+
+	// 秒杀业务错误码
+	CodeSpikeSoldOut     Code = 20001 // 活动已售罄
+	CodeSpikeNotActive   Code = 20002 // 活动未开始/已结束/已关闭
+	CodeSpikeDuplicate   Code = 20003 // 同一活动重复购买
+	CodeSpikeNotAdmitted Code = 20004 // 排队未放行或排队凭证无效
+	CodeSpikeChallenge   Code = 20005 // 防刷挑战未通过或下单凭证无效
+
+	// 订单业务错误码
+	CodeCouponUnavailable Code = 30001 // 优惠券不存在、已失效、未达门槛或次数用尽
 )
 
 type Response[T any] struct {
@@ -55,6 +67,16 @@ func HTTPStatusFromCode(code Code) int {
 		return http.StatusBadRequest
 	case CodeTimeout:
 		return http.StatusGatewayTimeout
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	case CodeIdempotencyConflict:
+		return http.StatusConflict
+	case CodeSpikeSoldOut, CodeSpikeDuplicate:
+		return http.StatusConflict
+	case CodeSpikeNotActive, CodeSpikeNotAdmitted, CodeSpikeChallenge:
+		return http.StatusForbidden
+	case CodeCouponUnavailable:
+		return http.StatusUnprocessableEntity
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrAddressNotFound      = errors.New("address not found")
+	ErrInvalidAddress       = errors.New("invalid address")
+	ErrAddressLimitExceeded = errors.New("address limit exceeded")
+	ErrAddressRequired      = errors.New("shipping address required")
+)
+
+// maxAddressesPerUser 每个用户最多保存的收货地址数量
+const maxAddressesPerUser = 20
+
+var (
+	phonePattern      = regexp.MustCompile(`^\+?[0-9][0-9-]{5,19}$`)
+	postalCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+)
+
+// AddressService 定义收货地址服务接口，所有操作限定在当前用户的地址内
+type AddressService interface {
+	// Create 创建地址；用户的第一个地址自动成为默认地址
+	Create(ctx context.Context, userID int64, req *domain.AddressRequest) (*domain.Address, error)
+	GetByID(ctx context.Context, userID, id int64) (*domain.Address, error)
+	List(ctx context.Context, userID int64) ([]*domain.Address, error)
+	// Update 整体覆盖地址；取消默认标记会被忽略（默认地址只能通过设置其他地址为默认来转移）
+	Update(ctx context.Context, userID, id int64, req *domain.AddressRequest) (*domain.Address, error)
+	// Delete 删除地址；删除默认地址时自动将另一个地址设为默认
+	Delete(ctx context.Context, userID, id int64) error
+	SetDefault(ctx context.Context, userID, id int64) (*domain.Address, error)
+	// Resolve 解析下单使用的收货地址快照：id 为 0 时使用默认地址，用户没有任何地址时返回 ErrAddressRequired
+	Resolve(ctx context.Context, userID, id int64) (*domain.OrderAddress, error)
+}
+
+type addressService struct {
+	addressRepo repo.AddressRepository
+	logger      *zap.Logger
+}
+
+// NewAddressService 创建收货地址服务实例
+func NewAddressService(addressRepo repo.AddressRepository, logger *zap.Logger) AddressService {
+	return &addressService{
+		addressRepo: addressRepo,
+		logger:      logger,
+	}
+}
+
+// Create 创建地址
+func (s *addressService) Create(ctx context.Context, userID int64, req *domain.AddressRequest) (*domain.Address, error) {
+	address := newAddress(userID, req)
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	count, err := s.addressRepo.CountByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to count addresses", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("count addresses: %w", err)
+	}
+	if count >= maxAddressesPerUser {
+		return nil, fmt.Errorf("%w: at most %d addresses per user", ErrAddressLimitExceeded, maxAddressesPerUser)
+	}
+	if count == 0 {
+		address.IsDefault = true
+	}
+
+	if err := s.addressRepo.Create(ctx, address); err != nil {
+		s.logger.Error("failed to create address", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("create address: %w", err)
+	}
+
+	return s.GetByID(ctx, userID, address.ID)
+}
+
+// GetByID 查询地址
+func (s *addressService) GetByID(ctx context.Context, userID, id int64) (*domain.Address, error) {
+	address, err := s.addressRepo.GetByID(ctx, userID, id)
+	if err != nil {
+		s.logger.Error("failed to get address", zap.Int64("address_id", id), zap.Error(err))
+		return nil, fmt.Errorf("get address: %w", err)
+	}
+	if address == nil {
+		return nil, ErrAddressNotFound
+	}
+	return address, nil
+}
+
+// List 查询地址列表
+func (s *addressService) List(ctx context.Context, userID int64) ([]*domain.Address, error) {
+	addresses, err := s.addressRepo.ListByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to list addresses", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("list addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+// Update 修改地址
+func (s *addressService) Update(ctx context.Context, userID, id int64, req *domain.AddressRequest) (*domain.Address, error) {
+	existing, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	address := newAddress(userID, req)
+	address.ID = id
+	address.IsDefault = address.IsDefault || existing.IsDefault
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	if err := s.addressRepo.Update(ctx, address); err != nil {
+		s.logger.Error("failed to update address", zap.Int64("address_id", id), zap.Error(err))
+		return nil, fmt.Errorf("update address: %w", err)
+	}
+
+	return s.GetByID(ctx, userID, id)
+}
+
+// Delete 删除地址
+func (s *addressService) Delete(ctx context.Context, userID, id int64) error {
+	if _, err := s.GetByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.addressRepo.Delete(ctx, userID, id); err != nil {
+		s.logger.Error("failed to delete address", zap.Int64("address_id", id), zap.Error(err))
+		return fmt.Errorf("delete address: %w", err)
+	}
+	return nil
+}
+
+// SetDefault 设为默认地址
+func (s *addressService) SetDefault(ctx context.Context, userID, id int64) (*domain.Address, error) {
+	address, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if address.IsDefault {
+		return address, nil
+	}
+
+	if err := s.addressRepo.SetDefault(ctx, userID, id); err != nil {
+		s.logger.Error("failed to set default address", zap.Int64("address_id", id), zap.Error(err))
+		return nil, fmt.Errorf("set default address: %w", err)
+	}
+
+	return s.GetByID(ctx, userID, id)
+}
+
+// Resolve 解析下单地址
+func (s *addressService) Resolve(ctx context.Context, userID, id int64) (*domain.OrderAddress, error) {
+	if id != 0 {
+		address, err := s.GetByID(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		return address.Snapshot(), nil
+	}
+
+	address, err := s.addressRepo.GetDefault(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to get default address", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("get default address: %w", err)
+	}
+	if address == nil {
+		return nil, ErrAddressRequired
+	}
+	return address.Snapshot(), nil
+}
+
+func newAddress(userID int64, req *domain.AddressRequest) *domain.Address {
+	return &domain.Address{
+		UserID:       userID,
+		ReceiverName: strings.TrimSpace(req.ReceiverName),
+		Phone:        strings.TrimSpace(req.Phone),
+		Province:     strings.TrimSpace(req.Province),
+		City:         strings.TrimSpace(req.City),
+		District:     strings.TrimSpace(req.District),
+		Detail:       strings.TrimSpace(req.Detail),
+		PostalCode:   strings.TrimSpace(req.PostalCode),
+		IsDefault:    req.IsDefault,
+	}
+}
+
+func validateAddress(a *domain.Address) error {
+	switch {
+	case a.ReceiverName == "" || utf8.RuneCountInString(a.ReceiverName) > 32:
+		return fmt.Errorf("%w: receiver_name is required and must be at most 32 characters", ErrInvalidAddress)
+	case !phonePattern.MatchString(a.Phone):
+		return fmt.Errorf("%w: phone must be 6-20 digits", ErrInvalidAddress)
+	case a.Province == "" || utf8.RuneCountInString(a.Province) > 32:
+		return fmt.Errorf("%w: province is required and must be at most 32 characters", ErrInvalidAddress)
+	case a.City == "" || utf8.RuneCountInString(a.City) > 32:
+		return fmt.Errorf("%w: city is required and must be at most 32 characters", ErrInvalidAddress)
+	case utf8.RuneCountInString(a.District) > 32:
+		return fmt.Errorf("%w: district must be at most 32 characters", ErrInvalidAddress)
+	case a.Detail == "" || utf8.RuneCountInString(a.Detail) > 255:
+		return fmt.Errorf("%w: detail is required and must be at most 255 characters", ErrInvalidAddress)
+	case a.PostalCode != "" && !postalCodePattern.MatchString(a.PostalCode):
+		return fmt.Errorf("%w: postal_code must be 6 digits", ErrInvalidAddress)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"go.uber.org/zap"
+)
+
+func testAddressRequest() *domain.AddressRequest {
+	return &domain.AddressRequest{
+		ReceiverName: "张三",
+		Phone:        "13800000000",
+		Province:     "浙江省",
+		City:         "杭州市",
+		Detail:       "文一西路 1 号",
+	}
+}
+
+func TestAddressService_Resolve(t *testing.T) {
+	ctx := context.Background()
+	s := NewAddressService(newFakeAddressRepo(), zap.NewNop())
+
+	if _, err := s.Resolve(ctx, 1, 0); !errors.Is(err, ErrAddressRequired) {
+		t.Fatalf("Resolve without any address = %v, want ErrAddressRequired", err)
+	}
+
+	first, err := s.Create(ctx, 1, testAddressRequest())
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !first.IsDefault {
+		t.Fatalf("expected the first address to become the default")
+	}
+	req := testAddressRequest()
+	req.Detail = "文二西路 2 号"
+	second, err := s.Create(ctx, 1, req)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if second.IsDefault {
+		t.Fatalf("expected the second address not to become the default")
+	}
+
+	got, err := s.Resolve(ctx, 1, 0)
+	if err != nil || got.Detail != first.Detail {
+		t.Fatalf("Resolve default = %+v, %v, want snapshot of %q", got, err, first.Detail)
+	}
+	got, err = s.Resolve(ctx, 1, second.ID)
+	if err != nil || got.Detail != second.Detail {
+		t.Fatalf("Resolve by id = %+v, %v, want snapshot of %q", got, err, second.Detail)
+	}
+	// 他人的地址按不存在处理
+	if _, err := s.Resolve(ctx, 2, second.ID); !errors.Is(err, ErrAddressNotFound) {
+		t.Fatalf("Resolve another user's address = %v, want ErrAddressNotFound", err)
+	}
+}
+
+func TestAddressService_CreateValidates(t *testing.T) {
+	s := NewAddressService(newFakeAddressRepo(), zap.NewNop())
+
+	req := testAddressRequest()
+	req.Phone = "abc"
+	if _, err := s.Create(context.Background(), 1, req); !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("Create with invalid phone = %v, want ErrInvalidAddress", err)
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+func TestValidateCoupon_RejectsCategoryScope(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &domain.Coupon{Code: "CAT10", Type: domain.CouponTypePercentage, Value: 10, CategoryIDs: []int64{3},
+		StartAt: start, EndAt: start.Add(24 * time.Hour)}
+	if err := validateCoupon(c); !errors.Is(err, ErrInvalidCoupon) {
+		t.Fatalf("validateCoupon with category_ids = %v, want ErrInvalidCoupon", err)
+	}
+
+	c.CategoryIDs = nil
+	c.ProductIDs = []int64{1}
+	if err := validateCoupon(c); err != nil {
+		t.Fatalf("validateCoupon with product_ids = %v, want nil", err)
+	}
+}
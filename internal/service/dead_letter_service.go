@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/mq"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrDeadLetterNotFound     = errors.New("dead letter not found")
+	ErrDeadLetterInvalidState = errors.New("dead letter already handled")
+)
+
+// DeadLetterService 定义死信管理服务接口
+type DeadLetterService interface {
+	// Record 保存重试耗尽的消息，签名与 mq.DeadLetterFunc 一致
+	Record(ctx context.Context, msg mq.Message, attempts int, cause error) error
+	GetByID(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, int, error)
+	// Replay 将死信重新投递到原主题，仅待处理的死信可以重放
+	Replay(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	// Discard 丢弃死信，仅待处理的死信可以丢弃
+	Discard(ctx context.Context, id int64) (*domain.DeadLetter, error)
+}
+
+type deadLetterService struct {
+	deadLetterRepo repo.DeadLetterRepository
+	publisher      mq.Publisher
+	logger         *zap.Logger
+}
+
+// NewDeadLetterService 创建死信管理服务实例
+func NewDeadLetterService(deadLetterRepo repo.DeadLetterRepository, publisher mq.Publisher, logger *zap.Logger) DeadLetterService {
+	return &deadLetterService{
+		deadLetterRepo: deadLetterRepo,
+		publisher:      publisher,
+		logger:         logger,
+	}
+}
+
+// Record 保存死信；消费 ctx 可能已结束，落库不受其取消影响
+func (s *deadLetterService) Record(ctx context.Context, msg mq.Message, attempts int, cause error) error {
+	dl := &domain.DeadLetter{
+		Topic:     msg.Topic,
+		MessageID: msg.ID,
+		Payload:   msg.Body,
+		Headers:   msg.Headers,
+		Attempts:  attempts,
+	}
+	if cause != nil {
+		dl.LastError = cause.Error()
+	}
+
+	if err := s.deadLetterRepo.Create(context.WithoutCancel(ctx), dl); err != nil {
+		s.logger.Error("failed to save dead letter",
+			zap.String("topic", msg.Topic),
+			zap.String("message_id", msg.ID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("save dead letter: %w", err)
+	}
+	return nil
+}
+
+// GetByID 查询死信详情
+func (s *deadLetterService) GetByID(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	dl, err := s.deadLetterRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get dead letter", zap.Int64("dead_letter_id", id), zap.Error(err))
+		return nil, fmt.Errorf("get dead letter: %w", err)
+	}
+
+	if dl == nil {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	return dl, nil
+}
+
+// List 分页查询死信
+func (s *deadLetterService) List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, int, error) {
+	items, total, err := s.deadLetterRepo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to list dead letters", zap.Error(err))
+		return nil, 0, fmt.Errorf("list dead letters: %w", err)
+	}
+	return items, total, nil
+}
+
+// Replay 重放死信
+// 先以条件更新占用状态，防止并发重放；投递失败时恢复为待处理
+func (s *deadLetterService) Replay(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	dl, err := s.transition(ctx, id, domain.DeadLetterStatusReplayed)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := mq.Message{ID: dl.MessageID, Topic: dl.Topic, Body: dl.Payload, Headers: dl.Headers}
+	if err := s.publisher.Publish(ctx, dl.Topic, msg); err != nil {
+		s.logger.Error("failed to replay dead letter", zap.Int64("dead_letter_id", id), zap.Error(err))
+		if _, revertErr := s.deadLetterRepo.UpdateStatus(context.WithoutCancel(ctx), id,
+			domain.DeadLetterStatusReplayed, domain.DeadLetterStatusDead); revertErr != nil {
+			s.logger.Error("failed to revert dead letter status", zap.Int64("dead_letter_id", id), zap.Error(revertErr))
+		}
+		return nil, fmt.Errorf("replay dead letter: %w", err)
+	}
+
+	s.logger.Info("dead letter replayed", zap.Int64("dead_letter_id", id), zap.String("topic", dl.Topic))
+	return dl, nil
+}
+
+// Discard 丢弃死信
+func (s *deadLetterService) Discard(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	dl, err := s.transition(ctx, id, domain.DeadLetterStatusDiscarded)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("dead letter discarded", zap.Int64("dead_letter_id", id), zap.String("topic", dl.Topic))
+	return dl, nil
+}
+
+// transition 将待处理的死信流转到目标状态
+func (s *deadLetterService) transition(ctx context.Context, id int64, to domain.DeadLetterStatus) (*domain.DeadLetter, error) {
+	dl, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.deadLetterRepo.UpdateStatus(ctx, id, domain.DeadLetterStatusDead, to)
+	if err != nil {
+		s.logger.Error("failed to update dead letter status", zap.Int64("dead_letter_id", id), zap.Error(err))
+		return nil, fmt.Errorf("update dead letter status: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: current status is %s", ErrDeadLetterInvalidState, dl.Status)
+	}
+
+	dl.Status = to
+	return dl, nil
+}
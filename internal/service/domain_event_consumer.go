@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/mq"
+	"go.uber.org/zap"
+)
+
+// 领域事件指标，通过 /debug/vars 暴露
+var (
+	orderCreatedTotal            = expvar.NewInt("order_created_total")
+	orderCreatedAmountTotal      = expvar.NewInt("order_created_amount_total")
+	spikeEventStatusChangedTotal = expvar.NewMap("spike_event_status_changed_total")
+)
+
+// DomainEventConsumer 消费 outbox relay 投递的领域事件（订单创建、秒杀活动状态变更），
+// 记录业务指标与日志。每个主题都必须有消费者：内存队列缓冲区写满后发布会阻塞，
+// 进而拖住 relay；AMQP 下无人消费的队列则会持续堆积。
+// 处理失败按 retry 策略重试，重试耗尽或消息无法解析时保存死信，可通过管理端重放。
+// 后续接入通知、搜索等下游时在此扩展处理逻辑。
+type DomainEventConsumer struct {
+	consumer    mq.Consumer
+	deadLetters DeadLetterService
+	retry       mq.RetryPolicy
+	logger      *zap.Logger
+}
+
+// NewDomainEventConsumer 创建领域事件消费者
+func NewDomainEventConsumer(consumer mq.Consumer, deadLetters DeadLetterService, retry mq.RetryPolicy, logger *zap.Logger) *DomainEventConsumer {
+	return &DomainEventConsumer{consumer: consumer, deadLetters: deadLetters, retry: retry, logger: logger}
+}
+
+// Run 启动各主题的消费并阻塞直到 ctx 结束；ctx 结束时返回 nil
+func (c *DomainEventConsumer) Run(ctx context.Context) error {
+	handlers := map[string]mq.Handler{
+		domain.TopicOrderCreated:            c.HandleOrderCreated,
+		domain.TopicSpikeEventStatusChanged: c.HandleSpikeEventStatusChanged,
+	}
+
+	errCh := make(chan error, len(handlers))
+	var wg sync.WaitGroup
+	for topic, handler := range handlers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.consumer.Consume(ctx, topic, mq.WithRetry(handler, c.retry, c.deadLetters.Record, c.logger)); err != nil && !errors.Is(err, context.Canceled) {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	return <-errCh
+}
+
+// HandleOrderCreated 处理订单创建事件
+func (c *DomainEventConsumer) HandleOrderCreated(_ context.Context, msg mq.Message) error {
+	var e domain.OrderCreatedEvent
+	if err := json.Unmarshal(msg.Body, &e); err != nil {
+		return mq.Permanent(fmt.Errorf("decode order created event: %w", err))
+	}
+
+	orderCreatedTotal.Add(1)
+	orderCreatedAmountTotal.Add(e.TotalAmount)
+	c.logger.Info("order created",
+		zap.String("message_id", msg.ID),
+		zap.Int64("order_id", e.OrderID),
+		zap.Int64("user_id", e.UserID),
+		zap.Int64("total_amount", e.TotalAmount),
+		zap.Int64("spike_event_id", e.SpikeEventID),
+	)
+	return nil
+}
+
+// HandleSpikeEventStatusChanged 处理秒杀活动状态变更事件
+// 热路径上的活动缓存只保留 spikeEventCacheTTL，状态变更无需主动失效
+func (c *DomainEventConsumer) HandleSpikeEventStatusChanged(_ context.Context, msg mq.Message) error {
+	var e domain.SpikeEventStatusChangedEvent
+	if err := json.Unmarshal(msg.Body, &e); err != nil {
+		return mq.Permanent(fmt.Errorf("decode spike event status changed event: %w", err))
+	}
+
+	spikeEventStatusChangedTotal.Add(string(e.Status), 1)
+	c.logger.Info("spike event status changed",
+		zap.String("message_id", msg.ID),
+		zap.Int64("event_id", e.EventID),
+		zap.String("status", string(e.Status)),
+	)
+	return nil
+}
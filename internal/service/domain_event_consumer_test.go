@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/mq"
+	"go.uber.org/zap"
+)
+
+func TestDomainEventConsumer_DrainsOutboxTopics(t *testing.T) {
+	broker := mq.NewMemoryBroker(1, zap.NewNop())
+	defer broker.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	deadLetters := &fakeDeadLetters{}
+	consumer := NewDomainEventConsumer(broker, deadLetters,
+		mq.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, zap.NewNop())
+	go func() { done <- consumer.Run(ctx) }()
+
+	before := orderCreatedTotal.Value()
+	body, _ := json.Marshal(domain.OrderCreatedEvent{OrderID: 1, UserID: 2, TotalAmount: 100})
+	statusBody, _ := json.Marshal(domain.SpikeEventStatusChangedEvent{EventID: 1, Status: domain.SpikeEventStatusClosed})
+
+	// 缓冲区仅为 1：没有消费者时第二次发布就会阻塞到超时
+	pubCtx, pubCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer pubCancel()
+	for i := 0; i < 5; i++ {
+		if err := broker.Publish(pubCtx, domain.TopicOrderCreated, mq.Message{Body: body}); err != nil {
+			t.Fatalf("publish order.created: %v", err)
+		}
+		if err := broker.Publish(pubCtx, domain.TopicSpikeEventStatusChanged, mq.Message{Body: statusBody}); err != nil {
+			t.Fatalf("publish spike.event.status_changed: %v", err)
+		}
+	}
+	// 无法解析的消息不重试，直接转入死信，不会阻塞后续消息
+	if err := broker.Publish(pubCtx, domain.TopicOrderCreated, mq.Message{ID: "bad", Body: []byte("{")}); err != nil {
+		t.Fatalf("publish invalid message: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for orderCreatedTotal.Value()-before < 5 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := orderCreatedTotal.Value() - before; got != 5 {
+		t.Fatalf("order created events consumed = %d, want 5", got)
+	}
+	for time.Now().Before(deadline) && deadLetters.count() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if deadLetters.count() != 1 || deadLetters.messages[0].ID != "bad" {
+		t.Fatalf("expected the invalid message to be dead-lettered, got %+v", deadLetters.messages)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+}
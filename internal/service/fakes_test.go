@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/mq"
+	"github.com/danta7/go_mall/internal/repo"
+)
+
+// fakeSpikeEventRepo 是 repo.SpikeEventRepository 的内存实现，仅供测试
+type fakeSpikeEventRepo struct {
+	mu     sync.Mutex
+	events map[int64]*domain.SpikeEvent
+}
+
+func newFakeSpikeEventRepo(events ...*domain.SpikeEvent) *fakeSpikeEventRepo {
+	r := &fakeSpikeEventRepo{events: make(map[int64]*domain.SpikeEvent)}
+	for _, e := range events {
+		r.events[e.ID] = e
+	}
+	return r
+}
+
+func (r *fakeSpikeEventRepo) Create(_ context.Context, event *domain.SpikeEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.ID = int64(len(r.events) + 1)
+	r.events[event.ID] = event
+	return nil
+}
+
+func (r *fakeSpikeEventRepo) GetByID(_ context.Context, id int64) (*domain.SpikeEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events[id], nil
+}
+
+func (r *fakeSpikeEventRepo) Update(_ context.Context, event *domain.SpikeEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[event.ID] = event
+	return nil
+}
+
+func (r *fakeSpikeEventRepo) UpdateStatus(_ context.Context, id int64, status domain.SpikeEventStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.events[id]; ok {
+		e.Status = status
+	}
+	return nil
+}
+
+func (r *fakeSpikeEventRepo) List(_ context.Context, _ domain.SpikeEventFilter) ([]*domain.SpikeEvent, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*domain.SpikeEvent
+	for _, e := range r.events {
+		list = append(list, e)
+	}
+	return list, len(list), nil
+}
+
+func (r *fakeSpikeEventRepo) ListActive(_ context.Context, now time.Time) ([]*domain.SpikeEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*domain.SpikeEvent
+	for _, e := range r.events {
+		if e.Status == domain.SpikeEventStatusScheduled && now.Before(e.EndAt) {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+// fakeSpikeOrderRepo 是 repo.SpikeOrderRepository 的内存实现，按 ticket 与 (用户, 活动) 保证唯一；
+// createErr 非空时 Create 直接返回该错误
+type fakeSpikeOrderRepo struct {
+	mu        sync.Mutex
+	byTicket  map[string]*domain.SpikeOrder
+	sold      map[int64]int64
+	createErr error
+	creates   int
+}
+
+func newFakeSpikeOrderRepo() *fakeSpikeOrderRepo {
+	return &fakeSpikeOrderRepo{byTicket: make(map[string]*domain.SpikeOrder), sold: make(map[int64]int64)}
+}
+
+func (r *fakeSpikeOrderRepo) Create(_ context.Context, order *domain.Order, spikeOrder *domain.SpikeOrder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.creates++
+	if r.createErr != nil {
+		return r.createErr
+	}
+	if _, ok := r.byTicket[spikeOrder.Ticket]; ok {
+		return repo.ErrDuplicateTicket
+	}
+	for _, so := range r.byTicket {
+		if so.UserID == spikeOrder.UserID && so.SpikeEventID == spikeOrder.SpikeEventID {
+			return repo.ErrDuplicateSpikeOrder
+		}
+	}
+	order.ID = int64(len(r.byTicket) + 1)
+	spikeOrder.ID, spikeOrder.OrderID = order.ID, order.ID
+	r.byTicket[spikeOrder.Ticket] = spikeOrder
+	r.sold[spikeOrder.SpikeEventID] += int64(spikeOrder.Quantity)
+	return nil
+}
+
+func (r *fakeSpikeOrderRepo) GetByTicket(_ context.Context, ticket string) (*domain.SpikeOrder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.byTicket[ticket], nil
+}
+
+func (r *fakeSpikeOrderRepo) SoldQuantity(_ context.Context, eventID int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sold[eventID], nil
+}
+
+// fakeCouponRepo 是 repo.CouponRepository 的内存实现，redemptions 记录 (优惠券, 用户) 的已使用次数
+type fakeCouponRepo struct {
+	mu          sync.Mutex
+	coupons     map[string]*domain.Coupon
+	redemptions map[[2]int64]int
+}
+
+func newFakeCouponRepo(coupons ...*domain.Coupon) *fakeCouponRepo {
+	r := &fakeCouponRepo{coupons: make(map[string]*domain.Coupon), redemptions: make(map[[2]int64]int)}
+	for _, c := range coupons {
+		r.coupons[c.Code] = c
+	}
+	return r
+}
+
+func (r *fakeCouponRepo) Create(_ context.Context, coupon *domain.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.coupons[coupon.Code]; ok {
+		return repo.ErrDuplicateCouponCode
+	}
+	coupon.ID = int64(len(r.coupons) + 1)
+	r.coupons[coupon.Code] = coupon
+	return nil
+}
+
+func (r *fakeCouponRepo) GetByID(_ context.Context, id int64) (*domain.Coupon, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.coupons {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeCouponRepo) GetByCode(_ context.Context, code string) (*domain.Coupon, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.coupons[code], nil
+}
+
+func (r *fakeCouponRepo) UpdateStatus(_ context.Context, id int64, status domain.CouponStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.coupons {
+		if c.ID == id {
+			c.Status = status
+		}
+	}
+	return nil
+}
+
+func (r *fakeCouponRepo) List(_ context.Context, _ domain.CouponFilter) ([]*domain.Coupon, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*domain.Coupon
+	for _, c := range r.coupons {
+		list = append(list, c)
+	}
+	return list, len(list), nil
+}
+
+func (r *fakeCouponRepo) CountUserRedemptions(_ context.Context, couponID, userID int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.redemptions[[2]int64{couponID, userID}], nil
+}
+
+// fakeAddressRepo 是 repo.AddressRepository 的内存实现
+type fakeAddressRepo struct {
+	mu        sync.Mutex
+	addresses map[int64]*domain.Address
+	nextID    int64
+}
+
+func newFakeAddressRepo() *fakeAddressRepo {
+	return &fakeAddressRepo{addresses: make(map[int64]*domain.Address)}
+}
+
+func (r *fakeAddressRepo) Create(_ context.Context, address *domain.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	address.ID = r.nextID
+	r.addresses[address.ID] = address
+	return nil
+}
+
+func (r *fakeAddressRepo) GetByID(_ context.Context, userID, id int64) (*domain.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.addresses[id]; ok && a.UserID == userID {
+		return a, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeAddressRepo) GetDefault(_ context.Context, userID int64) (*domain.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.addresses {
+		if a.UserID == userID && a.IsDefault {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeAddressRepo) ListByUser(_ context.Context, userID int64) ([]*domain.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*domain.Address
+	for _, a := range r.addresses {
+		if a.UserID == userID {
+			list = append(list, a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].IsDefault && !list[j].IsDefault })
+	return list, nil
+}
+
+func (r *fakeAddressRepo) CountByUser(ctx context.Context, userID int64) (int, error) {
+	list, err := r.ListByUser(ctx, userID)
+	return len(list), err
+}
+
+func (r *fakeAddressRepo) Update(_ context.Context, address *domain.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addresses[address.ID] = address
+	return nil
+}
+
+func (r *fakeAddressRepo) Delete(_ context.Context, userID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.addresses[id]; ok && a.UserID == userID {
+		delete(r.addresses, id)
+	}
+	return nil
+}
+
+func (r *fakeAddressRepo) SetDefault(_ context.Context, userID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.addresses {
+		if a.UserID == userID {
+			a.IsDefault = a.ID == id
+		}
+	}
+	return nil
+}
+
+// fakeOrderRepo 是 repo.OrderRepository 的内存实现
+type fakeOrderRepo struct {
+	orders map[int64]*domain.Order
+}
+
+func (r *fakeOrderRepo) GetByID(_ context.Context, id int64) (*domain.Order, error) {
+	return r.orders[id], nil
+}
+
+// fakeDeadLetters 记录写入的死信，只实现 Record；err 非空时写入失败
+type fakeDeadLetters struct {
+	DeadLetterService
+	mu       sync.Mutex
+	messages []mq.Message
+	err      error
+}
+
+func (d *fakeDeadLetters) Record(_ context.Context, msg mq.Message, _ int, _ error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+	d.messages = append(d.messages, msg)
+	return nil
+}
+
+func (d *fakeDeadLetters) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.messages)
+}
+
+// fakePublisher 记录发布的消息；err 非空时发布失败
+type fakePublisher struct {
+	mu       sync.Mutex
+	messages []mq.Message
+	err      error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, topic string, msg mq.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	msg.Topic = topic
+	p.messages = append(p.messages, msg)
+	return nil
+}
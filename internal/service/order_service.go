@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"github.com/danta7/go_mall/internal/shipping"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrCouponUnavailable = errors.New("coupon unavailable")
+)
+
+// maxCouponsPerOrder 单笔订单最多使用的优惠券数量：一张商品券（满减或折扣）+ 一张免运费券
+const maxCouponsPerOrder = 2
+
+// OrderService 定义订单服务接口
+type OrderService interface {
+	// Price 计算订单价格明细：商品小计、运费与逐张优惠券的优惠金额。
+	// 运费由运费计算器按商品与收货地址计算。
+	// 优惠券不存在、不在有效期、未达门槛、次数用尽或不可叠加时返回 ErrCouponUnavailable。
+	// 次数在计价时只做预校验，最终以创建订单事务内的核销为准。
+	Price(ctx context.Context, userID int64, req *domain.PriceOrderRequest) (*domain.OrderPricing, error)
+	// GetByID 查询订单详情（含优惠明细与收货地址），只能查询本人的订单
+	GetByID(ctx context.Context, userID, orderID int64) (*domain.Order, error)
+}
+
+type orderService struct {
+	orderRepo  repo.OrderRepository
+	couponRepo repo.CouponRepository
+	shipping   shipping.Calculator
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewOrderService 创建订单服务实例
+func NewOrderService(orderRepo repo.OrderRepository, couponRepo repo.CouponRepository, shipping shipping.Calculator,
+	logger *zap.Logger) OrderService {
+	return &orderService{
+		orderRepo:  orderRepo,
+		couponRepo: couponRepo,
+		shipping:   shipping,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Price 计算订单价格
+// 业务规则：
+//  1. 商品券（满减/折扣）与免运费券各最多一张，同一张券不能重复使用
+//  2. 先计算商品券，再计算免运费券；商品券优惠不超过适用商品小计
+//  3. 优惠金额为 0 的券视为不适用，避免无意义地消耗使用次数
+func (s *orderService) Price(ctx context.Context, userID int64, req *domain.PriceOrderRequest) (*domain.OrderPricing, error) {
+	pricing := &domain.OrderPricing{ShippingFee: s.shipping.Fee(req.Items, req.Address), Discounts: []*domain.OrderDiscount{}}
+	for _, item := range req.Items {
+		pricing.SubtotalAmount += item.Amount()
+	}
+
+	coupons, err := s.loadCoupons(ctx, userID, req.CouponCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range coupons {
+		if subtotal := c.EligibleSubtotal(req.Items); subtotal < c.MinSpend {
+			return nil, fmt.Errorf("%w: %s requires a minimum spend of %s on eligible items", ErrCouponUnavailable, c.Code, formatYuan(c.MinSpend))
+		}
+		amount := c.Discount(req.Items, pricing.ShippingFee)
+		if amount <= 0 {
+			return nil, fmt.Errorf("%w: %s is not applicable to this order", ErrCouponUnavailable, c.Code)
+		}
+		pricing.DiscountAmount += amount
+		pricing.Discounts = append(pricing.Discounts, &domain.OrderDiscount{
+			CouponID:    c.ID,
+			CouponCode:  c.Code,
+			Type:        c.Type,
+			Amount:      amount,
+			Description: describeCoupon(c),
+		})
+	}
+
+	pricing.TotalAmount = pricing.SubtotalAmount + pricing.ShippingFee - pricing.DiscountAmount
+	return pricing, nil
+}
+
+// loadCoupons 查询并校验优惠券，按商品券在前、免运费券在后排序
+func (s *orderService) loadCoupons(ctx context.Context, userID int64, codes []string) ([]*domain.Coupon, error) {
+	if len(codes) > maxCouponsPerOrder {
+		return nil, fmt.Errorf("%w: at most %d coupons per order", ErrCouponUnavailable, maxCouponsPerOrder)
+	}
+
+	now := s.now()
+	var merchandise, shipping *domain.Coupon
+	for _, raw := range codes {
+		code := normalizeCouponCode(raw)
+		c, err := s.couponRepo.GetByCode(ctx, code)
+		if err != nil {
+			s.logger.Error("failed to get coupon", zap.String("code", code), zap.Error(err))
+			return nil, fmt.Errorf("get coupon: %w", err)
+		}
+		switch {
+		case c == nil:
+			return nil, fmt.Errorf("%w: %s not found", ErrCouponUnavailable, code)
+		case !c.IsValidAt(now):
+			return nil, fmt.Errorf("%w: %s is disabled or out of its validity period", ErrCouponUnavailable, code)
+		case c.TotalLimit > 0 && c.UsedCount >= c.TotalLimit:
+			return nil, fmt.Errorf("%w: %s has been used up", ErrCouponUnavailable, code)
+		}
+
+		if c.PerUserLimit > 0 {
+			used, err := s.couponRepo.CountUserRedemptions(ctx, c.ID, userID)
+			if err != nil {
+				s.logger.Error("failed to count coupon redemptions", zap.Int64("coupon_id", c.ID), zap.Error(err))
+				return nil, fmt.Errorf("count coupon redemptions: %w", err)
+			}
+			if used >= c.PerUserLimit {
+				return nil, fmt.Errorf("%w: %s usage limit reached", ErrCouponUnavailable, code)
+			}
+		}
+
+		slot := &merchandise
+		if c.Type == domain.CouponTypeFreeShipping {
+			slot = &shipping
+		}
+		if *slot != nil {
+			return nil, fmt.Errorf("%w: %s cannot be combined with %s", ErrCouponUnavailable, code, (*slot).Code)
+		}
+		*slot = c
+	}
+
+	var coupons []*domain.Coupon
+	for _, c := range []*domain.Coupon{merchandise, shipping} {
+		if c != nil {
+			coupons = append(coupons, c)
+		}
+	}
+	return coupons, nil
+}
+
+// GetByID 查询订单详情
+func (s *orderService) GetByID(ctx context.Context, userID, orderID int64) (*domain.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("failed to get order", zap.Int64("order_id", orderID), zap.Error(err))
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+
+	// 他人的订单按不存在处理
+	if order == nil || order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// describeCoupon 生成优惠明细的说明文字
+func describeCoupon(c *domain.Coupon) string {
+	var rule string
+	switch c.Type {
+	case domain.CouponTypeFixedAmount:
+		rule = "立减 " + formatYuan(c.Value) + " 元"
+	case domain.CouponTypePercentage:
+		rule = fmt.Sprintf("减免 %d%%", c.Value)
+		if c.MaxDiscount > 0 {
+			rule += "，最多 " + formatYuan(c.MaxDiscount) + " 元"
+		}
+	case domain.CouponTypeFreeShipping:
+		rule = "免运费"
+	}
+	if c.MinSpend > 0 {
+		rule = "满 " + formatYuan(c.MinSpend) + " 元" + rule
+	}
+	if c.Name != "" {
+		return c.Name + "（" + rule + "）"
+	}
+	return rule
+}
+
+// formatYuan 将以分为单位的金额格式化为元
+func formatYuan(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/shipping"
+	"go.uber.org/zap"
+)
+
+func testCoupon(id int64, code string, typ domain.CouponType, value int64) *domain.Coupon {
+	now := time.Now()
+	return &domain.Coupon{ID: id, Code: code, Type: typ, Value: value, Status: domain.CouponStatusActive,
+		StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour)}
+}
+
+func testPriceRequest(codes ...string) *domain.PriceOrderRequest {
+	return &domain.PriceOrderRequest{
+		Items:       []*domain.PricingItem{{ProductID: 1, Quantity: 2, UnitPrice: 5000}},
+		CouponCodes: codes,
+	}
+}
+
+func TestOrderService_Price(t *testing.T) {
+	minus := testCoupon(1, "MINUS500", domain.CouponTypeFixedAmount, 500)
+	minus.MinSpend = 10000
+	coupons := newFakeCouponRepo(minus, testCoupon(2, "FREESHIP", domain.CouponTypeFreeShipping, 0))
+	s := NewOrderService(&fakeOrderRepo{}, coupons, shipping.NewFlat(1000), zap.NewNop())
+
+	// 免运费券写在前面，仍按商品券在前计算
+	pricing, err := s.Price(context.Background(), 1, testPriceRequest("FREESHIP", "MINUS500"))
+	if err != nil {
+		t.Fatalf("Price: %v", err)
+	}
+	if pricing.SubtotalAmount != 10000 || pricing.ShippingFee != 1000 || pricing.DiscountAmount != 1500 || pricing.TotalAmount != 9500 {
+		t.Fatalf("unexpected pricing: %+v", pricing)
+	}
+	if len(pricing.Discounts) != 2 || pricing.Discounts[0].CouponCode != "MINUS500" || pricing.Discounts[1].CouponCode != "FREESHIP" {
+		t.Fatalf("unexpected discounts order: %+v", pricing.Discounts)
+	}
+}
+
+func TestOrderService_Price_CouponUnavailable(t *testing.T) {
+	highMin := testCoupon(1, "HIGHMIN", domain.CouponTypeFixedAmount, 500)
+	highMin.MinSpend = 20000
+	usedUp := testCoupon(2, "USEDUP", domain.CouponTypeFixedAmount, 500)
+	usedUp.TotalLimit, usedUp.UsedCount = 10, 10
+	perUser := testCoupon(3, "ONCE", domain.CouponTypeFixedAmount, 500)
+	perUser.PerUserLimit = 1
+	expired := testCoupon(4, "EXPIRED", domain.CouponTypeFixedAmount, 500)
+	expired.EndAt = time.Now().Add(-time.Minute)
+	coupons := newFakeCouponRepo(highMin, usedUp, perUser, expired,
+		testCoupon(5, "MINUS500", domain.CouponTypeFixedAmount, 500), testCoupon(6, "PCT10", domain.CouponTypePercentage, 10))
+	coupons.redemptions[[2]int64{perUser.ID, 1}] = 1
+	s := NewOrderService(&fakeOrderRepo{}, coupons, shipping.NewFlat(1000), zap.NewNop())
+
+	cases := map[string][]string{
+		"not found":         {"NOPE"},
+		"min spend not met": {"HIGHMIN"},
+		"used up":           {"USEDUP"},
+		"per user limit":    {"ONCE"},
+		"expired":           {"EXPIRED"},
+		"two merchandise":   {"MINUS500", "PCT10"},
+		"too many coupons":  {"MINUS500", "PCT10", "FREESHIP"},
+	}
+	for name, codes := range cases {
+		if _, err := s.Price(context.Background(), 1, testPriceRequest(codes...)); !errors.Is(err, ErrCouponUnavailable) {
+			t.Fatalf("%s: Price = %v, want ErrCouponUnavailable", name, err)
+		}
+	}
+}
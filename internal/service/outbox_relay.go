@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/mq"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+const (
+	// outboxClaimLease 认领租约时长：relay 崩溃后，被认领的消息在租约到期后可由其他实例重新认领
+	outboxClaimLease = 30 * time.Second
+	// outboxBaseBackoff/outboxMaxBackoff 投递失败后的指数退避区间
+	outboxBaseBackoff = time.Second
+	outboxMaxBackoff  = 5 * time.Minute
+	// outboxPublishTimeout 单条消息的发布超时，避免队列阻塞时 relay 停滞
+	outboxPublishTimeout = 5 * time.Second
+)
+
+// outbox 指标，通过 /debug/vars 暴露
+var (
+	outboxPending     = expvar.NewInt("outbox_pending")
+	outboxSentTotal   = expvar.NewInt("outbox_sent_total")
+	outboxFailedTotal = expvar.NewInt("outbox_failed_total")
+)
+
+// OutboxRelay 周期性扫描本地消息表，将待投递消息发布到消息队列
+// 投递语义为至少一次：发布成功但标记失败时消息会被重复投递，消费者需按 message_id 去重。
+type OutboxRelay struct {
+	outboxRepo   repo.OutboxRepository
+	publisher    mq.Publisher
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	logger       *zap.Logger
+}
+
+// NewOutboxRelay 创建本地消息投递器
+func NewOutboxRelay(outboxRepo repo.OutboxRepository, publisher mq.Publisher, pollInterval time.Duration,
+	batchSize, maxAttempts int, logger *zap.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo:   outboxRepo,
+		publisher:    publisher,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		maxAttempts:  maxAttempts,
+		logger:       logger,
+	}
+}
+
+// Run 按 pollInterval 循环投递，阻塞直到 ctx 结束；一批消息占满 batchSize 时立即继续下一批
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// 积压时连续处理，直到某一批未占满 batchSize
+		for ctx.Err() == nil {
+			if r.RelayOnce(ctx) < r.batchSize {
+				break
+			}
+		}
+		r.refreshPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce 认领并投递一批消息，返回本批认领的消息数
+func (r *OutboxRelay) RelayOnce(ctx context.Context) int {
+	msgs, err := r.outboxRepo.Claim(ctx, r.batchSize, outboxClaimLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error("failed to claim outbox messages", zap.Error(err))
+		}
+		return 0
+	}
+
+	for _, m := range msgs {
+		r.deliver(ctx, m)
+	}
+	return len(msgs)
+}
+
+// deliver 投递单条消息并记录结果；状态回写不受 ctx 取消影响，避免已发布的消息因关闭而重复投递
+func (r *OutboxRelay) deliver(ctx context.Context, m *domain.OutboxMessage) {
+	bg := context.WithoutCancel(ctx)
+
+	pubCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	err := r.publisher.Publish(pubCtx, m.Topic, mq.Message{ID: m.MessageID, Topic: m.Topic, Body: m.Payload})
+	cancel()
+	if err == nil {
+		if err := r.outboxRepo.MarkSent(bg, m.ID); err != nil {
+			r.logger.Error("failed to mark outbox message sent", zap.Int64("outbox_id", m.ID), zap.Error(err))
+			return
+		}
+		outboxSentTotal.Add(1)
+		return
+	}
+
+	attempts := m.Attempts + 1
+	if attempts >= r.maxAttempts {
+		r.logger.Error("outbox message delivery failed permanently",
+			zap.Int64("outbox_id", m.ID),
+			zap.String("topic", m.Topic),
+			zap.Int("attempts", attempts),
+			zap.Error(err),
+		)
+		if markErr := r.outboxRepo.MarkFailed(bg, m.ID, err.Error()); markErr != nil {
+			r.logger.Error("failed to mark outbox message failed", zap.Int64("outbox_id", m.ID), zap.Error(markErr))
+			return
+		}
+		outboxFailedTotal.Add(1)
+		return
+	}
+
+	delay := outboxBackoff(attempts)
+	r.logger.Warn("outbox message delivery failed, will retry",
+		zap.Int64("outbox_id", m.ID),
+		zap.String("topic", m.Topic),
+		zap.Int("attempts", attempts),
+		zap.Duration("retry_in", delay),
+		zap.Error(err),
+	)
+	if markErr := r.outboxRepo.MarkRetry(bg, m.ID, err.Error(), delay); markErr != nil {
+		r.logger.Error("failed to mark outbox message retry", zap.Int64("outbox_id", m.ID), zap.Error(markErr))
+	}
+}
+
+// refreshPending 更新积压量指标
+func (r *OutboxRelay) refreshPending(ctx context.Context) {
+	n, err := r.outboxRepo.CountPending(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Warn("failed to count pending outbox messages", zap.Error(err))
+		}
+		return
+	}
+	outboxPending.Set(n)
+}
+
+// outboxBackoff 返回第 attempts 次失败后的重试延迟：1s、2s、4s ... 封顶 5m
+func outboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 20 {
+		return outboxMaxBackoff
+	}
+	d := outboxBaseBackoff << (attempts - 1)
+	if d > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+// spikeEventCacheTTL 活动信息本地缓存时间，秒杀热路径不直接查询 MySQL
+const spikeEventCacheTTL = 2 * time.Second
+
+type cachedSpikeEvent struct {
+	event     *domain.SpikeEvent
+	expiresAt time.Time
+}
+
+// spikeEventCache 活动信息的短时本地缓存，供秒杀热路径上的各服务共用
+type spikeEventCache struct {
+	eventRepo repo.SpikeEventRepository
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu    sync.RWMutex
+	items map[int64]cachedSpikeEvent
+}
+
+func newSpikeEventCache(eventRepo repo.SpikeEventRepository, logger *zap.Logger) *spikeEventCache {
+	return &spikeEventCache{
+		eventRepo: eventRepo,
+		logger:    logger,
+		now:       time.Now,
+		items:     make(map[int64]cachedSpikeEvent),
+	}
+}
+
+// get 读取活动信息，优先使用本地缓存；活动不存在时返回 ErrSpikeEventNotFound
+func (c *spikeEventCache) get(ctx context.Context, eventID int64) (*domain.SpikeEvent, error) {
+	now := c.now()
+
+	c.mu.RLock()
+	cached, ok := c.items[eventID]
+	c.mu.RUnlock()
+	if ok && now.Before(cached.expiresAt) {
+		if cached.event == nil {
+			return nil, ErrSpikeEventNotFound
+		}
+		return cached.event, nil
+	}
+
+	// 缓存已将查询限制为每个活动每个 TTL 一次，读主库的开销有限；
+	// 走从库则管理端刚排期或修改的活动在复制追上前会以旧状态、旧库存参与下单
+	event, err := c.eventRepo.GetByID(database.WithPrimary(ctx), eventID)
+	if err != nil {
+		c.logger.Error("failed to get spike event", zap.Int64("event_id", eventID), zap.Error(err))
+		return nil, fmt.Errorf("get spike event: %w", err)
+	}
+
+	// 不存在的活动同样缓存，防止无效ID穿透到数据库
+	c.mu.Lock()
+	c.items[eventID] = cachedSpikeEvent{event: event, expiresAt: now.Add(spikeEventCacheTTL)}
+	c.mu.Unlock()
+
+	if event == nil {
+		return nil, ErrSpikeEventNotFound
+	}
+	return event, nil
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSpikeEventNotFound     = errors.New("spike event not found")
+	ErrInvalidSpikeEvent      = errors.New("invalid spike event")
+	ErrSpikeEventInvalidState = errors.New("spike event state does not allow this operation")
+)
+
+// SpikeEventService 定义秒杀活动管理服务接口
+type SpikeEventService interface {
+	Create(ctx context.Context, req *domain.CreateSpikeEventRequest) (*domain.SpikeEvent, error)
+	Update(ctx context.Context, id int64, req *domain.UpdateSpikeEventRequest) (*domain.SpikeEvent, error)
+	Schedule(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	Close(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	List(ctx context.Context, filter domain.SpikeEventFilter) ([]*domain.SpikeEvent, int, error)
+	// ListPublic 返回对外展示的活动：即将开始与进行中
+	ListPublic(ctx context.Context) ([]*domain.SpikeEvent, error)
+	// WarmUpStock 将未结束的已排期活动库存预热到库存计数器（已存在的计数器不会被覆盖）
+	WarmUpStock(ctx context.Context) error
+}
+
+type spikeEventService struct {
+	eventRepo      repo.SpikeEventRepository
+	spikeOrderRepo repo.SpikeOrderRepository
+	stockCounter   repo.StockCounter
+	logger         *zap.Logger
+	now            func() time.Time
+}
+
+// NewSpikeEventService 创建秒杀活动服务实例
+func NewSpikeEventService(eventRepo repo.SpikeEventRepository, spikeOrderRepo repo.SpikeOrderRepository, stockCounter repo.StockCounter, logger *zap.Logger) SpikeEventService {
+	return &spikeEventService{
+		eventRepo:      eventRepo,
+		spikeOrderRepo: spikeOrderRepo,
+		stockCounter:   stockCounter,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// Create 创建秒杀活动
+// 业务规则：
+// 1. 新建活动处于草稿状态，需要显式排期后才会对外展示
+// 2. 结束时间必须晚于开始时间，库存与限购必须为正数
+func (s *spikeEventService) Create(ctx context.Context, req *domain.CreateSpikeEventRequest) (*domain.SpikeEvent, error) {
+	event := &domain.SpikeEvent{
+		ProductID:    req.ProductID,
+		Title:        strings.TrimSpace(req.Title),
+		SpikePrice:   req.SpikePrice,
+		TotalStock:   req.TotalStock,
+		PerUserLimit: req.PerUserLimit,
+		StartAt:      req.StartAt,
+		EndAt:        req.EndAt,
+		Status:       domain.SpikeEventStatusDraft,
+	}
+	if event.PerUserLimit == 0 {
+		event.PerUserLimit = 1
+	}
+
+	if err := validateSpikeEvent(event); err != nil {
+		return nil, err
+	}
+
+	if err := s.eventRepo.Create(ctx, event); err != nil {
+		s.logger.Error("failed to create spike event", zap.Error(err))
+		return nil, fmt.Errorf("create spike event: %w", err)
+	}
+
+	s.logger.Info("spike event created", zap.Int64("event_id", event.ID), zap.Int64("product_id", event.ProductID))
+	return event, nil
+}
+
+// Update 编辑秒杀活动
+// 业务规则：只有草稿或尚未开始的活动可以编辑，进行中/已结束/已关闭的活动不可修改
+func (s *spikeEventService) Update(ctx context.Context, id int64, req *domain.UpdateSpikeEventRequest) (*domain.SpikeEvent, error) {
+	// 读-改-写：基于主库的最新状态校验，避免从库延迟导致误判
+	ctx = database.WithPrimary(ctx)
+	event, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch event.Phase(s.now()) {
+	case domain.SpikeEventPhaseDraft, domain.SpikeEventPhaseUpcoming:
+		// ok
+	default:
+		return nil, fmt.Errorf("%w: only draft or upcoming events can be edited", ErrSpikeEventInvalidState)
+	}
+
+	if req.Title != nil {
+		event.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.SpikePrice != nil {
+		event.SpikePrice = *req.SpikePrice
+	}
+	if req.TotalStock != nil {
+		event.TotalStock = *req.TotalStock
+	}
+	if req.PerUserLimit != nil {
+		event.PerUserLimit = *req.PerUserLimit
+	}
+	if req.StartAt != nil {
+		event.StartAt = *req.StartAt
+	}
+	if req.EndAt != nil {
+		event.EndAt = *req.EndAt
+	}
+
+	if err := validateSpikeEvent(event); err != nil {
+		return nil, err
+	}
+	if event.Status == domain.SpikeEventStatusScheduled && !event.StartAt.After(s.now()) {
+		return nil, fmt.Errorf("%w: start_at of a scheduled event must be in the future", ErrInvalidSpikeEvent)
+	}
+
+	if err := s.eventRepo.Update(ctx, event); err != nil {
+		s.logger.Error("failed to update spike event", zap.Int64("event_id", id), zap.Error(err))
+		return nil, fmt.Errorf("update spike event: %w", err)
+	}
+
+	// 已排期但未开始的活动尚无售出，直接以新库存覆盖计数器
+	if event.Status == domain.SpikeEventStatusScheduled {
+		if err := s.stockCounter.Reset(ctx, event.ID, int64(event.TotalStock)); err != nil {
+			s.logger.Error("failed to reset spike stock", zap.Int64("event_id", id), zap.Error(err))
+			return nil, fmt.Errorf("reset spike stock: %w", err)
+		}
+	}
+
+	s.logger.Info("spike event updated", zap.Int64("event_id", id))
+	return event, nil
+}
+
+// Schedule 发布（排期）草稿活动，发布后活动在 start_at 自动开始
+func (s *spikeEventService) Schedule(ctx context.Context, id int64) (*domain.SpikeEvent, error) {
+	ctx = database.WithPrimary(ctx)
+	event, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Status != domain.SpikeEventStatusDraft {
+		return nil, fmt.Errorf("%w: only draft events can be scheduled", ErrSpikeEventInvalidState)
+	}
+	if !event.EndAt.After(s.now()) {
+		return nil, fmt.Errorf("%w: event has already ended", ErrSpikeEventInvalidState)
+	}
+
+	// 先预热库存再发布，保证活动对外可见时计数器已就绪
+	if err := s.stockCounter.Reset(ctx, event.ID, int64(event.TotalStock)); err != nil {
+		s.logger.Error("failed to warm up spike stock", zap.Int64("event_id", id), zap.Error(err))
+		return nil, fmt.Errorf("warm up spike stock: %w", err)
+	}
+
+	return s.transition(ctx, event, domain.SpikeEventStatusScheduled)
+}
+
+// Close 关闭活动，关闭后不再对外展示也不可下单
+func (s *spikeEventService) Close(ctx context.Context, id int64) (*domain.SpikeEvent, error) {
+	ctx = database.WithPrimary(ctx)
+	event, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Status == domain.SpikeEventStatusClosed {
+		return nil, fmt.Errorf("%w: event is already closed", ErrSpikeEventInvalidState)
+	}
+
+	return s.transition(ctx, event, domain.SpikeEventStatusClosed)
+}
+
+// GetByID 根据ID获取秒杀活动
+// 管理端创建或修改后通常立即查看详情（read-your-writes），读主库；列表查询仍走从库
+func (s *spikeEventService) GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error) {
+	event, err := s.eventRepo.GetByID(database.WithPrimary(ctx), id)
+	if err != nil {
+		s.logger.Error("failed to get spike event", zap.Int64("event_id", id), zap.Error(err))
+		return nil, fmt.Errorf("get spike event: %w", err)
+	}
+
+	if event == nil {
+		return nil, ErrSpikeEventNotFound
+	}
+
+	return event, nil
+}
+
+// List 分页查询秒杀活动（管理端）
+func (s *spikeEventService) List(ctx context.Context, filter domain.SpikeEventFilter) ([]*domain.SpikeEvent, int, error) {
+	events, total, err := s.eventRepo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to list spike events", zap.Error(err))
+		return nil, 0, fmt.Errorf("list spike events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// ListPublic 返回即将开始与进行中的活动
+func (s *spikeEventService) ListPublic(ctx context.Context) ([]*domain.SpikeEvent, error) {
+	events, err := s.eventRepo.ListActive(ctx, s.now())
+	if err != nil {
+		s.logger.Error("failed to list active spike events", zap.Error(err))
+		return nil, fmt.Errorf("list active spike events: %w", err)
+	}
+
+	return events, nil
+}
+
+// WarmUpStock 启动时从数据库预热库存计数器，剩余库存 = 活动分配库存 - 已落库售出件数
+func (s *spikeEventService) WarmUpStock(ctx context.Context) error {
+	// 库存计数以主库为准，刚排期的活动可能尚未同步到从库
+	ctx = database.WithPrimary(ctx)
+	events, err := s.eventRepo.ListActive(ctx, s.now())
+	if err != nil {
+		s.logger.Error("failed to list active spike events", zap.Error(err))
+		return fmt.Errorf("list active spike events: %w", err)
+	}
+
+	for _, e := range events {
+		sold, err := s.spikeOrderRepo.SoldQuantity(ctx, e.ID)
+		if err != nil {
+			s.logger.Error("failed to get sold quantity", zap.Int64("event_id", e.ID), zap.Error(err))
+			return fmt.Errorf("get sold quantity: %w", err)
+		}
+
+		if err := s.stockCounter.Init(ctx, e.ID, max(int64(e.TotalStock)-sold, 0)); err != nil {
+			s.logger.Error("failed to warm up spike stock", zap.Int64("event_id", e.ID), zap.Error(err))
+			return fmt.Errorf("warm up spike stock: %w", err)
+		}
+	}
+
+	s.logger.Info("spike stock warmed up", zap.Int("events", len(events)))
+	return nil
+}
+
+func (s *spikeEventService) transition(ctx context.Context, event *domain.SpikeEvent, status domain.SpikeEventStatus) (*domain.SpikeEvent, error) {
+	if err := s.eventRepo.UpdateStatus(ctx, event.ID, status); err != nil {
+		s.logger.Error("failed to update spike event status",
+			zap.Int64("event_id", event.ID),
+			zap.String("status", string(status)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("update spike event status: %w", err)
+	}
+
+	s.logger.Info("spike event status changed",
+		zap.Int64("event_id", event.ID),
+		zap.String("from", string(event.Status)),
+		zap.String("to", string(status)),
+	)
+	event.Status = status
+	return event, nil
+}
+
+// validateSpikeEvent 校验活动字段的业务约束
+func validateSpikeEvent(e *domain.SpikeEvent) error {
+	switch {
+	case e.ProductID <= 0:
+		return fmt.Errorf("%w: product_id is required", ErrInvalidSpikeEvent)
+	case utf8.RuneCountInString(e.Title) > 128:
+		return fmt.Errorf("%w: title must be at most 128 characters", ErrInvalidSpikeEvent)
+	case e.SpikePrice <= 0:
+		return fmt.Errorf("%w: spike_price must be > 0", ErrInvalidSpikeEvent)
+	case e.TotalStock <= 0:
+		return fmt.Errorf("%w: total_stock must be > 0", ErrInvalidSpikeEvent)
+	case e.PerUserLimit <= 0:
+		return fmt.Errorf("%w: per_user_limit must be > 0", ErrInvalidSpikeEvent)
+	case e.PerUserLimit > e.TotalStock:
+		return fmt.Errorf("%w: per_user_limit cannot exceed total_stock", ErrInvalidSpikeEvent)
+	case e.StartAt.IsZero() || e.EndAt.IsZero():
+		return fmt.Errorf("%w: start_at and end_at are required", ErrInvalidSpikeEvent)
+	case !e.EndAt.After(e.StartAt):
+		return fmt.Errorf("%w: end_at must be after start_at", ErrInvalidSpikeEvent)
+	}
+	return nil
+}
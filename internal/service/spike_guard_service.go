@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSpikeChallengeInvalid = errors.New("invalid or expired spike challenge")
+	ErrSpikeChallengeFailed  = errors.New("spike challenge solution rejected")
+	ErrSpikeTokenInvalid     = errors.New("invalid or used spike purchase token")
+	ErrSpikePathInvalid      = errors.New("invalid spike purchase path")
+)
+
+// SpikeGuardConfig 防刷配置
+type SpikeGuardConfig struct {
+	// Difficulty 工作量证明难度（前导零比特数）
+	Difficulty int
+	// ChallengeTTL 挑战有效期
+	ChallengeTTL time.Duration
+	// TokenTTL 一次性下单凭证有效期
+	TokenTTL time.Duration
+	// Secret 挑战与隐藏地址的签名密钥
+	Secret string
+}
+
+// SpikeGuardService 定义秒杀防刷服务接口
+// 下单前需完成两步：
+//  1. 获取并解答工作量证明挑战，换取一次性下单凭证（脚本需付出计算成本，且凭证不可重复使用）
+//  2. 活动开始后获取隐藏下单地址（按用户签名，提前猜测或他人泄露的地址均无效）
+type SpikeGuardService interface {
+	// Challenge 签发挑战，仅未开始或进行中的活动可以签发
+	Challenge(ctx context.Context, userID, eventID int64) (*domain.SpikeChallenge, error)
+	// Solve 校验挑战答案并签发一次性下单凭证；每个挑战只能兑换一次
+	Solve(ctx context.Context, userID, eventID int64, req *domain.SolveSpikeChallengeRequest) (*domain.SpikePurchaseToken, error)
+	// PurchasePath 返回隐藏下单地址，活动开始前返回 ErrSpikeEventNotLive
+	PurchasePath(ctx context.Context, userID, eventID int64) (*domain.SpikePurchasePath, error)
+	// Verify 校验隐藏地址并消耗一次性下单凭证
+	Verify(ctx context.Context, userID, eventID int64, path, token string) error
+}
+
+type spikeGuardService struct {
+	events *spikeEventCache
+	keys   repo.OneTimeKeyStore
+	cfg    SpikeGuardConfig
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewSpikeGuardService 创建秒杀防刷服务实例
+func NewSpikeGuardService(eventRepo repo.SpikeEventRepository, keys repo.OneTimeKeyStore, cfg SpikeGuardConfig, logger *zap.Logger) SpikeGuardService {
+	return &spikeGuardService{
+		events: newSpikeEventCache(eventRepo, logger),
+		keys:   keys,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Challenge 签发挑战：<event_id>.<user_id>.<nonce>.<过期时间>.<难度>.<签名>
+func (s *spikeGuardService) Challenge(ctx context.Context, userID, eventID int64) (*domain.SpikeChallenge, error) {
+	event, err := s.events.get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	switch event.Phase(s.now()) {
+	case domain.SpikeEventPhaseUpcoming, domain.SpikeEventPhaseLive:
+		// ok
+	default:
+		return nil, ErrSpikeEventNotLive
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate challenge nonce: %w", err)
+	}
+
+	expiresAt := s.now().Add(s.cfg.ChallengeTTL)
+	payload := fmt.Sprintf("%d.%d.%s.%d.%d", eventID, userID, hex.EncodeToString(nonce), expiresAt.Unix(), s.cfg.Difficulty)
+	return &domain.SpikeChallenge{
+		Challenge:  payload + "." + s.mac(payload),
+		Difficulty: s.cfg.Difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Solve 校验挑战答案
+func (s *spikeGuardService) Solve(ctx context.Context, userID, eventID int64, req *domain.SolveSpikeChallengeRequest) (*domain.SpikePurchaseToken, error) {
+	nonce, expiresAt, difficulty, err := s.parseChallenge(userID, eventID, req.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(req.Challenge + req.Solution))
+	if leadingZeroBits(sum[:]) < difficulty {
+		return nil, ErrSpikeChallengeFailed
+	}
+
+	// 挑战防重放：每个挑战只能兑换一次凭证
+	fresh, err := s.keys.Add(ctx, "spike:challenge:"+nonce, expiresAt.Sub(s.now())+time.Minute)
+	if err != nil {
+		s.logger.Error("failed to mark spike challenge used", zap.Int64("event_id", eventID), zap.Error(err))
+		return nil, fmt.Errorf("mark spike challenge: %w", err)
+	}
+	if !fresh {
+		return nil, ErrSpikeChallengeInvalid
+	}
+
+	token := uuid.NewString()
+	if _, err := s.keys.Add(ctx, tokenKey(eventID, userID, token), s.cfg.TokenTTL); err != nil {
+		s.logger.Error("failed to save spike purchase token", zap.Int64("event_id", eventID), zap.Error(err))
+		return nil, fmt.Errorf("save spike purchase token: %w", err)
+	}
+
+	return &domain.SpikePurchaseToken{Token: token, ExpiresAt: s.now().Add(s.cfg.TokenTTL)}, nil
+}
+
+// PurchasePath 返回隐藏下单地址
+func (s *spikeGuardService) PurchasePath(ctx context.Context, userID, eventID int64) (*domain.SpikePurchasePath, error) {
+	event, err := s.events.get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.IsLive(s.now()) {
+		return nil, ErrSpikeEventNotLive
+	}
+
+	path := s.path(eventID, userID)
+	return &domain.SpikePurchasePath{
+		Path: path,
+		URL:  fmt.Sprintf("/api/v1/spike/events/%d/purchase/%s", eventID, path),
+	}, nil
+}
+
+// Verify 校验隐藏地址与一次性凭证；地址先于凭证校验，错误地址不会消耗凭证
+func (s *spikeGuardService) Verify(ctx context.Context, userID, eventID int64, path, token string) error {
+	if !hmac.Equal([]byte(path), []byte(s.path(eventID, userID))) {
+		return ErrSpikePathInvalid
+	}
+	if token == "" {
+		return ErrSpikeTokenInvalid
+	}
+
+	ok, err := s.keys.Consume(ctx, tokenKey(eventID, userID, token))
+	if err != nil {
+		s.logger.Error("failed to consume spike purchase token", zap.Int64("event_id", eventID), zap.Error(err))
+		return fmt.Errorf("consume spike purchase token: %w", err)
+	}
+	if !ok {
+		return ErrSpikeTokenInvalid
+	}
+	return nil
+}
+
+// parseChallenge 校验挑战签名、归属与有效期，返回 nonce、过期时间与难度
+func (s *spikeGuardService) parseChallenge(userID, eventID int64, challenge string) (string, time.Time, int, error) {
+	i := strings.LastIndexByte(challenge, '.')
+	if i < 0 {
+		return "", time.Time{}, 0, ErrSpikeChallengeInvalid
+	}
+	payload, sig := challenge[:i], challenge[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.mac(payload))) {
+		return "", time.Time{}, 0, ErrSpikeChallengeInvalid
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 5 || parts[0] != strconv.FormatInt(eventID, 10) || parts[1] != strconv.FormatInt(userID, 10) {
+		return "", time.Time{}, 0, ErrSpikeChallengeInvalid
+	}
+	exp, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return "", time.Time{}, 0, ErrSpikeChallengeInvalid
+	}
+	expiresAt := time.Unix(exp, 0)
+	if s.now().After(expiresAt) {
+		return "", time.Time{}, 0, ErrSpikeChallengeInvalid
+	}
+	difficulty, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return "", time.Time{}, 0, ErrSpikeChallengeInvalid
+	}
+	return parts[2], expiresAt, difficulty, nil
+}
+
+// path 计算用户在活动中的隐藏下单路径
+func (s *spikeGuardService) path(eventID, userID int64) string {
+	return s.mac(fmt.Sprintf("path:%d:%d", eventID, userID))
+}
+
+func (s *spikeGuardService) mac(data string) string {
+	h := hmac.New(sha256.New, []byte(s.cfg.Secret))
+	h.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:16])
+}
+
+func tokenKey(eventID, userID int64, token string) string {
+	return fmt.Sprintf("spike:ptoken:%d:%d:%s", eventID, userID, token)
+}
+
+// leadingZeroBits 统计字节序列的前导零比特数
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if c != 0 {
+			return n + bits.LeadingZeros8(c)
+		}
+		n += 8
+	}
+	return n
+}
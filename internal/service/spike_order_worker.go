@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/mq"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+// HeaderStockReleased 标记消息对应的预减库存与去重标记已被补偿释放（死信重放时据此重新占用）
+const HeaderStockReleased = "x-stock-released"
+
+// spikeReplayMarkTTL 死信重放成功后重新写入去重标记的保留时长
+const spikeReplayMarkTTL = 24 * time.Hour
+
+// SpikeOrderWorker 消费秒杀下单消息并异步创建订单
+// 以消息中的 ticket 作为幂等键：同一消息被重复投递时只会落库一次。
+// 处理结果写入结果存储，供客户端按 ticket 查询。
+// 处理失败按 retry 策略退避重试，重试耗尽后写入死信，并回补预减库存、清除去重标记，
+// 避免库存泄漏与用户被误拦截。
+type SpikeOrderWorker struct {
+	consumer       mq.Consumer
+	spikeOrderRepo repo.SpikeOrderRepository
+	orderService   OrderService
+	stockCounter   repo.StockCounter
+	dedupe         repo.SpikeDedupe
+	resultStore    repo.SpikeResultStore
+	deadLetters    DeadLetterService
+	retry          mq.RetryPolicy
+	concurrency    int
+	logger         *zap.Logger
+}
+
+// NewSpikeOrderWorker 创建秒杀下单消费者，concurrency 为并发消费的 goroutine 数
+func NewSpikeOrderWorker(consumer mq.Consumer, spikeOrderRepo repo.SpikeOrderRepository, orderService OrderService, stockCounter repo.StockCounter,
+	dedupe repo.SpikeDedupe, resultStore repo.SpikeResultStore, deadLetters DeadLetterService, retry mq.RetryPolicy, concurrency int,
+	logger *zap.Logger) *SpikeOrderWorker {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &SpikeOrderWorker{
+		consumer:       consumer,
+		spikeOrderRepo: spikeOrderRepo,
+		orderService:   orderService,
+		stockCounter:   stockCounter,
+		dedupe:         dedupe,
+		resultStore:    resultStore,
+		deadLetters:    deadLetters,
+		retry:          retry,
+		concurrency:    concurrency,
+		logger:         logger,
+	}
+}
+
+// Run 启动消费并阻塞直到 ctx 结束；ctx 结束时返回 nil
+func (w *SpikeOrderWorker) Run(ctx context.Context) error {
+	handler := mq.WithRetry(w.Handle, w.retry, w.deadLetter, w.logger)
+
+	errCh := make(chan error, w.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.consumer.Consume(ctx, TopicSpikeOrderCreate, handler); err != nil && !errors.Is(err, context.Canceled) {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	return <-errCh
+}
+
+// Handle 处理一条秒杀下单消息；返回错误表示可重试，格式错误的消息以 mq.Permanent 标记
+func (w *SpikeOrderWorker) Handle(ctx context.Context, msg mq.Message) error {
+	var m domain.SpikeOrderMessage
+	if err := json.Unmarshal(msg.Body, &m); err != nil {
+		return mq.Permanent(fmt.Errorf("decode spike order message: %w", err))
+	}
+	released := msg.Headers[HeaderStockReleased] != ""
+
+	// 重复投递的消息可能已落库并核销了优惠券，须先于计价判断，避免被误判为优惠券不可用
+	existing, err := w.spikeOrderRepo.GetByTicket(ctx, m.Ticket)
+	if err != nil {
+		return fmt.Errorf("get spike order by ticket: %w", err)
+	}
+	if existing != nil {
+		w.logger.Info("spike order already created, skip duplicate message", zap.String("ticket", m.Ticket))
+		w.setResult(ctx, &m, domain.SpikeTicketStatusSucceeded, existing.OrderID, "", nil)
+		return nil
+	}
+
+	// 受理到落库之间优惠券可能被停用或用尽：按业务失败处理，释放库存让给其他用户
+	pricing, err := w.orderService.Price(ctx, m.UserID, spikePriceRequest(m.ProductID, m.SpikePrice, m.Quantity, m.Address, m.CouponCodes))
+	if err != nil {
+		if errors.Is(err, ErrCouponUnavailable) {
+			w.reject(ctx, &m, released, err.Error())
+			return nil
+		}
+		return fmt.Errorf("price spike order: %w", err)
+	}
+
+	order := &domain.Order{
+		UserID: m.UserID,
+		Status: domain.OrderStatusPendingPayment,
+		Items: []*domain.OrderItem{{
+			ProductID: m.ProductID,
+			Quantity:  m.Quantity,
+			Price:     m.SpikePrice,
+		}},
+		Address: m.Address,
+	}
+	order.ApplyPricing(pricing)
+	spikeOrder := &domain.SpikeOrder{
+		SpikeEventID: m.EventID,
+		UserID:       m.UserID,
+		Ticket:       m.Ticket,
+		Quantity:     m.Quantity,
+	}
+
+	if err := w.spikeOrderRepo.Create(ctx, order, spikeOrder); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrDuplicateTicket):
+			w.logger.Info("spike order already created, skip duplicate message", zap.String("ticket", m.Ticket))
+			existing, getErr := w.spikeOrderRepo.GetByTicket(ctx, m.Ticket)
+			if getErr != nil || existing == nil {
+				w.logger.Error("failed to load existing spike order", zap.String("ticket", m.Ticket), zap.Error(getErr))
+				return nil
+			}
+			w.setResult(ctx, &m, domain.SpikeTicketStatusSucceeded, existing.OrderID, "", nil)
+			return nil
+		case errors.Is(err, repo.ErrDuplicateSpikeOrder):
+			// 缓存标记丢失（如 Redis 重启）时由唯一约束兜底：回补本次预减的库存（已补偿过的除外），保留用户的已购买标记
+			w.logger.Warn("duplicate spike purchase rejected by database",
+				zap.String("ticket", m.Ticket),
+				zap.Int64("event_id", m.EventID),
+				zap.Int64("user_id", m.UserID),
+			)
+			if !released {
+				if restoreErr := w.stockCounter.Restore(ctx, m.EventID, int64(m.Quantity)); restoreErr != nil {
+					w.logger.Error("failed to restore spike stock", zap.Int64("event_id", m.EventID), zap.Error(restoreErr))
+				}
+			}
+			w.setResult(ctx, &m, domain.SpikeTicketStatusFailed, 0, "already purchased", nil)
+			return nil
+		case errors.Is(err, repo.ErrSpikeStockExhausted):
+			w.logger.Warn("spike stock exhausted on persist", zap.String("ticket", m.Ticket), zap.Int64("event_id", m.EventID))
+			w.setResult(ctx, &m, domain.SpikeTicketStatusSoldOut, 0, "sold out", nil)
+			return nil
+		case errors.Is(err, repo.ErrCouponExhausted), errors.Is(err, repo.ErrCouponUserLimitReached):
+			w.logger.Warn("spike order coupon rejected on persist", zap.String("ticket", m.Ticket), zap.Error(err))
+			w.reject(ctx, &m, released, err.Error())
+			return nil
+		}
+
+		// 结果保持 queued，由重试或死信处理决定终态
+		return fmt.Errorf("create spike order: %w", err)
+	}
+	if released {
+		w.reacquire(ctx, &m)
+	}
+	w.setResult(ctx, &m, domain.SpikeTicketStatusSucceeded, order.ID, "", pricing)
+
+	w.logger.Info("spike order created",
+		zap.String("ticket", m.Ticket),
+		zap.Int64("order_id", order.ID),
+		zap.Int64("event_id", m.EventID),
+		zap.Int64("user_id", m.UserID),
+	)
+	return nil
+}
+
+// deadLetter 重试耗尽后保存死信并执行补偿
+// 死信保存失败时不补偿：消息会重新入队并再次处理，此时补偿可能与后续成功下单重复
+func (w *SpikeOrderWorker) deadLetter(ctx context.Context, msg mq.Message, attempts int, cause error) error {
+	headers := make(map[string]string, len(msg.Headers)+1)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderStockReleased] = "1"
+	msg.Headers = headers
+	if err := w.deadLetters.Record(ctx, msg, attempts, cause); err != nil {
+		return err
+	}
+
+	var m domain.SpikeOrderMessage
+	if err := json.Unmarshal(msg.Body, &m); err == nil {
+		w.compensate(context.WithoutCancel(ctx), &m, "create order failed")
+	}
+	return nil
+}
+
+// reject 以业务原因拒绝下单：回补预减库存（已补偿过的除外）、清除去重标记，并将结果标记为失败
+func (w *SpikeOrderWorker) reject(ctx context.Context, m *domain.SpikeOrderMessage, released bool, reason string) {
+	if released {
+		w.setResult(ctx, m, domain.SpikeTicketStatusFailed, 0, reason, nil)
+		return
+	}
+	w.compensate(ctx, m, reason)
+}
+
+// compensate 下单落库失败的补偿：回补预减库存、清除去重标记，并将结果标记为失败
+// 补偿失败只记录日志，残留的偏差由库存对账任务修正
+func (w *SpikeOrderWorker) compensate(ctx context.Context, m *domain.SpikeOrderMessage, reason string) {
+	if err := w.stockCounter.Restore(ctx, m.EventID, int64(m.Quantity)); err != nil {
+		w.logger.Error("failed to restore spike stock",
+			zap.String("ticket", m.Ticket),
+			zap.Int64("event_id", m.EventID),
+			zap.Error(err),
+		)
+	}
+	if err := w.dedupe.Unmark(ctx, m.EventID, m.UserID); err != nil {
+		w.logger.Error("failed to unmark spike buyer",
+			zap.String("ticket", m.Ticket),
+			zap.Int64("event_id", m.EventID),
+			zap.Int64("user_id", m.UserID),
+			zap.Error(err),
+		)
+	}
+	w.setResult(ctx, m, domain.SpikeTicketStatusFailed, 0, reason, nil)
+
+	w.logger.Warn("spike order compensated",
+		zap.String("ticket", m.Ticket),
+		zap.Int64("event_id", m.EventID),
+		zap.Int64("user_id", m.UserID),
+		zap.Int("quantity", m.Quantity),
+	)
+}
+
+// reacquire 已补偿的消息重放成功后重新占用库存与去重标记
+// 失败只记录日志：订单已落库，计数器偏差由库存对账任务修正
+func (w *SpikeOrderWorker) reacquire(ctx context.Context, m *domain.SpikeOrderMessage) {
+	if _, err := w.stockCounter.Decrement(ctx, m.EventID, int64(m.Quantity)); err != nil {
+		w.logger.Warn("failed to reacquire spike stock after replay",
+			zap.String("ticket", m.Ticket),
+			zap.Int64("event_id", m.EventID),
+			zap.Error(err),
+		)
+	}
+	if _, err := w.dedupe.Mark(ctx, m.EventID, m.UserID, spikeReplayMarkTTL); err != nil {
+		w.logger.Warn("failed to mark spike buyer after replay",
+			zap.String("ticket", m.Ticket),
+			zap.Int64("event_id", m.EventID),
+			zap.Error(err),
+		)
+	}
+}
+
+// setResult 更新 ticket 处理结果；结果存储失败只记录日志，不影响消息确认
+func (w *SpikeOrderWorker) setResult(ctx context.Context, m *domain.SpikeOrderMessage, status domain.SpikeTicketStatus, orderID int64,
+	reason string, pricing *domain.OrderPricing) {
+	result := &domain.SpikeOrderResult{
+		Ticket:    m.Ticket,
+		EventID:   m.EventID,
+		UserID:    m.UserID,
+		Status:    status,
+		OrderID:   orderID,
+		Reason:    reason,
+		Pricing:   pricing,
+		UpdatedAt: time.Now(),
+	}
+	if err := w.resultStore.Set(ctx, result); err != nil {
+		w.logger.Error("failed to save spike order result",
+			zap.String("ticket", m.Ticket),
+			zap.String("status", string(status)),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/mq"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+// workerHarness 模拟 Purchase 受理后的状态：库存已预减、去重标记已写入、结果为 queued
+type workerHarness struct {
+	*spikeHarness
+	spikeOrders *fakeSpikeOrderRepo
+	deadLetters *fakeDeadLetters
+	worker      *SpikeOrderWorker
+}
+
+func newWorkerHarness(t *testing.T) *workerHarness {
+	t.Helper()
+	h := &workerHarness{spikeHarness: newSpikeHarness(t, 10), spikeOrders: newFakeSpikeOrderRepo(), deadLetters: &fakeDeadLetters{}}
+	h.worker = NewSpikeOrderWorker(mq.NewMemoryBroker(0, zap.NewNop()), h.spikeOrders, h.orders, h.counter, h.dedupe, h.results, h.deadLetters,
+		mq.RetryPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, 1, zap.NewNop())
+	return h
+}
+
+// queue 走一遍受理流程，返回投递给消费者的消息
+func (h *workerHarness) queue(t *testing.T, coupons ...string) mq.Message {
+	t.Helper()
+	if _, err := h.service.Purchase(context.Background(), testBuyerID, testEventID,
+		&domain.SpikePurchaseRequest{CouponCodes: coupons}); err != nil {
+		t.Fatalf("Purchase: %v", err)
+	}
+	return h.publisher.messages[len(h.publisher.messages)-1]
+}
+
+func (h *workerHarness) result(t *testing.T, msg mq.Message) *domain.SpikeOrderResult {
+	t.Helper()
+	result, err := h.results.Get(context.Background(), msg.ID)
+	if err != nil || result == nil {
+		t.Fatalf("get result: %+v, %v", result, err)
+	}
+	return result
+}
+
+func TestSpikeOrderWorker_Handle_CreatesOrderOnce(t *testing.T) {
+	ctx := context.Background()
+	h := newWorkerHarness(t)
+	msg := h.queue(t, "MINUS500")
+
+	if err := h.worker.Handle(ctx, msg); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	result := h.result(t, msg)
+	if result.Status != domain.SpikeTicketStatusSucceeded || result.OrderID == 0 || result.Pricing == nil || result.Pricing.DiscountAmount != 500 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// 重复投递：按 ticket 命中已落库的订单，不再计价或落库
+	h.coupons.coupons["MINUS500"].Status = domain.CouponStatusDisabled
+	if err := h.worker.Handle(ctx, msg); err != nil {
+		t.Fatalf("Handle duplicate: %v", err)
+	}
+	if h.spikeOrders.creates != 1 {
+		t.Fatalf("Create called %d times, want 1", h.spikeOrders.creates)
+	}
+	if again := h.result(t, msg); again.Status != domain.SpikeTicketStatusSucceeded || again.OrderID != result.OrderID {
+		t.Fatalf("unexpected result after duplicate delivery: %+v", again)
+	}
+	if h.stock(t) != 9 {
+		t.Fatalf("stock = %d, want 9", h.stock(t))
+	}
+}
+
+func TestSpikeOrderWorker_Handle_RejectsUnavailableCoupon(t *testing.T) {
+	ctx := context.Background()
+	h := newWorkerHarness(t)
+	msg := h.queue(t, "MINUS500")
+	// 受理之后优惠券被停用
+	h.coupons.coupons["MINUS500"].Status = domain.CouponStatusDisabled
+
+	if err := h.worker.Handle(ctx, msg); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if result := h.result(t, msg); result.Status != domain.SpikeTicketStatusFailed {
+		t.Fatalf("status = %s, want failed", result.Status)
+	}
+	if h.spikeOrders.creates != 0 {
+		t.Fatalf("expected no order to be persisted")
+	}
+	h.assertReleased(t, 10, testBuyerID)
+}
+
+func TestSpikeOrderWorker_Handle_RejectReplayedMessageDoesNotRestoreTwice(t *testing.T) {
+	ctx := context.Background()
+	h := newWorkerHarness(t)
+	msg := h.queue(t, "MINUS500")
+	h.coupons.coupons["MINUS500"].Status = domain.CouponStatusDisabled
+
+	// 死信补偿时已回补库存，重放后再被拒绝不能重复回补
+	if err := h.worker.deadLetter(ctx, msg, 1, errors.New("db down")); err != nil {
+		t.Fatalf("deadLetter: %v", err)
+	}
+	replayed := h.deadLetters.messages[0]
+	if err := h.worker.Handle(ctx, replayed); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if h.stock(t) != 10 {
+		t.Fatalf("stock = %d, want 10", h.stock(t))
+	}
+}
+
+func TestSpikeOrderWorker_Handle_PersistErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stock exhausted", func(t *testing.T) {
+		h := newWorkerHarness(t)
+		msg := h.queue(t)
+		h.spikeOrders.createErr = repo.ErrSpikeStockExhausted
+		if err := h.worker.Handle(ctx, msg); err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+		if result := h.result(t, msg); result.Status != domain.SpikeTicketStatusSoldOut {
+			t.Fatalf("status = %s, want sold_out", result.Status)
+		}
+	})
+
+	t.Run("duplicate spike order", func(t *testing.T) {
+		h := newWorkerHarness(t)
+		msg := h.queue(t)
+		h.spikeOrders.createErr = repo.ErrDuplicateSpikeOrder
+		if err := h.worker.Handle(ctx, msg); err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+		if result := h.result(t, msg); result.Status != domain.SpikeTicketStatusFailed {
+			t.Fatalf("status = %s, want failed", result.Status)
+		}
+		// 回补本次预减的库存，但保留用户的已购买标记
+		if h.stock(t) != 10 {
+			t.Fatalf("stock = %d, want 10", h.stock(t))
+		}
+		if marked, _ := h.dedupe.Mark(ctx, testEventID, testBuyerID, time.Minute); marked {
+			t.Fatalf("expected the buyer mark to be kept")
+		}
+	})
+
+	t.Run("transient error is retried", func(t *testing.T) {
+		h := newWorkerHarness(t)
+		msg := h.queue(t)
+		h.spikeOrders.createErr = errors.New("db down")
+		if err := h.worker.Handle(ctx, msg); err == nil {
+			t.Fatalf("expected a retryable error")
+		}
+		if result := h.result(t, msg); result.Status != domain.SpikeTicketStatusQueued {
+			t.Fatalf("status = %s, want queued", result.Status)
+		}
+	})
+
+	t.Run("malformed message", func(t *testing.T) {
+		h := newWorkerHarness(t)
+		if err := h.worker.Handle(ctx, mq.Message{ID: "bad", Body: []byte("{")}); !mq.IsPermanent(err) {
+			t.Fatalf("Handle malformed message = %v, want permanent error", err)
+		}
+	})
+}
+
+func TestSpikeOrderWorker_DeadLetterCompensates(t *testing.T) {
+	ctx := context.Background()
+	h := newWorkerHarness(t)
+	msg := h.queue(t)
+
+	if err := h.worker.deadLetter(ctx, msg, 5, errors.New("db down")); err != nil {
+		t.Fatalf("deadLetter: %v", err)
+	}
+	if len(h.deadLetters.messages) != 1 || h.deadLetters.messages[0].Headers[HeaderStockReleased] == "" {
+		t.Fatalf("expected dead letter marked as released: %+v", h.deadLetters.messages)
+	}
+	if result := h.result(t, msg); result.Status != domain.SpikeTicketStatusFailed {
+		t.Fatalf("status = %s, want failed", result.Status)
+	}
+	h.assertReleased(t, 10, testBuyerID)
+
+	// 重放成功后重新占用库存与去重标记
+	h.spikeOrders.createErr = nil
+	if err := h.worker.Handle(ctx, h.deadLetters.messages[0]); err != nil {
+		t.Fatalf("Handle replay: %v", err)
+	}
+	if result := h.result(t, msg); result.Status != domain.SpikeTicketStatusSucceeded {
+		t.Fatalf("status after replay = %s, want succeeded", result.Status)
+	}
+	if h.stock(t) != 9 {
+		t.Fatalf("stock after replay = %d, want 9", h.stock(t))
+	}
+	if marked, _ := h.dedupe.Mark(ctx, testEventID, testBuyerID, time.Minute); marked {
+		t.Fatalf("expected the buyer mark to be reacquired after replay")
+	}
+}
+
+func TestSpikeOrderWorker_DeadLetterFailureKeepsReservation(t *testing.T) {
+	ctx := context.Background()
+	h := newWorkerHarness(t)
+	msg := h.queue(t)
+	h.deadLetters.err = errors.New("db down")
+
+	// 死信写入失败时消息重新入队，库存与去重标记保持占用，等待再次处理
+	err := h.worker.deadLetter(ctx, msg, 5, errors.New("create order failed"))
+	if !errors.Is(err, h.deadLetters.err) {
+		t.Fatalf("deadLetter = %v, want record error", err)
+	}
+	if h.stock(t) != 9 {
+		t.Fatalf("stock = %d, want reservation kept at 9", h.stock(t))
+	}
+	if result := h.result(t, msg); result.Status != domain.SpikeTicketStatusQueued {
+		t.Fatalf("status = %s, want queued", result.Status)
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/mq"
+	"github.com/danta7/go_mall/internal/repo"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TopicSpikeOrderCreate 秒杀下单消息主题
+const TopicSpikeOrderCreate = "spike.order.create"
+
+var (
+	ErrSpikeEventNotLive    = errors.New("spike event is not live")
+	ErrSpikeSoldOut         = errors.New("spike event sold out")
+	ErrInvalidSpikePurchase = errors.New("invalid spike purchase")
+	ErrSpikeTicketNotFound  = errors.New("spike ticket not found")
+	ErrSpikeDuplicate       = errors.New("already purchased in this spike event")
+)
+
+// SpikeService 定义秒杀下单服务接口
+type SpikeService interface {
+	// Purchase 受理秒杀下单：校验活动 -> 预减库存 -> 投递下单消息，立即返回排队凭证
+	Purchase(ctx context.Context, userID, eventID int64, req *domain.SpikePurchaseRequest) (*domain.SpikeTicket, error)
+	// GetResult 查询排队凭证的处理结果，只能查询本人的凭证
+	GetResult(ctx context.Context, userID int64, ticket string) (*domain.SpikeOrderResult, error)
+}
+
+type spikeService struct {
+	events         *spikeEventCache
+	stockCounter   repo.StockCounter
+	dedupe         repo.SpikeDedupe
+	resultStore    repo.SpikeResultStore
+	orderService   OrderService
+	addressService AddressService
+	publisher      mq.Publisher
+	logger         *zap.Logger
+	now            func() time.Time
+}
+
+// NewSpikeService 创建秒杀下单服务实例
+func NewSpikeService(eventRepo repo.SpikeEventRepository, stockCounter repo.StockCounter, dedupe repo.SpikeDedupe,
+	resultStore repo.SpikeResultStore, orderService OrderService, addressService AddressService, publisher mq.Publisher,
+	logger *zap.Logger) SpikeService {
+	return &spikeService{
+		events:         newSpikeEventCache(eventRepo, logger),
+		stockCounter:   stockCounter,
+		dedupe:         dedupe,
+		resultStore:    resultStore,
+		orderService:   orderService,
+		addressService: addressService,
+		publisher:      publisher,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// Purchase 受理秒杀下单
+// 业务规则：
+//  1. 活动必须处于进行中，购买数量不超过每用户限购
+//  2. 售罄标记命中时直接返回，不再访问计数器
+//  3. 同一用户在同一活动只能购买一次：预减前写入去重标记，已存在则直接拒绝
+//  4. 预减成功后解析收货地址（必填：未指定时使用默认地址，没有任何地址时返回 ErrAddressRequired），
+//     使用优惠券时再按秒杀价预计价，优惠券不可用直接拒绝（落库时再次计价并核销）；二者失败均回补库存。
+//     地址与计价需要查询数据库，放在售罄、去重与预减之后，售罄与重复请求只访问缓存与计数器
+//  5. 预减成功后先记录 queued 结果再投递消息（避免覆盖消费者写入的终态）；
+//     任一步骤失败都会回补库存、清除去重标记，避免库存泄漏和用户被误拦截
+func (s *spikeService) Purchase(ctx context.Context, userID, eventID int64, req *domain.SpikePurchaseRequest) (*domain.SpikeTicket, error) {
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	event, err := s.events.get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !event.IsLive(s.now()) {
+		return nil, ErrSpikeEventNotLive
+	}
+	if quantity < 0 || quantity > event.PerUserLimit {
+		return nil, fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidSpikePurchase, event.PerUserLimit)
+	}
+
+	soldOut, err := s.stockCounter.IsSoldOut(ctx, eventID)
+	if err != nil {
+		s.logger.Error("failed to check sold out flag", zap.Int64("event_id", eventID), zap.Error(err))
+		return nil, fmt.Errorf("check sold out: %w", err)
+	}
+	if soldOut {
+		return nil, ErrSpikeSoldOut
+	}
+
+	// 标记保留到活动结束后一段时间，覆盖消息积压期间的重复请求
+	marked, err := s.dedupe.Mark(ctx, eventID, userID, event.EndAt.Sub(s.now())+time.Hour)
+	if err != nil {
+		s.logger.Error("failed to mark spike buyer", zap.Int64("event_id", eventID), zap.Error(err))
+		return nil, fmt.Errorf("mark spike buyer: %w", err)
+	}
+	if !marked {
+		return nil, ErrSpikeDuplicate
+	}
+
+	if _, err := s.stockCounter.Decrement(ctx, eventID, int64(quantity)); err != nil {
+		s.unmark(ctx, eventID, userID)
+		if errors.Is(err, repo.ErrStockSoldOut) {
+			return nil, ErrSpikeSoldOut
+		}
+		s.logger.Error("failed to decrement spike stock", zap.Int64("event_id", eventID), zap.Error(err))
+		return nil, fmt.Errorf("decrement spike stock: %w", err)
+	}
+
+	address, err := s.addressService.Resolve(ctx, userID, req.AddressID)
+	if err != nil {
+		s.release(ctx, eventID, userID, quantity)
+		return nil, err
+	}
+
+	if len(req.CouponCodes) > 0 {
+		if _, err := s.orderService.Price(ctx, userID, spikePriceRequest(event.ProductID, event.SpikePrice, quantity, address, req.CouponCodes)); err != nil {
+			s.release(ctx, eventID, userID, quantity)
+			return nil, err
+		}
+	}
+
+	msg := domain.SpikeOrderMessage{
+		Ticket:      uuid.NewString(),
+		EventID:     eventID,
+		ProductID:   event.ProductID,
+		UserID:      userID,
+		Quantity:    quantity,
+		SpikePrice:  event.SpikePrice,
+		CouponCodes: req.CouponCodes,
+		Address:     address,
+		QueuedAt:    s.now(),
+	}
+	result := &domain.SpikeOrderResult{
+		Ticket:    msg.Ticket,
+		EventID:   eventID,
+		UserID:    userID,
+		Status:    domain.SpikeTicketStatusQueued,
+		UpdatedAt: s.now(),
+	}
+	err = s.resultStore.Set(ctx, result)
+	if err == nil {
+		err = s.publish(ctx, &msg)
+	}
+	if err != nil {
+		s.logger.Error("failed to queue spike order",
+			zap.String("ticket", msg.Ticket),
+			zap.Int64("event_id", eventID),
+			zap.Error(err),
+		)
+		bg := context.WithoutCancel(ctx)
+		s.release(bg, eventID, userID, quantity)
+		result.Status, result.Reason, result.UpdatedAt = domain.SpikeTicketStatusFailed, "queue unavailable", s.now()
+		if setErr := s.resultStore.Set(bg, result); setErr != nil {
+			s.logger.Error("failed to save spike order result", zap.String("ticket", msg.Ticket), zap.Error(setErr))
+		}
+		return nil, fmt.Errorf("queue spike order: %w", err)
+	}
+
+	s.logger.Info("spike order queued",
+		zap.String("ticket", msg.Ticket),
+		zap.Int64("event_id", eventID),
+		zap.Int64("user_id", userID),
+		zap.Int("quantity", quantity),
+	)
+	return &domain.SpikeTicket{Ticket: msg.Ticket, EventID: eventID, Status: domain.SpikeTicketStatusQueued}, nil
+}
+
+// GetResult 查询排队凭证的处理结果
+func (s *spikeService) GetResult(ctx context.Context, userID int64, ticket string) (*domain.SpikeOrderResult, error) {
+	result, err := s.resultStore.Get(ctx, ticket)
+	if err != nil {
+		s.logger.Error("failed to get spike order result", zap.String("ticket", ticket), zap.Error(err))
+		return nil, fmt.Errorf("get spike order result: %w", err)
+	}
+
+	// 他人的凭证按不存在处理，避免泄露凭证是否有效
+	if result == nil || result.UserID != userID {
+		return nil, ErrSpikeTicketNotFound
+	}
+	return result, nil
+}
+
+// spikePriceRequest 构造秒杀订单的计价请求：单一商品行，按秒杀价计价
+func spikePriceRequest(productID, spikePrice int64, quantity int, address *domain.OrderAddress, couponCodes []string) *domain.PriceOrderRequest {
+	return &domain.PriceOrderRequest{
+		Items:       []*domain.PricingItem{{ProductID: productID, Quantity: quantity, UnitPrice: spikePrice}},
+		Address:     address,
+		CouponCodes: couponCodes,
+	}
+}
+
+// release 回补预减的库存并清除去重标记，用于受理失败时撤销占用；失败只记录日志
+func (s *spikeService) release(ctx context.Context, eventID, userID int64, quantity int) {
+	bg := context.WithoutCancel(ctx)
+	if err := s.stockCounter.Restore(bg, eventID, int64(quantity)); err != nil {
+		s.logger.Error("failed to restore spike stock", zap.Int64("event_id", eventID), zap.Error(err))
+	}
+	s.unmark(bg, eventID, userID)
+}
+
+// unmark 清除去重标记，失败只记录日志
+func (s *spikeService) unmark(ctx context.Context, eventID, userID int64) {
+	if err := s.dedupe.Unmark(context.WithoutCancel(ctx), eventID, userID); err != nil {
+		s.logger.Error("failed to unmark spike buyer",
+			zap.Int64("event_id", eventID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *spikeService) publish(ctx context.Context, msg *domain.SpikeOrderMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	return s.publisher.Publish(ctx, TopicSpikeOrderCreate, mq.Message{ID: msg.Ticket, Body: body})
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"github.com/danta7/go_mall/internal/shipping"
+	"go.uber.org/zap"
+)
+
+const (
+	testEventID = 1
+	testBuyerID = 1
+)
+
+// spikeHarness 组装秒杀下单所需的内存后端与 fake 仓储
+type spikeHarness struct {
+	counter   repo.StockCounter
+	dedupe    repo.SpikeDedupe
+	results   repo.SpikeResultStore
+	coupons   *fakeCouponRepo
+	orders    OrderService
+	addresses AddressService
+	publisher *fakePublisher
+	service   SpikeService
+}
+
+func newSpikeHarness(t *testing.T, stock int64) *spikeHarness {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	event := &domain.SpikeEvent{ID: testEventID, ProductID: 100, SpikePrice: 5000, TotalStock: int(stock), PerUserLimit: 2,
+		Status: domain.SpikeEventStatusScheduled, StartAt: now.Add(-time.Minute), EndAt: now.Add(time.Hour)}
+
+	h := &spikeHarness{
+		counter:   repo.NewMemoryStockCounter(),
+		dedupe:    repo.NewMemorySpikeDedupe(),
+		results:   repo.NewMemorySpikeResultStore(time.Hour),
+		coupons:   newFakeCouponRepo(testCoupon(1, "MINUS500", domain.CouponTypeFixedAmount, 500)),
+		addresses: NewAddressService(newFakeAddressRepo(), zap.NewNop()),
+		publisher: &fakePublisher{},
+	}
+	if err := h.counter.Init(ctx, testEventID, stock); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.addresses.Create(ctx, testBuyerID, testAddressRequest()); err != nil {
+		t.Fatal(err)
+	}
+	h.orders = NewOrderService(&fakeOrderRepo{}, h.coupons, shipping.NewFlat(0), zap.NewNop())
+	h.service = NewSpikeService(newFakeSpikeEventRepo(event), h.counter, h.dedupe, h.results, h.orders, h.addresses, h.publisher, zap.NewNop())
+	return h
+}
+
+func (h *spikeHarness) stock(t *testing.T) int64 {
+	t.Helper()
+	stock, err := h.counter.Stock(context.Background(), testEventID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stock
+}
+
+// assertReleased 断言库存已回补且去重标记已清除（用户可以重新下单）
+func (h *spikeHarness) assertReleased(t *testing.T, wantStock int64, userID int64) {
+	t.Helper()
+	if got := h.stock(t); got != wantStock {
+		t.Fatalf("stock = %d, want %d", got, wantStock)
+	}
+	marked, err := h.dedupe.Mark(context.Background(), testEventID, userID, time.Minute)
+	if err != nil || !marked {
+		t.Fatalf("expected dedupe mark to be cleared, Mark = %v, %v", marked, err)
+	}
+	_ = h.dedupe.Unmark(context.Background(), testEventID, userID)
+}
+
+func TestSpikeService_Purchase_Queues(t *testing.T) {
+	ctx := context.Background()
+	h := newSpikeHarness(t, 10)
+
+	ticket, err := h.service.Purchase(ctx, testBuyerID, testEventID, &domain.SpikePurchaseRequest{Quantity: 2, CouponCodes: []string{"MINUS500"}})
+	if err != nil {
+		t.Fatalf("Purchase: %v", err)
+	}
+	if h.stock(t) != 8 {
+		t.Fatalf("stock = %d, want 8", h.stock(t))
+	}
+	if len(h.publisher.messages) != 1 || h.publisher.messages[0].ID != ticket.Ticket || h.publisher.messages[0].Topic != TopicSpikeOrderCreate {
+		t.Fatalf("unexpected published messages: %+v", h.publisher.messages)
+	}
+	result, err := h.service.GetResult(ctx, testBuyerID, ticket.Ticket)
+	if err != nil || result.Status != domain.SpikeTicketStatusQueued {
+		t.Fatalf("GetResult = %+v, %v, want queued", result, err)
+	}
+	if _, err := h.service.GetResult(ctx, testBuyerID+1, ticket.Ticket); !errors.Is(err, ErrSpikeTicketNotFound) {
+		t.Fatalf("GetResult by another user = %v, want ErrSpikeTicketNotFound", err)
+	}
+
+	// 同一用户重复下单直接拒绝，不再扣减库存
+	if _, err := h.service.Purchase(ctx, testBuyerID, testEventID, &domain.SpikePurchaseRequest{}); !errors.Is(err, ErrSpikeDuplicate) {
+		t.Fatalf("second Purchase = %v, want ErrSpikeDuplicate", err)
+	}
+	if h.stock(t) != 8 {
+		t.Fatalf("stock after duplicate = %d, want 8", h.stock(t))
+	}
+}
+
+func TestSpikeService_Purchase_SoldOut(t *testing.T) {
+	ctx := context.Background()
+	h := newSpikeHarness(t, 1)
+	if _, err := h.addresses.Create(ctx, 2, testAddressRequest()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 库存不足以购买 2 件：拒绝且不标记售罄，仍可购买剩余的 1 件
+	if _, err := h.service.Purchase(ctx, testBuyerID, testEventID, &domain.SpikePurchaseRequest{Quantity: 2}); !errors.Is(err, ErrSpikeSoldOut) {
+		t.Fatalf("Purchase over stock = %v, want ErrSpikeSoldOut", err)
+	}
+	h.assertReleased(t, 1, testBuyerID)
+
+	if _, err := h.service.Purchase(ctx, testBuyerID, testEventID, &domain.SpikePurchaseRequest{}); err != nil {
+		t.Fatalf("Purchase: %v", err)
+	}
+	if _, err := h.service.Purchase(ctx, 2, testEventID, &domain.SpikePurchaseRequest{}); !errors.Is(err, ErrSpikeSoldOut) {
+		t.Fatalf("Purchase after sold out = %v, want ErrSpikeSoldOut", err)
+	}
+}
+
+func TestSpikeService_Purchase_ReleasesOnFailure(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("address required", func(t *testing.T) {
+		h := newSpikeHarness(t, 10)
+		const noAddressUser = 2
+		if _, err := h.service.Purchase(ctx, noAddressUser, testEventID, &domain.SpikePurchaseRequest{}); !errors.Is(err, ErrAddressRequired) {
+			t.Fatalf("Purchase = %v, want ErrAddressRequired", err)
+		}
+		h.assertReleased(t, 10, noAddressUser)
+	})
+
+	t.Run("coupon rejected", func(t *testing.T) {
+		h := newSpikeHarness(t, 10)
+		req := &domain.SpikePurchaseRequest{CouponCodes: []string{"NOPE"}}
+		if _, err := h.service.Purchase(ctx, testBuyerID, testEventID, req); !errors.Is(err, ErrCouponUnavailable) {
+			t.Fatalf("Purchase = %v, want ErrCouponUnavailable", err)
+		}
+		h.assertReleased(t, 10, testBuyerID)
+		if len(h.publisher.messages) != 0 {
+			t.Fatalf("expected no message to be published")
+		}
+	})
+
+	t.Run("publish failed", func(t *testing.T) {
+		h := newSpikeHarness(t, 10)
+		h.publisher.err = errors.New("broker down")
+		if _, err := h.service.Purchase(ctx, testBuyerID, testEventID, &domain.SpikePurchaseRequest{}); err == nil {
+			t.Fatalf("expected Purchase to fail when publishing fails")
+		}
+		h.assertReleased(t, 10, testBuyerID)
+	})
+}
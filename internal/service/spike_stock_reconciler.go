@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/danta7/go_mall/database"
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+// 库存对账指标，通过 /debug/vars 暴露；spike_stock_drift 记录各活动最近一次对账的偏差，
+// 活动结束或不再参与对账后删除对应的键
+var (
+	spikeStockDrift        = expvar.NewMap("spike_stock_drift")
+	spikeStockDriftFixed   = expvar.NewInt("spike_stock_drift_fixed_total")
+	spikeStockDriftPending = expvar.NewInt("spike_stock_drift_pending")
+)
+
+// stockObservation 对账时观察到的售出件数与计数器值，since 为该组取值首次被观察到的时间
+type stockObservation struct {
+	sold   int64
+	cached int64
+	since  time.Time
+}
+
+// SpikeStockReconciler 周期性比对库存计数器与数据库库存（活动库存 - 已落库售出件数），报告并修正偏差
+// 修正策略：
+//  1. 计数器偏多（Drift > 0）会导致用户排队后在落库时才发现售罄，立即扣减修正
+//  2. 计数器偏少（Drift < 0）可能只是在途订单尚未落库（消息积压、消费者重试退避中），
+//     售出件数与计数器值持续 restoreAfter 不变（即期间没有任何下单与落库）时才判定为泄漏并回补；
+//     restoreAfter 须大于消息可能在途的最长时间，否则会把在途订单的库存多回补一份
+//
+// 修正使用原子的 Decrement/Restore 而非覆盖写入，不会吞掉对账期间并发的扣减。
+type SpikeStockReconciler struct {
+	eventRepo      repo.SpikeEventRepository
+	spikeOrderRepo repo.SpikeOrderRepository
+	stockCounter   repo.StockCounter
+	interval       time.Duration
+	restoreAfter   time.Duration
+	logger         *zap.Logger
+	now            func() time.Time
+
+	mu   sync.Mutex
+	prev map[int64]stockObservation
+}
+
+// NewSpikeStockReconciler 创建库存对账任务，interval 为对账间隔，restoreAfter 为回补前要求的静默时长
+func NewSpikeStockReconciler(eventRepo repo.SpikeEventRepository, spikeOrderRepo repo.SpikeOrderRepository,
+	stockCounter repo.StockCounter, interval, restoreAfter time.Duration, logger *zap.Logger) *SpikeStockReconciler {
+	return &SpikeStockReconciler{
+		eventRepo:      eventRepo,
+		spikeOrderRepo: spikeOrderRepo,
+		stockCounter:   stockCounter,
+		interval:       interval,
+		restoreAfter:   restoreAfter,
+		logger:         logger,
+		now:            time.Now,
+		prev:           make(map[int64]stockObservation),
+	}
+}
+
+// Run 按 interval 循环对账，阻塞直到 ctx 结束
+func (r *SpikeStockReconciler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := r.ReconcileOnce(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Error("spike stock reconciliation failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ReconcileOnce 对所有未结束的已排期活动执行一轮对账，返回发现的偏差
+func (r *SpikeStockReconciler) ReconcileOnce(ctx context.Context) ([]*domain.SpikeStockDrift, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 对账以主库为准
+	ctx = database.WithPrimary(ctx)
+	now := r.now()
+	events, err := r.eventRepo.ListActive(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []*domain.SpikeStockDrift
+	seen := make(map[int64]stockObservation, len(events))
+	for _, e := range events {
+		sold, err := r.spikeOrderRepo.SoldQuantity(ctx, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		expected := max(int64(e.TotalStock)-sold, 0)
+
+		cached, err := r.stockCounter.Stock(ctx, e.ID)
+		if errors.Is(err, repo.ErrStockNotWarmed) {
+			// 计数器丢失（如 Redis 重启）时重新预热
+			if err := r.stockCounter.Init(ctx, e.ID, expected); err != nil {
+				return nil, err
+			}
+			r.logger.Warn("spike stock counter missing, re-warmed", zap.Int64("event_id", e.ID), zap.Int64("stock", expected))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		obs := stockObservation{sold: sold, cached: cached, since: now}
+		if prev, ok := r.prev[e.ID]; ok && prev.sold == sold && prev.cached == cached {
+			obs.since = prev.since
+		}
+		seen[e.ID] = obs
+		spikeStockDrift.Set(driftKey(e.ID), intVar(cached-expected))
+		if cached == expected {
+			continue
+		}
+
+		drift := &domain.SpikeStockDrift{EventID: e.ID, Expected: expected, Cached: cached, Drift: cached - expected}
+		switch {
+		case drift.Drift > 0:
+			_, err := r.stockCounter.Decrement(ctx, e.ID, drift.Drift)
+			drift.Fixed = err == nil
+		case now.Sub(obs.since) >= r.restoreAfter:
+			drift.Fixed = r.stockCounter.Restore(ctx, e.ID, -drift.Drift) == nil
+		}
+		drifts = append(drifts, drift)
+	}
+	for id := range r.prev {
+		if _, ok := seen[id]; !ok {
+			spikeStockDrift.Delete(driftKey(id))
+		}
+	}
+	r.prev = seen
+
+	pending := int64(0)
+	for _, d := range drifts {
+		if d.Fixed {
+			spikeStockDriftFixed.Add(1)
+		} else {
+			pending++
+		}
+		r.logger.Warn("spike stock drift detected",
+			zap.Int64("event_id", d.EventID),
+			zap.Int64("expected", d.Expected),
+			zap.Int64("cached", d.Cached),
+			zap.Int64("drift", d.Drift),
+			zap.Bool("fixed", d.Fixed),
+		)
+	}
+	spikeStockDriftPending.Set(pending)
+
+	return drifts, nil
+}
+
+func driftKey(eventID int64) string {
+	return strconv.FormatInt(eventID, 10)
+}
+
+func intVar(n int64) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(n)
+	return v
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+func TestSpikeStockReconciler_RestoresOnlyAfterQuietPeriod(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := &domain.SpikeEvent{ID: 1, TotalStock: 10, PerUserLimit: 1, Status: domain.SpikeEventStatusScheduled,
+		StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour)}
+	counter := repo.NewMemoryStockCounter()
+	if err := counter.Init(ctx, event.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+	// 3 件已预减但尚未落库：可能是消息积压中的在途订单，也可能是泄漏
+	if _, err := counter.Decrement(ctx, event.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewSpikeStockReconciler(newFakeSpikeEventRepo(event), newFakeSpikeOrderRepo(), counter, time.Minute, 10*time.Minute, zap.NewNop())
+	r.now = func() time.Time { return now }
+
+	// 连续多轮观察到相同取值，但未满静默时长，不能回补
+	for i := 0; i < 5; i++ {
+		drifts, err := r.ReconcileOnce(ctx)
+		if err != nil {
+			t.Fatalf("ReconcileOnce: %v", err)
+		}
+		if len(drifts) != 1 || drifts[0].Drift != -3 || drifts[0].Fixed {
+			t.Fatalf("round %d: drifts = %+v, want one unfixed drift of -3", i, drifts)
+		}
+		now = now.Add(time.Minute)
+	}
+	if stock, _ := counter.Stock(ctx, event.ID); stock != 7 {
+		t.Fatalf("stock restored before quiet period: %d", stock)
+	}
+
+	now = now.Add(10 * time.Minute)
+	drifts, err := r.ReconcileOnce(ctx)
+	if err != nil {
+		t.Fatalf("ReconcileOnce: %v", err)
+	}
+	if len(drifts) != 1 || !drifts[0].Fixed {
+		t.Fatalf("drifts = %+v, want fixed drift after quiet period", drifts)
+	}
+	if stock, _ := counter.Stock(ctx, event.ID); stock != 10 {
+		t.Fatalf("stock = %d, want 10", stock)
+	}
+}
+
+func TestSpikeStockReconciler_ActivityResetsQuietPeriod(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := &domain.SpikeEvent{ID: 1, TotalStock: 10, PerUserLimit: 1, Status: domain.SpikeEventStatusScheduled,
+		StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour)}
+	counter := repo.NewMemoryStockCounter()
+	if err := counter.Init(ctx, event.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := counter.Decrement(ctx, event.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewSpikeStockReconciler(newFakeSpikeEventRepo(event), newFakeSpikeOrderRepo(), counter, time.Minute, 10*time.Minute, zap.NewNop())
+	r.now = func() time.Time { return now }
+	if _, err := r.ReconcileOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 期间又有下单，计数器变化后重新计时
+	now = now.Add(9 * time.Minute)
+	if _, err := counter.Decrement(ctx, event.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReconcileOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(5 * time.Minute)
+	drifts, err := r.ReconcileOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 1 || drifts[0].Fixed {
+		t.Fatalf("drifts = %+v, want unfixed drift while quiet period restarts", drifts)
+	}
+}
+
+func TestSpikeStockReconciler_RemovesDriftMetricAfterEventEnds(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := &domain.SpikeEvent{ID: 42, TotalStock: 10, PerUserLimit: 1, Status: domain.SpikeEventStatusScheduled,
+		StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour)}
+	counter := repo.NewMemoryStockCounter()
+	if err := counter.Init(ctx, event.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewSpikeStockReconciler(newFakeSpikeEventRepo(event), newFakeSpikeOrderRepo(), counter, time.Minute, 10*time.Minute, zap.NewNop())
+	r.now = func() time.Time { return now }
+	if _, err := r.ReconcileOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if spikeStockDrift.Get(driftKey(event.ID)) == nil {
+		t.Fatalf("expected drift metric for active event")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := r.ReconcileOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if spikeStockDrift.Get(driftKey(event.ID)) != nil {
+		t.Fatalf("expected drift metric to be removed after the event ended")
+	}
+}
@@ -3,6 +3,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/danta7/go_mall/internal/domain"
@@ -21,10 +22,10 @@ var (
 
 // UserService 定义用户服务接口
 type UserService interface {
-	Register(req *domain.RegisterRequest) (*domain.User, error)
-	Login(req *domain.LoginRequest) (*domain.User, error)
-	GetUserByID(id int64) (*domain.User, error)
-	GetUserByUsername(username string) (*domain.User, error)
+	Register(ctx context.Context, req *domain.RegisterRequest) (*domain.User, error)
+	Login(ctx context.Context, req *domain.LoginRequest) (*domain.User, error)
+	GetUserByID(ctx context.Context, id int64) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
 }
 
 type userService struct {
@@ -45,9 +46,9 @@ func NewUserService(userRepo repo.UserRepository, logger *zap.Logger) UserServic
 // 1. 用户名和邮箱不能重复
 // 2. 密码需要进行bcrypt哈希
 // 3. 新用户默认为普通用户角色
-func (s *userService) Register(req *domain.RegisterRequest) (*domain.User, error) {
+func (s *userService) Register(ctx context.Context, req *domain.RegisterRequest) (*domain.User, error) {
 	// 验证用户名是否存在
-	existingUser, err := s.userRepo.GetByUsername(req.Username)
+	existingUser, err := s.userRepo.GetByUsername(ctx, req.Username)
 	if err != nil {
 		s.logger.Error("failed to check username", zap.Error(err))
 		return nil, fmt.Errorf("check username: %w", err)
@@ -58,7 +59,7 @@ func (s *userService) Register(req *domain.RegisterRequest) (*domain.User, error
 	}
 
 	// 验证邮箱是否存在
-	existingUser, err = s.userRepo.GetByEmail(req.Email)
+	existingUser, err = s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		s.logger.Error("failed to check email", zap.Error(err))
 		return nil, fmt.Errorf("check email: %w", err)
@@ -82,7 +83,7 @@ func (s *userService) Register(req *domain.RegisterRequest) (*domain.User, error
 		IsActive:     true,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := s.userRepo.Create(ctx, user); err != nil {
 		s.logger.Error("failed to create user", zap.Error(err))
 		return nil, fmt.Errorf("create user: %w", err)
 	}
@@ -96,9 +97,9 @@ func (s *userService) Register(req *domain.RegisterRequest) (*domain.User, error
 // 1. 支持用户名或邮箱登录
 // 2. 验证密码正确性
 // 3. 检查用户是否处于活跃状态
-func (s *userService) Login(req *domain.LoginRequest) (*domain.User, error) {
+func (s *userService) Login(ctx context.Context, req *domain.LoginRequest) (*domain.User, error) {
 	// 尝试通过用户名查找用户
-	user, err := s.userRepo.GetByUsername(req.Username)
+	user, err := s.userRepo.GetByUsername(ctx, req.Username)
 	if err != nil {
 		s.logger.Error("failed to get user by username", zap.Error(err))
 		return nil, fmt.Errorf("get user: %w", err)
@@ -106,7 +107,7 @@ func (s *userService) Login(req *domain.LoginRequest) (*domain.User, error) {
 
 	// 如果用户名找不到，尝试用邮箱查找
 	if user == nil {
-		user, err = s.userRepo.GetByEmail(req.Username)
+		user, err = s.userRepo.GetByEmail(ctx, req.Username)
 		if err != nil {
 			s.logger.Error("failed to get user by email", zap.Error(err))
 			return nil, fmt.Errorf("get user: %w", err)
@@ -142,8 +143,8 @@ func (s *userService) Login(req *domain.LoginRequest) (*domain.User, error) {
 }
 
 // GetUserByID 根据ID获取用户
-func (s *userService) GetUserByID(id int64) (*domain.User, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *userService) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("failed to get user by id", zap.Int64("id", id), zap.Error(err))
 		return nil, fmt.Errorf("get user: %w", err)
@@ -157,8 +158,8 @@ func (s *userService) GetUserByID(id int64) (*domain.User, error) {
 }
 
 // GetUserByUsername 根据用户名获取用户
-func (s *userService) GetUserByUsername(username string) (*domain.User, error) {
-	user, err := s.userRepo.GetByUsername(username)
+func (s *userService) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	user, err := s.userRepo.GetByUsername(ctx, username)
 	if err != nil {
 		s.logger.Error("failed to get user by username", zap.String("username", username), zap.Error(err))
 		return nil, fmt.Errorf("get user: %w", err)
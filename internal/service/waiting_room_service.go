@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danta7/go_mall/internal/domain"
+	"github.com/danta7/go_mall/internal/repo"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrQueueTokenInvalid = errors.New("invalid queue token")
+	ErrQueueNotAdmitted  = errors.New("not admitted yet")
+)
+
+// WaitingRoomService 定义秒杀虚拟排队室服务接口
+// 活动开始前即可进入排队；活动开始后按固定速率放行，被放行的用户才能进入下单接口。
+type WaitingRoomService interface {
+	// Join 进入排队并返回排队凭证与当前位置；重复进入返回原位置
+	Join(ctx context.Context, userID, eventID int64) (*domain.WaitingRoomStatus, error)
+	// Status 根据排队凭证查询当前位置与预计等待时间
+	Status(ctx context.Context, userID, eventID int64, token string) (*domain.WaitingRoomStatus, error)
+	// CheckAdmitted 校验排队凭证已被放行，未放行时返回 ErrQueueNotAdmitted 与预计等待时间
+	CheckAdmitted(ctx context.Context, userID, eventID int64, token string) (time.Duration, error)
+}
+
+type waitingRoomService struct {
+	events      *spikeEventCache
+	waitingRoom repo.WaitingRoom
+	admitRate   float64
+	secret      []byte
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewWaitingRoomService 创建排队室服务实例，admitRate 为每秒放行人数，secret 用于签发排队凭证
+func NewWaitingRoomService(eventRepo repo.SpikeEventRepository, waitingRoom repo.WaitingRoom, admitRate int,
+	secret string, logger *zap.Logger) WaitingRoomService {
+	return &waitingRoomService{
+		events:      newSpikeEventCache(eventRepo, logger),
+		waitingRoom: waitingRoom,
+		admitRate:   float64(admitRate),
+		secret:      []byte(secret),
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Join 进入排队，仅未开始或进行中的活动可以排队
+func (s *waitingRoomService) Join(ctx context.Context, userID, eventID int64) (*domain.WaitingRoomStatus, error) {
+	event, err := s.queueableEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 排队数据保留到活动结束后一段时间
+	seq, err := s.waitingRoom.Join(ctx, eventID, userID, event.EndAt.Sub(s.now())+time.Hour)
+	if err != nil {
+		s.logger.Error("failed to join waiting room", zap.Int64("event_id", eventID), zap.Int64("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("join waiting room: %w", err)
+	}
+
+	return s.status(ctx, event, userID, seq)
+}
+
+// Status 查询排队状态
+func (s *waitingRoomService) Status(ctx context.Context, userID, eventID int64, token string) (*domain.WaitingRoomStatus, error) {
+	seq, err := s.verify(eventID, userID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := s.queueableEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.status(ctx, event, userID, seq)
+}
+
+// CheckAdmitted 校验是否已放行
+func (s *waitingRoomService) CheckAdmitted(ctx context.Context, userID, eventID int64, token string) (time.Duration, error) {
+	status, err := s.Status(ctx, userID, eventID, token)
+	if err != nil {
+		return 0, err
+	}
+	if !status.Admitted {
+		return time.Duration(status.ETASeconds) * time.Second, ErrQueueNotAdmitted
+	}
+	return 0, nil
+}
+
+// queueableEvent 返回可排队的活动（未开始或进行中）
+func (s *waitingRoomService) queueableEvent(ctx context.Context, eventID int64) (*domain.SpikeEvent, error) {
+	event, err := s.events.get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch event.Phase(s.now()) {
+	case domain.SpikeEventPhaseUpcoming, domain.SpikeEventPhaseLive:
+		return event, nil
+	default:
+		return nil, ErrSpikeEventNotLive
+	}
+}
+
+// status 计算排队状态；活动进行中时顺带推进放行水位
+func (s *waitingRoomService) status(ctx context.Context, event *domain.SpikeEvent, userID, seq int64) (*domain.WaitingRoomStatus, error) {
+	now := s.now()
+
+	var admitted int64
+	var err error
+	if event.IsLive(now) {
+		admitted, err = s.waitingRoom.Admit(ctx, event.ID, s.admitRate)
+	} else {
+		_, admitted, err = s.waitingRoom.Stats(ctx, event.ID)
+	}
+	if err != nil {
+		s.logger.Error("failed to read waiting room", zap.Int64("event_id", event.ID), zap.Error(err))
+		return nil, fmt.Errorf("read waiting room: %w", err)
+	}
+
+	status := &domain.WaitingRoomStatus{
+		EventID:  event.ID,
+		Token:    s.sign(event.ID, userID, seq),
+		Position: seq,
+		Admitted: event.IsLive(now) && seq <= admitted,
+	}
+	if !status.Admitted {
+		status.Ahead = max(seq-admitted-1, 0)
+		eta := math.Ceil(float64(seq-admitted) / s.admitRate)
+		if event.StartAt.After(now) {
+			eta += math.Ceil(event.StartAt.Sub(now).Seconds())
+		}
+		status.ETASeconds = int64(eta)
+	}
+	return status, nil
+}
+
+// sign 签发排队凭证：<序号>.<HMAC(event_id:user_id:序号)>，凭证与活动、用户绑定，无需服务端保存
+func (s *waitingRoomService) sign(eventID, userID, seq int64) string {
+	return strconv.FormatInt(seq, 10) + "." + s.mac(eventID, userID, seq)
+}
+
+// verify 校验排队凭证并返回序号
+func (s *waitingRoomService) verify(eventID, userID int64, token string) (int64, error) {
+	seqStr, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, ErrQueueTokenInvalid
+	}
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if err != nil || seq <= 0 {
+		return 0, ErrQueueTokenInvalid
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.mac(eventID, userID, seq))) {
+		return 0, ErrQueueTokenInvalid
+	}
+	return seq, nil
+}
+
+func (s *waitingRoomService) mac(eventID, userID, seq int64) string {
+	h := hmac.New(sha256.New, s.secret)
+	_, _ = fmt.Fprintf(h, "%d:%d:%d", eventID, userID, seq)
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,79 @@
+// Package shipping 提供运费计算器：固定运费、按重量计费，以及满额包邮（包装其他计算器）。
+// 计算器可组合，由配置决定具体策略。
+package shipping
+
+import (
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+// Calculator 定义运费计算器接口，金额以“分”为单位
+type Calculator interface {
+	// Fee 根据商品行与收货地址计算运费；address 为 nil 表示地址未知（按默认规则计费）
+	Fee(items []*domain.PricingItem, address *domain.OrderAddress) int64
+}
+
+// flat 固定运费
+type flat struct {
+	fee int64
+}
+
+// NewFlat 创建固定运费计算器
+func NewFlat(fee int64) Calculator {
+	return &flat{fee: fee}
+}
+
+// Fee 计算运费
+func (c *flat) Fee([]*domain.PricingItem, *domain.OrderAddress) int64 {
+	return c.fee
+}
+
+// weightBased 首重 + 续重计费
+type weightBased struct {
+	firstWeight int64
+	firstFee    int64
+	stepWeight  int64
+	stepFee     int64
+}
+
+// NewWeightBased 创建按重量计费的计算器：首重 firstWeight 克内收 firstFee，
+// 超出部分每 stepWeight 克（不足按一档计）加收 stepFee
+func NewWeightBased(firstWeight, firstFee, stepWeight, stepFee int64) Calculator {
+	return &weightBased{firstWeight: firstWeight, firstFee: firstFee, stepWeight: stepWeight, stepFee: stepFee}
+}
+
+// Fee 计算运费
+func (c *weightBased) Fee(items []*domain.PricingItem, _ *domain.OrderAddress) int64 {
+	var weight int64
+	for _, item := range items {
+		weight += item.UnitWeight * int64(item.Quantity)
+	}
+
+	fee := c.firstFee
+	if extra := weight - c.firstWeight; extra > 0 && c.stepWeight > 0 {
+		fee += (extra + c.stepWeight - 1) / c.stepWeight * c.stepFee
+	}
+	return fee
+}
+
+// freeOver 满额包邮
+type freeOver struct {
+	threshold int64
+	next      Calculator
+}
+
+// NewFreeOver 创建满额包邮计算器：商品小计达到 threshold 时免运费，否则按 next 计费
+func NewFreeOver(threshold int64, next Calculator) Calculator {
+	return &freeOver{threshold: threshold, next: next}
+}
+
+// Fee 计算运费
+func (c *freeOver) Fee(items []*domain.PricingItem, address *domain.OrderAddress) int64 {
+	var subtotal int64
+	for _, item := range items {
+		subtotal += item.Amount()
+	}
+	if subtotal >= c.threshold {
+		return 0
+	}
+	return c.next.Fee(items, address)
+}
@@ -0,0 +1,41 @@
+package shipping
+
+import (
+	"testing"
+
+	"github.com/danta7/go_mall/internal/domain"
+)
+
+func TestWeightBased(t *testing.T) {
+	c := NewWeightBased(1000, 800, 500, 200)
+
+	cases := []struct {
+		name   string
+		weight int64
+		qty    int
+		want   int64
+	}{
+		{"unknown weight", 0, 1, 800},
+		{"within first weight", 500, 2, 800},
+		{"one step", 600, 2, 1000},
+		{"partial step rounds up", 1000, 2, 1200},
+	}
+
+	for _, tc := range cases {
+		items := []*domain.PricingItem{{Quantity: tc.qty, UnitWeight: tc.weight}}
+		if got := c.Fee(items, nil); got != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestFreeOver(t *testing.T) {
+	c := NewFreeOver(9900, NewFlat(1000))
+
+	if got := c.Fee([]*domain.PricingItem{{Quantity: 1, UnitPrice: 9899}}, nil); got != 1000 {
+		t.Fatalf("expected flat fee below threshold, got %d", got)
+	}
+	if got := c.Fee([]*domain.PricingItem{{Quantity: 3, UnitPrice: 3300}}, nil); got != 0 {
+		t.Fatalf("expected free shipping at threshold, got %d", got)
+	}
+}
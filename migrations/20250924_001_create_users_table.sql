@@ -19,4 +19,7 @@ CREATE TABLE IF NOT EXISTS `users` (
 
 -- 插入默认管理员用户（密码为 "admin123"，实际生产环境应使用更强密码）
 INSERT IGNORE INTO `users` (`username`, `email`, `password_hash`, `role`) VALUES
-    ('admin', 'admin@spike.local', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'admin');
\ No newline at end of file
+    ('admin', 'admin@spike.local', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'admin');
+
+-- +migrate Down
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,22 @@
+-- 秒杀活动表
+-- 管理员创建/排期/编辑/关闭活动；活动对外阶段（未开始/进行中/已结束）由 status 与时间窗口推导
+
+CREATE TABLE IF NOT EXISTS `spike_events` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '活动ID',
+    `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+    `title` varchar(128) NOT NULL DEFAULT '' COMMENT '活动标题',
+    `spike_price` bigint unsigned NOT NULL COMMENT '秒杀价（分）',
+    `total_stock` int unsigned NOT NULL COMMENT '活动分配库存',
+    `per_user_limit` int unsigned NOT NULL DEFAULT 1 COMMENT '每用户限购件数',
+    `start_at` datetime NOT NULL COMMENT '开始时间',
+    `end_at` datetime NOT NULL COMMENT '结束时间',
+    `status` enum('draft', 'scheduled', 'closed') NOT NULL DEFAULT 'draft' COMMENT '活动状态',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_product_time` (`product_id`, `start_at`, `end_at`),
+    KEY `idx_status_time` (`status`, `start_at`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='秒杀活动表';
+
+-- +migrate Down
+DROP TABLE IF EXISTS `spike_events`;
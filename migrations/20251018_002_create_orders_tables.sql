@@ -0,0 +1,29 @@
+-- 订单与订单行表
+-- 金额统一以“分”为单位存储；订单行保存下单时的成交单价快照
+
+CREATE TABLE IF NOT EXISTS `orders` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '订单ID',
+    `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+    `status` enum('pending_payment', 'paid', 'cancelled') NOT NULL DEFAULT 'pending_payment' COMMENT '订单状态',
+    `total_amount` bigint unsigned NOT NULL COMMENT '订单总金额（分）',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_user_created` (`user_id`, `created_at`),
+    KEY `idx_status` (`status`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单表';
+
+CREATE TABLE IF NOT EXISTS `order_items` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '订单行ID',
+    `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+    `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+    `quantity` int unsigned NOT NULL COMMENT '购买数量',
+    `price` bigint unsigned NOT NULL COMMENT '成交单价（分）',
+    PRIMARY KEY (`id`),
+    KEY `idx_order_id` (`order_id`),
+    KEY `idx_product_id` (`product_id`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单行表';
+
+-- +migrate Down
+DROP TABLE IF EXISTS `order_items`;
+DROP TABLE IF EXISTS `orders`;
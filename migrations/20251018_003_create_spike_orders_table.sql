@@ -0,0 +1,19 @@
+-- 秒杀订单表
+-- ticket 为秒杀接口受理时发放的排队凭证，唯一约束保证异步消费者重复投递时幂等落库
+
+CREATE TABLE IF NOT EXISTS `spike_orders` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '秒杀订单ID',
+    `spike_event_id` bigint unsigned NOT NULL COMMENT '秒杀活动ID',
+    `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+    `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+    `ticket` varchar(64) NOT NULL COMMENT '排队凭证（幂等键）',
+    `quantity` int unsigned NOT NULL COMMENT '购买数量',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_ticket` (`ticket`),
+    KEY `idx_event_user` (`spike_event_id`, `user_id`),
+    KEY `idx_order_id` (`order_id`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='秒杀订单表';
+
+-- +migrate Down
+DROP TABLE IF EXISTS `spike_orders`;
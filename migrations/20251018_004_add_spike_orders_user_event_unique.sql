@@ -0,0 +1,13 @@
+-- 秒杀订单增加 (user_id, spike_event_id) 唯一约束
+-- 与缓存侧“已购买”标记配合：缓存标记负责快速拦截，唯一约束作为最终防线保证同一活动不重复下单
+
+ALTER TABLE `spike_orders`
+    DROP INDEX `idx_event_user`,
+    ADD UNIQUE KEY `uk_user_event` (`user_id`, `spike_event_id`),
+    ADD KEY `idx_event_id` (`spike_event_id`);
+
+-- +migrate Down
+ALTER TABLE `spike_orders`
+    DROP INDEX `uk_user_event`,
+    DROP INDEX `idx_event_id`,
+    ADD KEY `idx_event_user` (`spike_event_id`, `user_id`);
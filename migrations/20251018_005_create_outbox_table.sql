@@ -0,0 +1,25 @@
+-- 本地消息表（Transactional Outbox）
+-- 业务变更与待发送消息在同一事务中写入，由 relay 异步投递到消息队列，避免“写库成功但发消息失败”
+-- claim_token/claimed_until 用于多实例 relay 之间的租约式认领，避免同一消息被并发投递
+
+CREATE TABLE IF NOT EXISTS `outbox` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '自增ID',
+    `topic` varchar(128) NOT NULL COMMENT '消息主题',
+    `message_id` varchar(64) NOT NULL COMMENT '消息唯一ID，消费者据此去重',
+    `payload` mediumblob NOT NULL COMMENT '消息体（JSON）',
+    `status` enum('pending', 'sent', 'failed') NOT NULL DEFAULT 'pending' COMMENT '投递状态',
+    `attempts` int unsigned NOT NULL DEFAULT 0 COMMENT '已尝试投递次数',
+    `next_attempt_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '下次可投递时间',
+    `claim_token` varchar(64) DEFAULT NULL COMMENT '认领令牌',
+    `claimed_until` datetime(3) DEFAULT NULL COMMENT '认领租约到期时间',
+    `last_error` varchar(512) NOT NULL DEFAULT '' COMMENT '最近一次投递错误',
+    `created_at` timestamp(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '创建时间',
+    `sent_at` timestamp(3) NULL DEFAULT NULL COMMENT '投递成功时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_message_id` (`message_id`),
+    KEY `idx_status_next` (`status`, `next_attempt_at`),
+    KEY `idx_claim_token` (`claim_token`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='本地消息表';
+
+-- +migrate Down
+DROP TABLE IF EXISTS `outbox`;
@@ -0,0 +1,20 @@
+-- 死信表：消费重试耗尽的消息落库保存，供管理端查看、重放或丢弃
+
+CREATE TABLE IF NOT EXISTS `dead_letters` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '自增ID',
+    `topic` varchar(128) NOT NULL COMMENT '原消息主题',
+    `message_id` varchar(64) NOT NULL DEFAULT '' COMMENT '原消息ID',
+    `payload` mediumblob NOT NULL COMMENT '原消息体',
+    `headers` json DEFAULT NULL COMMENT '原消息头',
+    `attempts` int unsigned NOT NULL DEFAULT 0 COMMENT '已处理次数',
+    `last_error` varchar(512) NOT NULL DEFAULT '' COMMENT '最后一次处理错误',
+    `status` enum('dead', 'replayed', 'discarded') NOT NULL DEFAULT 'dead' COMMENT '状态',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status_topic` (`status`, `topic`),
+    KEY `idx_message_id` (`message_id`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='死信表';
+
+-- +migrate Down
+DROP TABLE IF EXISTS `dead_letters`;
@@ -0,0 +1,66 @@
+-- 优惠券、核销记录与订单优惠明细
+-- 订单增加商品小计、运费与优惠金额，total_amount = subtotal_amount + shipping_fee - discount_amount
+
+CREATE TABLE IF NOT EXISTS `coupons` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '优惠券ID',
+    `code` varchar(64) NOT NULL COMMENT '券码',
+    `name` varchar(128) NOT NULL DEFAULT '' COMMENT '名称',
+    `type` enum('fixed_amount', 'percentage', 'free_shipping') NOT NULL COMMENT '类型：满减/折扣/免运费',
+    `value` bigint unsigned NOT NULL DEFAULT 0 COMMENT '满减金额（分）或折扣百分比（1-100）',
+    `max_discount` bigint unsigned NOT NULL DEFAULT 0 COMMENT '折扣券优惠上限（分），0 表示不限',
+    `min_spend` bigint unsigned NOT NULL DEFAULT 0 COMMENT '适用商品小计门槛（分）',
+    `product_ids` json NULL COMMENT '适用商品ID，为空表示不限',
+    `category_ids` json NULL COMMENT '适用类目ID，为空表示不限',
+    `total_limit` int unsigned NOT NULL DEFAULT 0 COMMENT '总可用次数，0 表示不限',
+    `per_user_limit` int unsigned NOT NULL DEFAULT 0 COMMENT '每用户可用次数，0 表示不限',
+    `used_count` int unsigned NOT NULL DEFAULT 0 COMMENT '已使用次数',
+    `start_at` datetime NOT NULL COMMENT '生效时间',
+    `end_at` datetime NOT NULL COMMENT '失效时间',
+    `status` enum('active', 'disabled') NOT NULL DEFAULT 'active' COMMENT '状态',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_code` (`code`),
+    KEY `idx_status_time` (`status`, `start_at`, `end_at`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='优惠券表';
+
+CREATE TABLE IF NOT EXISTS `coupon_redemptions` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '核销记录ID',
+    `coupon_id` bigint unsigned NOT NULL COMMENT '优惠券ID',
+    `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+    `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+    `amount` bigint unsigned NOT NULL COMMENT '优惠金额（分）',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '核销时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_coupon_order` (`coupon_id`, `order_id`),
+    KEY `idx_coupon_user` (`coupon_id`, `user_id`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='优惠券核销记录表';
+
+CREATE TABLE IF NOT EXISTS `order_discounts` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '优惠明细ID',
+    `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+    `coupon_id` bigint unsigned NOT NULL COMMENT '优惠券ID',
+    `coupon_code` varchar(64) NOT NULL COMMENT '券码快照',
+    `type` varchar(32) NOT NULL COMMENT '优惠类型快照',
+    `amount` bigint unsigned NOT NULL COMMENT '优惠金额（分）',
+    `description` varchar(255) NOT NULL DEFAULT '' COMMENT '优惠说明',
+    PRIMARY KEY (`id`),
+    KEY `idx_order_id` (`order_id`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单优惠明细表';
+
+ALTER TABLE `orders`
+    ADD COLUMN `subtotal_amount` bigint unsigned NOT NULL DEFAULT 0 COMMENT '商品小计（分）' AFTER `status`,
+    ADD COLUMN `shipping_fee` bigint unsigned NOT NULL DEFAULT 0 COMMENT '运费（分）' AFTER `subtotal_amount`,
+    ADD COLUMN `discount_amount` bigint unsigned NOT NULL DEFAULT 0 COMMENT '优惠金额（分）' AFTER `shipping_fee`;
+
+UPDATE `orders` SET `subtotal_amount` = `total_amount` WHERE `subtotal_amount` = 0;
+
+-- +migrate Down
+ALTER TABLE `orders`
+    DROP COLUMN `discount_amount`,
+    DROP COLUMN `shipping_fee`,
+    DROP COLUMN `subtotal_amount`;
+
+DROP TABLE IF EXISTS `order_discounts`;
+DROP TABLE IF EXISTS `coupon_redemptions`;
+DROP TABLE IF EXISTS `coupons`;
@@ -0,0 +1,35 @@
+-- 用户收货地址与订单收货地址快照
+-- 订单保存下单时的地址快照，用户之后修改或删除地址不影响已有订单
+
+CREATE TABLE IF NOT EXISTS `user_addresses` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '地址ID',
+    `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+    `receiver_name` varchar(32) NOT NULL COMMENT '收货人',
+    `phone` varchar(20) NOT NULL COMMENT '联系电话',
+    `province` varchar(32) NOT NULL COMMENT '省',
+    `city` varchar(32) NOT NULL COMMENT '市',
+    `district` varchar(32) NOT NULL DEFAULT '' COMMENT '区/县',
+    `detail` varchar(255) NOT NULL COMMENT '详细地址',
+    `postal_code` varchar(10) NOT NULL DEFAULT '' COMMENT '邮编',
+    `is_default` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否默认地址',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_user_default` (`user_id`, `is_default`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户收货地址表';
+
+CREATE TABLE IF NOT EXISTS `order_addresses` (
+    `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+    `receiver_name` varchar(32) NOT NULL COMMENT '收货人',
+    `phone` varchar(20) NOT NULL COMMENT '联系电话',
+    `province` varchar(32) NOT NULL COMMENT '省',
+    `city` varchar(32) NOT NULL COMMENT '市',
+    `district` varchar(32) NOT NULL DEFAULT '' COMMENT '区/县',
+    `detail` varchar(255) NOT NULL COMMENT '详细地址',
+    `postal_code` varchar(10) NOT NULL DEFAULT '' COMMENT '邮编',
+    PRIMARY KEY (`order_id`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单收货地址快照表';
+
+-- +migrate Down
+DROP TABLE IF EXISTS `order_addresses`;
+DROP TABLE IF EXISTS `user_addresses`;
@@ -0,0 +1,13 @@
+-- 秒杀活动增加已售件数 sold_stock
+-- 落库时以条件更新 sold_stock + ? <= total_stock 做最终防超卖校验，替代锁定活动行再汇总 spike_orders 的做法
+
+ALTER TABLE `spike_events`
+    ADD COLUMN `sold_stock` int unsigned NOT NULL DEFAULT 0 COMMENT '已落库的售出件数' AFTER `total_stock`;
+
+UPDATE `spike_events` e
+    JOIN (SELECT `spike_event_id`, SUM(`quantity`) AS `sold` FROM `spike_orders` GROUP BY `spike_event_id`) o
+    ON o.`spike_event_id` = e.`id`
+SET e.`sold_stock` = o.`sold`;
+
+-- +migrate Down
+ALTER TABLE `spike_events` DROP COLUMN `sold_stock`;
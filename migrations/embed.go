@@ -0,0 +1,9 @@
+// Package migrations 将数据库迁移文件嵌入二进制，部署时无需随附迁移目录
+package migrations
+
+import "embed"
+
+// FS 内嵌的迁移文件（*.sql）
+//
+//go:embed *.sql
+var FS embed.FS